@@ -0,0 +1,133 @@
+package morton
+
+import (
+	"errors"
+	"sort"
+)
+
+// Candidates examined on each side of the query's position, per view, by default.
+const DefaultANNWindow = 16
+
+// Neighbour found by an ANNIndex: Index is the code's position in the indexed slice, and Distance the squared Euclidean distance to the query.
+type ANNResult struct {
+	Code     uint64
+	Index    int
+	Distance uint64
+}
+
+// Approximate nearest neighbour index over sorted codes.  Queries binary search each view for the query's code, and rank the Window codes either side by exact distance.  Points near but across a high level cell boundary are far apart in code order, so views of the points under coordinate shifts, which move the boundaries, recover most of the neighbours a single view misses.
+type ANNIndex struct {
+	Window int
+
+	m     *Morton
+	codes []uint64
+	views []annView
+}
+
+// Points shifted by offset, wrapping within the tables, in code order.
+type annView struct {
+	offset []uint32
+	codes  []uint64
+	index  []int
+}
+
+// Index over codes, which must be sorted ascending and are not copied, with shifts additional views.  View s is shifted by s/(2*shifts + 1) of each table's length: odd fractions, so cell boundaries never realign.
+func NewANNIndex(m *Morton, codes []uint64, shifts int) (*ANNIndex, error) {
+	d := int(m.Dimensions)
+	if d == 0 || len(m.Tables) < d {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if shifts < 0 {
+		return nil, errors.New("ANN index shifts must not be negative")
+	}
+	if !sort.SliceIsSorted(codes, func(i, j int) bool { return codes[i] < codes[j] }) {
+		return nil, errors.New("ANN index requires codes sorted in ascending order")
+	}
+
+	a := &ANNIndex{Window: DefaultANNWindow, m: m, codes: codes}
+	identity := make([]int, len(codes))
+	for i := range identity {
+		identity[i] = i
+	}
+	a.views = append(a.views, annView{make([]uint32, d), codes, identity})
+
+	v := make([]uint32, d)
+	for s := 1; s <= shifts; s++ {
+		view := annView{offset: make([]uint32, d)}
+		for i := range view.offset {
+			view.offset[i] = uint32(uint64(s) * uint64(m.Tables[i].Length) / uint64(2*shifts+1))
+		}
+
+		keys := make([]uint64, len(codes))
+		for i, c := range codes {
+			m.decode(c, v)
+			code, err := m.Encode(view.shift(m, v))
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = code
+		}
+		view.index = make([]int, len(codes))
+		for i := range view.index {
+			view.index[i] = i
+		}
+		sort.Slice(view.index, func(i, j int) bool { return keys[view.index[i]] < keys[view.index[j]] })
+		view.codes = make([]uint64, len(codes))
+		for i, p := range view.index {
+			view.codes[i] = keys[p]
+		}
+		a.views = append(a.views, view)
+	}
+	return a, nil
+}
+
+// Point shifted by the view's offset, wrapping within the tables, in place.
+func (view annView) shift(m *Morton, point []uint32) []uint32 {
+	for i, o := range view.offset {
+		point[i] = uint32((uint64(point[i]) + uint64(o)) % uint64(m.Tables[i].Length))
+	}
+	return point
+}
+
+// Up to k approximate nearest neighbours of point, nearest first.
+func (a *ANNIndex) Query(point []uint32, k int) ([]ANNResult, error) {
+	if len(point) != int(a.m.Dimensions) {
+		return nil, ErrDimensionMismatch
+	}
+	query, err := a.m.Encode(point)
+	if err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		return nil, nil
+	}
+
+	seen := make(map[int]bool)
+	var results []ANNResult
+	q := make([]uint32, len(point))
+	for _, view := range a.views {
+		copy(q, point)
+		code, _ := a.m.Encode(view.shift(a.m, q))
+		at := sort.Search(len(view.codes), func(i int) bool { return view.codes[i] >= code })
+		for i := at - a.Window; i < at+a.Window; i++ {
+			if i < 0 || i >= len(view.codes) || seen[view.index[i]] {
+				continue
+			}
+			idx := view.index[i]
+			seen[idx] = true
+
+			results = append(results, ANNResult{a.codes[idx], idx, a.m.DistanceSquaredEuclidean(a.codes[idx], query)})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		return results[i].Index < results[j].Index
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
@@ -0,0 +1,94 @@
+package morton
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Fraction of the k results per query within the exact k-th nearest distance, so ties count either way.
+func annRecall(t *testing.T, r *rand.Rand, m *Morton, shifts int) float64 {
+	const points, queries, k = 2000, 200, 5
+	codes := sortedCodes(r, m, points)
+	a, err := NewANNIndex(m, codes, shifts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hits := 0
+	q := make([]uint32, m.Dimensions)
+	for n := 0; n < queries; n++ {
+		for i := range q {
+			q[i] = uint32(r.Intn(int(m.Tables[i].Length)))
+		}
+		results, err := a.Query(q, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != k {
+			t.Fatalf("Query(%v, %v) found %v results", q, k, len(results))
+		}
+
+		query := m.MustEncode(q)
+		exact := make([]uint64, len(codes))
+		for i, c := range codes {
+			exact[i] = m.DistanceSquaredEuclidean(c, query)
+		}
+		sort.Slice(exact, func(i, j int) bool { return exact[i] < exact[j] })
+		for i, res := range results {
+			if codes[res.Index] != res.Code || res.Distance != m.DistanceSquaredEuclidean(res.Code, query) {
+				t.Fatalf("Query(%v) result %+v is inconsistent", q, res)
+			}
+			if i > 0 && results[i-1].Distance > res.Distance {
+				t.Fatalf("Query(%v) results are not nearest first", q)
+			}
+			if res.Distance <= exact[k-1] {
+				hits++
+			}
+		}
+	}
+	return float64(hits) / (queries * k)
+}
+
+func TestANNRecall(t *testing.T) {
+	for _, m := range []*Morton{New(2, 1024), New(3, 128)} {
+		single := annRecall(t, rand.New(rand.NewSource(132)), m, 0)
+		shifted := annRecall(t, rand.New(rand.NewSource(132)), m, 2)
+		if shifted < 0.9 || shifted < single {
+			t.Errorf("%vD: recall with 2 shifts is %v, against %v without", m.Dimensions, shifted, single)
+		}
+	}
+}
+
+func TestANNQueryEdges(t *testing.T) {
+	m := New(2, 16)
+	codes := []uint64{3, 40, 41, 200}
+	a, err := NewANNIndex(m, codes, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fewer points than k, each found once
+	results, err := a.Query([]uint32{1, 1}, 10)
+	if err != nil || len(results) != len(codes) {
+		t.Fatalf("Query for 10 of 4 points = %v, %v", results, err)
+	}
+	if results[0].Code != 3 {
+		t.Errorf("Nearest to [1 1] is %v, want 3", results[0].Code)
+	}
+	if results, err := a.Query([]uint32{1, 1}, 0); err != nil || results != nil {
+		t.Errorf("Query for 0 = %v, %v", results, err)
+	}
+	if _, err := a.Query([]uint32{1}, 1); err == nil {
+		t.Error("Query of 1 component succeeded")
+	}
+	if _, err := a.Query([]uint32{16, 1}, 1); err == nil {
+		t.Error("Query beyond the tables succeeded")
+	}
+
+	if _, err := NewANNIndex(m, []uint64{5, 4}, 0); err == nil {
+		t.Error("NewANNIndex of unsorted codes succeeded")
+	}
+	if _, err := NewANNIndex(m, codes, -1); err == nil {
+		t.Error("NewANNIndex with -1 shifts succeeded")
+	}
+}
@@ -0,0 +1,152 @@
+package morton
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Checks that n records of Dimensions components, stride apart from offset, fit within a buffer of the given length.
+func (m *Morton) checkStrided(length, offset, stride, n int) error {
+	d := int(m.Dimensions)
+	switch {
+	case d == 0:
+		return errors.New("Strided access requires at least one dimension")
+	case offset < 0 || n < 0:
+		return errors.New("Strided offset and count must not be negative")
+	case stride < d:
+		return errors.New(fmt.Sprint("Stride ", stride, " is less than the number of dimensions"))
+	case n > 0 && offset+stride*(n-1)+d > length:
+		return errors.New(fmt.Sprint("Strided records exceed the buffer of length ", length))
+	}
+	return nil
+}
+
+// Encodes n vectors read directly from buf, the i-th at buf[offset+i*stride:], e.g. from interleaved vertex attributes, without gathering them first.
+func (m *Morton) EncodeStrided(buf []uint32, offset, stride int, n int) ([]uint64, error) {
+	if err := m.checkStrided(len(buf), offset, stride, n); err != nil {
+		return nil, err
+	}
+
+	d := int(m.Dimensions)
+	codes := make([]uint64, n)
+	for i := range codes {
+		o := offset + i*stride
+		c, err := m.Encode(buf[o : o+d])
+		if err != nil {
+			return nil, fmt.Errorf("Record %v: %w", i, err)
+		}
+		codes[i] = c
+	}
+	return codes, nil
+}
+
+// Decodes codes directly into buf, the i-th at buf[offset+i*stride:], leaving the components between records untouched.
+func (m *Morton) DecodeStrided(codes []uint64, buf []uint32, offset, stride int) error {
+	if err := m.checkStrided(len(buf), offset, stride, len(codes)); err != nil {
+		return err
+	}
+
+	d := int(m.Dimensions)
+	for i, c := range codes {
+		o := offset + i*stride
+		m.decode(c, buf[o:o+d])
+	}
+	return nil
+}
+
+// Encodes vectors and returns the codes in ascending order, with perm such that vectors[perm[i]] produced codes[i].  Each vector is encoded once; equal codes keep their original relative order.
+func (m *Morton) EncodeArgsort(vectors [][]uint32) (codes []uint64, perm []int, err error) {
+	keys := make([]uint64, len(vectors))
+	for i, v := range vectors {
+		if keys[i], err = m.Encode(v); err != nil {
+			return nil, nil, fmt.Errorf("Vector %v: %w", i, err)
+		}
+	}
+
+	perm = make([]int, len(keys))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(i, j int) bool { return keys[perm[i]] < keys[perm[j]] })
+
+	codes = make([]uint64, len(perm))
+	for i, p := range perm {
+		codes[i] = keys[p]
+	}
+	return codes, perm, nil
+}
+
+// Encodes and sorts points for a spatial index: codes ascend, and points[indices[i]] is the point of codes[i].  The same as EncodeArgsort, under the name index builders look for.
+func (m *Morton) BuildIndex(points [][]uint32) (codes []uint64, indices []int, err error) {
+	return m.EncodeArgsort(points)
+}
+
+// Encodes columns of components, vectors[i] holding dimension i's value for every row, into one column per dimension of that dimension's contributions to each row's code, as little-endian 8 byte words.  ORing the i-th words of every column gives the i-th row's code, and each column can be decoded, or loaded for SIMD, on its own.
+func (m *Morton) EncodeColumnStore(vectors [][]uint32) ([][]byte, error) {
+	d := int(m.Dimensions)
+	if len(vectors) != d {
+		return nil, ErrDimensionMismatch
+	}
+	if len(m.Tables) < d {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if m.scatter {
+		return nil, errors.New("Column store contributions cannot be combined under WithShardScatter")
+	}
+	for i, col := range vectors {
+		if len(col) != len(vectors[0]) {
+			return nil, errors.New(fmt.Sprint("Column ", i, " has ", len(col), " rows, not ", len(vectors[0])))
+		}
+	}
+
+	columns := make([][]byte, d)
+	for i, col := range vectors {
+		t := m.Tables[i]
+		columns[i] = make([]byte, 8*len(col))
+		for row, v := range col {
+			b, ok := t.lookup(v)
+			if !ok {
+				return nil, fmt.Errorf("%w.  Component %v of row %v", ErrComponentOverflow, i, row)
+			}
+			binary.LittleEndian.PutUint64(columns[i][8*row:], b.Value)
+		}
+	}
+	return columns, nil
+}
+
+// Inverse of EncodeColumnStore, returning columns of components.
+func (m *Morton) DecodeColumnStore(columns [][]byte) ([][]uint32, error) {
+	d := int(m.Dimensions)
+	if len(columns) != d {
+		return nil, ErrDimensionMismatch
+	}
+	if m.scatter {
+		return nil, errors.New("Column store contributions cannot be combined under WithShardScatter")
+	}
+	rows := 0
+	for i, col := range columns {
+		if len(col)%8 != 0 || len(col) != len(columns[0]) {
+			return nil, errors.New(fmt.Sprint("Column ", i, " is not a whole number of rows, or differs in length"))
+		}
+		rows = len(col) / 8
+	}
+
+	vectors := make([][]uint32, d)
+	for i := range vectors {
+		vectors[i] = make([]uint32, rows)
+	}
+	v := make([]uint32, d)
+	for row := 0; row < rows; row++ {
+		var code uint64
+		for _, col := range columns {
+			code |= binary.LittleEndian.Uint64(col[8*row:])
+		}
+		m.decode(code, v)
+		for i := range vectors {
+			vectors[i][row] = v[i]
+		}
+	}
+	return vectors, nil
+}
@@ -0,0 +1,296 @@
+package morton
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Buffer of n records of 3 components, stride apart from offset, with every other component set to a sentinel.
+func stridedBuffer(r *rand.Rand, offset, stride, n int) []uint32 {
+	buf := make([]uint32, offset+stride*n)
+	for i := range buf {
+		buf[i] = 0xdead
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < 3; j++ {
+			buf[offset+i*stride+j] = uint32(r.Intn(64))
+		}
+	}
+	return buf
+}
+
+func TestEncodeDecodeStrided(t *testing.T) {
+	m := New(3, 64)
+	r := rand.New(rand.NewSource(118))
+	for _, tc := range []struct{ offset, stride int }{{0, 3}, {2, 3}, {0, 4}, {1, 7}} {
+		n := 50
+		buf := stridedBuffer(r, tc.offset, tc.stride, n)
+		codes, err := m.EncodeStrided(buf, tc.offset, tc.stride, n)
+		if err != nil {
+			t.Fatalf("EncodeStrided(%v, %v): %v", tc.offset, tc.stride, err)
+		}
+		for i, c := range codes {
+			o := tc.offset + i*tc.stride
+			if want, _ := m.Encode(buf[o : o+3]); c != want {
+				t.Fatalf("offset %v, stride %v: code %v is %v, want %v", tc.offset, tc.stride, i, c, want)
+			}
+		}
+
+		out := make([]uint32, len(buf))
+		for i := range out {
+			out[i] = 0xdead
+		}
+		if err := m.DecodeStrided(codes, out, tc.offset, tc.stride); err != nil {
+			t.Fatalf("DecodeStrided(%v, %v): %v", tc.offset, tc.stride, err)
+		}
+		if !equalUint32s(out, buf) {
+			t.Errorf("offset %v, stride %v: DecodeStrided did not restore the buffer, padding included", tc.offset, tc.stride)
+		}
+	}
+}
+
+func TestStridedRejects(t *testing.T) {
+	m := New(3, 64)
+	buf := make([]uint32, 10)
+	for _, tc := range []struct{ offset, stride, n int }{
+		{0, 2, 1},  // stride below the dimensions
+		{-1, 3, 1}, // negative offset
+		{0, 3, -1}, // negative count
+		{0, 3, 4},  // last record ends at 12
+		{8, 3, 1},  // record from 8 ends at 11
+	} {
+		if _, err := m.EncodeStrided(buf, tc.offset, tc.stride, tc.n); err == nil {
+			t.Errorf("EncodeStrided(%v, %v, %v) succeeded", tc.offset, tc.stride, tc.n)
+		}
+	}
+	if codes, err := m.EncodeStrided(buf, 1, 3, 3); err != nil || len(codes) != 3 {
+		t.Errorf("EncodeStrided of records ending at the buffer's end = %v, %v", codes, err)
+	}
+	if err := m.DecodeStrided(make([]uint64, 4), buf, 0, 3); err == nil {
+		t.Error("DecodeStrided beyond the buffer succeeded")
+	}
+	buf[4] = 64
+	if _, err := m.EncodeStrided(buf, 0, 3, 3); err == nil {
+		t.Error("EncodeStrided of an overflowing component succeeded")
+	}
+}
+
+func BenchmarkEncodeStrided(b *testing.B) {
+	m := New(3, 64)
+	buf := stridedBuffer(rand.New(rand.NewSource(1)), 0, 4, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.EncodeStrided(buf, 0, 4, 1024)
+	}
+}
+
+// The gather copy into [][]uint32 that EncodeStrided avoids.
+func BenchmarkEncodeGathered(b *testing.B) {
+	m := New(3, 64)
+	buf := stridedBuffer(rand.New(rand.NewSource(1)), 0, 4, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vectors := make([][]uint32, 1024)
+		for j := range vectors {
+			vectors[j] = append([]uint32(nil), buf[4*j:4*j+3]...)
+		}
+		codes := make([]uint64, len(vectors))
+		for j, v := range vectors {
+			codes[j], _ = m.Encode(v)
+		}
+	}
+}
+
+func BenchmarkDecodeStrided(b *testing.B) {
+	m := New(3, 64)
+	codes, _ := m.EncodeStrided(stridedBuffer(rand.New(rand.NewSource(1)), 0, 4, 1024), 0, 4, 1024)
+	out := make([]uint32, 4*1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.DecodeStrided(codes, out, 0, 4)
+	}
+}
+
+func TestEncodeArgsort(t *testing.T) {
+	m := New(2, 8)
+	r := rand.New(rand.NewSource(125))
+	// Few distinct points, so most codes are duplicated
+	vectors := make([][]uint32, 300)
+	for i := range vectors {
+		vectors[i] = []uint32{uint32(r.Intn(3)), uint32(r.Intn(2))}
+	}
+	codes, perm, err := m.EncodeArgsort(vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != len(vectors) || len(perm) != len(vectors) {
+		t.Fatalf("EncodeArgsort returned %v codes and %v indices for %v vectors", len(codes), len(perm), len(vectors))
+	}
+	seen := make([]bool, len(vectors))
+	for i, p := range perm {
+		if seen[p] {
+			t.Fatalf("index %v appears twice", p)
+		}
+		seen[p] = true
+		if want, _ := m.Encode(vectors[p]); codes[i] != want {
+			t.Fatalf("codes[%v] = %v, but vectors[%v] encodes to %v", i, codes[i], p, want)
+		}
+		if i > 0 && (codes[i] < codes[i-1] || codes[i] == codes[i-1] && p < perm[i-1]) {
+			t.Fatalf("position %v is out of order, or unstable among equal codes", i)
+		}
+	}
+
+	if _, _, err := m.EncodeArgsort([][]uint32{{1, 1}, {8, 0}}); err == nil {
+		t.Error("EncodeArgsort of an overflowing vector succeeded")
+	}
+	if codes, perm, err := m.EncodeArgsort(nil); err != nil || len(codes) != 0 || len(perm) != 0 {
+		t.Errorf("EncodeArgsort(nil) = %v, %v, %v", codes, perm, err)
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	m := New(3, 1<<10)
+	points := argsortVectors(2000)
+	original := make([][]uint32, len(points))
+	for i, p := range points {
+		original[i] = append([]uint32(nil), p...)
+	}
+	codes, indices, err := m.BuildIndex(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != len(points) || len(indices) != len(points) {
+		t.Fatalf("BuildIndex returned %v codes and %v indices for %v points", len(codes), len(indices), len(points))
+	}
+	sorted := append([]int(nil), indices...)
+	sort.Ints(sorted)
+	for i, p := range sorted {
+		if p != i {
+			t.Fatalf("indices are not a permutation: sorted, position %v holds %v", i, p)
+		}
+	}
+	for i, p := range indices {
+		if i > 0 && codes[i] < codes[i-1] {
+			t.Fatalf("codes[%v] = %v follows %v", i, codes[i], codes[i-1])
+		}
+		if got := m.Decode(codes[i]); !equalUint32s(got, points[p]) || !equalUint32s(points[p], original[p]) {
+			t.Fatalf("codes[%v] decodes to %v, but points[indices[%v]] is %v", i, got, i, points[p])
+		}
+	}
+
+	if _, _, err := m.BuildIndex([][]uint32{{1, 2, 3}, {1, 1024, 0}}); err == nil || !strings.HasPrefix(err.Error(), "Vector 1") {
+		t.Errorf("BuildIndex of an overflowing point returned %v", err)
+	}
+}
+
+func argsortVectors(n int) [][]uint32 {
+	r := rand.New(rand.NewSource(1))
+	vectors := make([][]uint32, n)
+	for i := range vectors {
+		vectors[i] = []uint32{uint32(r.Intn(1024)), uint32(r.Intn(1024)), uint32(r.Intn(1024))}
+	}
+	return vectors
+}
+
+func BenchmarkEncodeArgsort(b *testing.B) {
+	m := New(3, 1024)
+	vectors := argsortVectors(4096)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.EncodeArgsort(vectors)
+	}
+}
+
+// The naive argsort, encoding in the comparator.
+func BenchmarkArgsortReencoding(b *testing.B) {
+	m := New(3, 1024)
+	vectors := argsortVectors(4096)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		perm := make([]int, len(vectors))
+		for j := range perm {
+			perm[j] = j
+		}
+		sort.SliceStable(perm, func(x, y int) bool {
+			cx, _ := m.Encode(vectors[perm[x]])
+			cy, _ := m.Encode(vectors[perm[y]])
+			return cx < cy
+		})
+	}
+}
+
+func TestColumnStore(t *testing.T) {
+	for _, m := range []*Morton{New(3, 1000), New(3, 1<<10, WithGrayCode())} {
+		r := rand.New(rand.NewSource(144))
+		const rows = 500
+		columns := make([][]uint32, 3)
+		flat := make([]uint32, 3*rows)
+		for i := range columns {
+			columns[i] = make([]uint32, rows)
+			for row := range columns[i] {
+				columns[i][row] = uint32(r.Intn(1000))
+				flat[3*row+i] = columns[i][row]
+			}
+		}
+		store, err := m.EncodeColumnStore(columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Assembling rows from the columns gives the row-major codes
+		want, err := m.EncodeStrided(flat, 0, 3, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for row := 0; row < rows; row++ {
+			var code uint64
+			for _, col := range store {
+				code |= binary.LittleEndian.Uint64(col[8*row:])
+			}
+			if code != want[row] {
+				t.Fatalf("row %v assembles to %v, want %v", row, code, want[row])
+			}
+		}
+		// Without Gray coding, each column holds only its own dimension's bits
+		for i, col := range store {
+			mask := MaskForDimension(3, uint8(i))
+			for row := 0; row < rows && !m.gray; row++ {
+				if w := binary.LittleEndian.Uint64(col[8*row:]); w&^mask != 0 {
+					t.Fatalf("column %v row %v has bits %x beyond its dimension", i, row, w&^mask)
+				}
+			}
+		}
+
+		back, err := m.DecodeColumnStore(store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range columns {
+			if !equalUint32s(back[i], columns[i]) {
+				t.Fatalf("DecodeColumnStore column %v differs from the input", i)
+			}
+		}
+	}
+}
+
+func TestColumnStoreRejects(t *testing.T) {
+	m := New(2, 16)
+	for _, columns := range [][][]uint32{{{1, 2}}, {{1, 2}, {3}}, {{1, 16}, {3, 4}}} {
+		if _, err := m.EncodeColumnStore(columns); err == nil {
+			t.Errorf("EncodeColumnStore(%v) succeeded", columns)
+		}
+	}
+	if _, err := New(2, 16, WithShardScatter()).EncodeColumnStore([][]uint32{{1}, {2}}); err == nil {
+		t.Error("EncodeColumnStore under WithShardScatter succeeded")
+	}
+	for _, columns := range [][][]byte{{make([]byte, 8)}, {make([]byte, 8), make([]byte, 16)}, {make([]byte, 7), make([]byte, 7)}} {
+		if _, err := m.DecodeColumnStore(columns); err == nil {
+			t.Errorf("DecodeColumnStore of columns of %v bytes succeeded", len(columns[0]))
+		}
+	}
+	if got, err := m.DecodeColumnStore([][]byte{nil, nil}); err != nil || len(got) != 2 || len(got[0]) != 0 {
+		t.Errorf("DecodeColumnStore of empty columns = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,71 @@
+package morton
+
+import (
+	"errors"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+// Per operation cost of Encode and Decode, see Measure.
+type BenchmarkResult struct {
+	EncodeNsPerOp     float64
+	DecodeNsPerOp     float64
+	EncodeAllocsPerOp float64
+	DecodeAllocsPerOp float64
+}
+
+// Times n encodes followed by n decodes, of deterministically seeded random in-table vectors, and returns nanoseconds per operation.  See Measure for allocations.
+func (m *Morton) Benchmark(n int) (encodeNs, decodeNs float64, err error) {
+	r, err := m.Measure(n)
+	return r.EncodeNsPerOp, r.DecodeNsPerOp, err
+}
+
+// Like Benchmark, additionally counting heap allocations per operation via runtime.ReadMemStats.
+func (m *Morton) Measure(n int) (result BenchmarkResult, err error) {
+	if n <= 0 {
+		err = errors.New("Benchmark requires a positive number of operations")
+		return
+	}
+	if len(m.Tables) == 0 {
+		err = errors.New("No lookup tables.  Please generate them via CreateTables().")
+		return
+	}
+
+	// Inputs are generated up front so they are not measured
+	rnd := rand.New(rand.NewSource(1))
+	vectors := make([][]uint32, n)
+	for i := range vectors {
+		v := make([]uint32, len(m.Tables))
+		for k, t := range m.Tables {
+			v[k] = uint32(rnd.Int63n(int64(t.Length)))
+		}
+		vectors[i] = v
+	}
+	codes := make([]uint64, n)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i, v := range vectors {
+		if codes[i], err = m.Encode(v); err != nil {
+			return
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	result.EncodeNsPerOp = float64(elapsed.Nanoseconds()) / float64(n)
+	result.EncodeAllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(n)
+
+	runtime.ReadMemStats(&before)
+	start = time.Now()
+	for _, c := range codes {
+		m.Decode(c)
+	}
+	elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	result.DecodeNsPerOp = float64(elapsed.Nanoseconds()) / float64(n)
+	result.DecodeAllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(n)
+
+	return
+}
@@ -0,0 +1,35 @@
+package morton
+
+import "testing"
+
+func TestMeasure(t *testing.T) {
+	m := New(3, 1024)
+	r, err := m.Measure(10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.EncodeNsPerOp <= 0 || r.DecodeNsPerOp <= 0 {
+		t.Errorf("Measure = %+v, want positive times", r)
+	}
+	// Encode allocates nothing; Decode at most its result
+	if r.EncodeAllocsPerOp > 0.1 {
+		t.Errorf("Encode allocates %v times per operation", r.EncodeAllocsPerOp)
+	}
+	if r.DecodeAllocsPerOp < 0 || r.DecodeAllocsPerOp > 1.1 {
+		t.Errorf("Decode allocates %v times per operation", r.DecodeAllocsPerOp)
+	}
+
+	encodeNs, decodeNs, err := m.Benchmark(1000)
+	if err != nil || encodeNs <= 0 || decodeNs <= 0 {
+		t.Errorf("Benchmark = %v, %v, %v", encodeNs, decodeNs, err)
+	}
+}
+
+func TestMeasureRejects(t *testing.T) {
+	if _, err := New(2, 8).Measure(0); err == nil {
+		t.Error("Measure of 0 operations succeeded")
+	}
+	if _, _, err := new(Morton).Benchmark(10); err == nil {
+		t.Error("Benchmark without tables succeeded")
+	}
+}
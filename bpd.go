@@ -0,0 +1,89 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// Interleaves dimensions of unequal widths: bit positions are dealt round robin, from the least significant, to the dimensions that still have bits left, and each dimension's positions form its mask.
+type maskInterleaver []uint64
+
+func newMaskInterleaver(bpd []uint8) maskInterleaver {
+	masks := make(maskInterleaver, len(bpd))
+	pos := uint(0)
+	for round := uint8(0); pos < 64; round++ {
+		dealt := false
+		for i, b := range bpd {
+			if round < b {
+				masks[i] |= 1 << pos
+				pos++
+				dealt = true
+			}
+		}
+		if !dealt {
+			break
+		}
+	}
+	return masks
+}
+
+// Deposits the low bits of value into the set bits of the dimension's mask.
+func (mi maskInterleaver) Spread(value uint32, dim uint8) (code uint64) {
+	v := uint64(value)
+	for mask := mi[dim]; mask != 0 && v != 0; mask &= mask - 1 {
+		code |= (v & 1) << bits.TrailingZeros64(mask)
+		v >>= 1
+	}
+	return
+}
+
+// Extracts the set bits of the dimension's mask into the low bits of the result.
+func (mi maskInterleaver) Compact(code uint64, dim uint8) (value uint32) {
+	i := uint(0)
+	for mask := mi[dim]; mask != 0; mask &= mask - 1 {
+		value |= uint32(code>>bits.TrailingZeros64(mask)&1) << i
+		i++
+	}
+	return
+}
+
+// Total bits of the code.
+func (mi maskInterleaver) bits() (n uint8) {
+	for _, mask := range mi {
+		n += uint8(bits.OnesCount64(mask))
+	}
+	return
+}
+
+// A Morton with m's options in which dimension i has bpd[i] bits, so that e.g. space and time can have different resolutions.  Each table holds 2^bpd[i] entries, so widths range from 1 to 21 bits, keeping each table within 32 MiB, and their sum may not exceed 64.  Higher bits of the code come from the wider dimensions alone, once the narrower ones run out.  Helpers that assume equal widths, such as the cell and range helpers, do not apply.
+func (m *Morton) WithBitsPerDimension(bpd []uint8) (*Morton, error) {
+	if len(bpd) == 0 || len(bpd) > 64 {
+		return nil, errors.New("Bits per dimension requires between 1 and 64 dimensions")
+	}
+	total := 0
+	for i, b := range bpd {
+		if b == 0 || b > maxTableBits {
+			return nil, errors.New(fmt.Sprint("Dimension ", i, " must have between 1 and ", maxTableBits, " bits"))
+		}
+		total += int(b)
+	}
+	if total > 64 {
+		return nil, errors.New(fmt.Sprint("Bits per dimension total ", total, ", exceeding 64"))
+	}
+
+	n := new(Morton)
+	for _, opt := range append(m.options(), WithInterleaver(newMaskInterleaver(bpd))) {
+		opt(n)
+	}
+
+	d := uint8(len(bpd))
+	n.Dimensions = d
+	n.Magic = MakeMagic(d)
+	for i, b := range bpd {
+		n.Tables = append(n.Tables, createTable(uint8(i), d, 1<<b, n.spread(uint8(i))))
+	}
+	sort.Sort(ByTable(n.Tables))
+	return n, nil
+}
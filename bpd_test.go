@@ -0,0 +1,93 @@
+package morton
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Reference interleave for unequal widths: round k takes bit k of each dimension that has more than k bits, in dimension order.
+func referenceBPD(bpd []uint8, vector []uint32) (code uint64) {
+	pos := uint(0)
+	for k := uint8(0); ; k++ {
+		dealt := false
+		for i, b := range bpd {
+			if k < b {
+				code |= uint64(vector[i]>>k&1) << pos
+				pos++
+				dealt = true
+			}
+		}
+		if !dealt {
+			return
+		}
+	}
+}
+
+func TestWithBitsPerDimension(t *testing.T) {
+	base := New(2, 4)
+	r := rand.New(rand.NewSource(1))
+	for _, bpd := range [][]uint8{
+		{20, 20, 20, 4},
+		{1, 21},
+		{21, 1, 5},
+		{3},
+		{16, 16, 16, 16},
+		{8, 1, 8, 1, 8},
+	} {
+		m, err := base.WithBitsPerDimension(bpd)
+		if err != nil {
+			t.Fatalf("%v: %v", bpd, err)
+		}
+		total := 0
+		for _, b := range bpd {
+			total += int(b)
+		}
+		if int(m.codeBits()) != total {
+			t.Errorf("%v: %v code bits, want %v", bpd, m.codeBits(), total)
+		}
+
+		for n := 0; n < 500; n++ {
+			v := make([]uint32, len(bpd))
+			for i, b := range bpd {
+				v[i] = uint32(r.Int63n(1 << b))
+				if n == 0 {
+					v[i] = 1<<b - 1
+				}
+			}
+			code, err := m.Encode(v)
+			if err != nil {
+				t.Fatalf("%v: Encode(%v): %v", bpd, v, err)
+			}
+			if want := referenceBPD(bpd, v); code != want {
+				t.Fatalf("%v: Encode(%v) = %#x, want %#x", bpd, v, code, want)
+			}
+			got := m.Decode(code)
+			for i := range v {
+				if got[i] != v[i] {
+					t.Fatalf("%v: Decode(Encode(%v)) = %v", bpd, v, got)
+				}
+			}
+		}
+
+		over := make([]uint32, len(bpd))
+		over[0] = 1 << bpd[0]
+		if _, err := m.Encode(over); err == nil {
+			t.Errorf("%v: encoding %v succeeded, want an error", bpd, over)
+		}
+	}
+}
+
+func TestWithBitsPerDimensionLimits(t *testing.T) {
+	base := New(2, 4)
+	for _, bpd := range [][]uint8{
+		nil,
+		{0, 4},
+		{maxTableBits + 1},
+		{31},
+		{21, 21, 21, 2},
+	} {
+		if _, err := base.WithBitsPerDimension(bpd); err == nil {
+			t.Errorf("%v: succeeded, want an error", bpd)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+var ErrInvalidLevel = errors.New("Level exceeds the number of bits per dimension")
+
+/*
+Cells are the aligned boxes of the implicit 2^N-ary tree over the code space.  Level 0 is the whole bit budget, and each level halves every
+dimension, down to single coordinates at level Bits().  A cell is identified by the code of its minimum corner, i.e., any code within it
+truncated to its level (see AtLevel), so cell codes sort with point codes.  Cell helpers assume the default layout.
+*/
+
+// Number of bits per dimension, i.e., needed to represent the largest table index.
+func (m *Morton) Bits() uint8 {
+	return uint8(m.tableBits())
+}
+
+// Number of low code bits below a cell at level.
+func (m *Morton) cellShift(level uint8) (uint8, error) {
+	if err := m.checkLayout(); err != nil {
+		return 0, err
+	}
+	b := m.Bits()
+	if level > b {
+		return 0, ErrInvalidLevel
+	}
+	return (b - level) * m.Dimensions, nil
+}
+
+// Truncates code to the cell containing it at level.
+func (m *Morton) AtLevel(code uint64, level uint8) (uint64, error) {
+	s, err := m.cellShift(level)
+	if err != nil {
+		return 0, err
+	}
+	return code &^ lowMask(s), nil
+}
+
+// Encodes vector once and derives its cell codes at each of levels, in the order given, by masking.
+func (m *Morton) EncodeLevels(vector []uint32, levels []uint8) ([]uint64, error) {
+	if err := m.checkLayout(); err != nil {
+		return nil, err
+	}
+	for _, l := range levels {
+		if l > m.Bits() {
+			return nil, fmt.Errorf("%w.  Level %v of %v", ErrInvalidLevel, l, m.Bits())
+		}
+	}
+	code, err := m.Encode(vector)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]uint64, len(levels))
+	for i, l := range levels {
+		codes[i], _ = m.AtLevel(code, l)
+	}
+	return codes, nil
+}
+
+// Minimum and maximum corner coordinates, inclusive, of the cell at level containing cellCode.  The bounds span the bit budget, so for tables whose length is not a power of two they may extend beyond the tables.
+func (m *Morton) CellBounds(cellCode uint64, level uint8) (min, max []uint32, err error) {
+	s, err := m.cellShift(level)
+	if err != nil {
+		return
+	}
+	base := cellCode &^ lowMask(s)
+	min, max = m.Decode(base), m.Decode(base|lowMask(s))
+	return
+}
+
+// Squared distance from point to the nearest point of the cell at level containing cellCode, zero when point is inside it, saturating at math.MaxUint64.
+func (m *Morton) MinDistanceSquaredToCell(point []uint32, cellCode uint64, level uint8) (uint64, error) {
+	return m.cellDistance(point, cellCode, level, func(p, lo, hi uint32) uint32 {
+		switch {
+		case p < lo:
+			return lo - p
+		case p > hi:
+			return p - hi
+		}
+		return 0
+	})
+}
+
+// Squared distance from point to the farthest corner of the cell at level containing cellCode, saturating at math.MaxUint64.
+func (m *Morton) MaxDistanceSquaredToCell(point []uint32, cellCode uint64, level uint8) (uint64, error) {
+	return m.cellDistance(point, cellCode, level, func(p, lo, hi uint32) uint32 {
+		a, b := absDiff(p, lo), absDiff(p, hi)
+		if a > b {
+			return a
+		}
+		return b
+	})
+}
+
+// Euclidean form of MinDistanceSquaredToCell, for best-first traversal.  The result is NaN if point or level is invalid.
+func (m *Morton) MinDistanceToCell(point []uint32, cellCode uint64, level uint8) float64 {
+	d, err := m.MinDistanceSquaredToCell(point, cellCode, level)
+	if err != nil {
+		return math.NaN()
+	}
+	return math.Sqrt(float64(d))
+}
+
+// Euclidean form of MaxDistanceSquaredToCell, an upper bound for pruning.  The result is NaN if point or level is invalid.
+func (m *Morton) MaxDistanceToCell(point []uint32, cellCode uint64, level uint8) float64 {
+	d, err := m.MaxDistanceSquaredToCell(point, cellCode, level)
+	if err != nil {
+		return math.NaN()
+	}
+	return math.Sqrt(float64(d))
+}
+
+// Sums the squares of gap(point, min, max) over each dimension of the cell.
+func (m *Morton) cellDistance(point []uint32, cellCode uint64, level uint8, gap func(p, lo, hi uint32) uint32) (dist uint64, err error) {
+	if len(point) != int(m.Dimensions) {
+		err = ErrDimensionMismatch
+		return
+	}
+	min, max, err := m.CellBounds(cellCode, level)
+	if err != nil {
+		return
+	}
+
+	for i, p := range point {
+		g := uint64(gap(p, min[i], max[i]))
+		var carry uint64
+		if dist, carry = bits.Add64(dist, g*g, 0); carry != 0 {
+			return math.MaxUint64, nil
+		}
+	}
+	return
+}
+
+// Relation of a cell to a query region.
+type Containment uint8
+
+const (
+	Outside Containment = iota
+	Partial
+	Inside
+)
+
+// Tests a cell, given its inclusive corner coordinates, against a region.
+type CellTest func(min, max []uint32) Containment
+
+// Visits, in ascending code order, the cells accepted by test: cells fully inside are visited without descending, and cells still partial at maxLevel are visited as such.  Visiting stops when visit returns false, which walk reports.
+func (m *Morton) walk(maxLevel uint8, test CellTest, visit func(code uint64, level uint8, c Containment) bool) bool {
+	d := int(m.Dimensions)
+	b := m.Bits()
+	if d == 0 || len(m.Tables) < d || maxLevel > b || !m.defaultLayout() {
+		return true
+	}
+
+	lo, hi := make([]uint32, d), make([]uint32, d)
+	for i := range hi {
+		hi[i] = uint32(lowMask(b))
+	}
+	return m.descend(0, 0, maxLevel, lo, hi, test, visit)
+}
+
+func (m *Morton) descend(code uint64, level, maxLevel uint8, lo, hi []uint32, test CellTest, visit func(uint64, uint8, Containment) bool) bool {
+	c := test(lo, hi)
+	switch {
+	case c == Outside:
+		return true
+	case c == Inside || level == maxLevel:
+		return visit(code, level, c)
+	}
+
+	d := uint(m.Dimensions)
+	half := uint32(1) << (m.Bits() - level - 1)
+	shift := uint(m.Bits()-level-1) * d
+	clo, chi := make([]uint32, d), make([]uint32, d)
+	for child := uint64(0); child < 1<<d; child++ {
+		for i := uint(0); i < d; i++ {
+			clo[i], chi[i] = lo[i], hi[i]
+			if child>>i&1 == 0 {
+				chi[i] = lo[i] + half - 1
+			} else {
+				clo[i] = lo[i] + half
+			}
+		}
+		if !m.descend(code|child<<shift, level+1, maxLevel, clo, chi, test, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+// Cell test for the inclusive box [min, max].
+func boxTest(min, max []uint32) CellTest {
+	return func(lo, hi []uint32) Containment {
+		c := Inside
+		for i := range lo {
+			if hi[i] < min[i] || lo[i] > max[i] {
+				return Outside
+			}
+			if lo[i] < min[i] || hi[i] > max[i] {
+				c = Partial
+			}
+		}
+		return c
+	}
+}
+
+// Calls fn for every in-table coordinate vector, with its code, in ascending code order, until fn returns false.  Cells wholly within the tables are enumerated by incrementing the code.  The vector is reused between calls and must not be retained.
+func (m *Morton) ForEach(fn func(vector []uint32, code uint64) bool) {
+	d := int(m.Dimensions)
+	if d == 0 || len(m.Tables) < d {
+		return
+	}
+
+	min, max := make([]uint32, d), make([]uint32, d)
+	for i := range max {
+		if m.Tables[i].Length == 0 {
+			return
+		}
+		max[i] = m.Tables[i].Length - 1
+	}
+
+	vector := make([]uint32, d)
+	if !m.defaultLayout() {
+		m.forEachCode(vector, fn)
+		return
+	}
+	m.walk(m.Bits(), boxTest(min, max), func(code uint64, level uint8, c Containment) bool {
+		s, _ := m.cellShift(level)
+		for end := code + lowMask(s); ; code++ {
+			m.decode(code, vector)
+			if !fn(vector, code) {
+				return false
+			}
+			if code == end {
+				return true
+			}
+		}
+	})
+}
+
+// ForEach for layouts whose components are not monotonic in the code: every code up to the highest bit in use is decoded, and yielded only if it encodes back to itself.  This visits at most 2^d times as many codes as there are vectors.
+func (m *Morton) forEachCode(vector []uint32, fn func(vector []uint32, code uint64) bool) {
+	end := lowMask(uint8(bits.Len64(m.MaxCode())))
+	for code := uint64(0); ; code++ {
+		m.decode(code, vector)
+		if c, err := m.Encode(vector); err == nil && c == code && !fn(vector, code) {
+			return
+		}
+		if code == end {
+			return
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Interleaver placing the dimensions in reverse order, for exercising custom layouts.
+type reversedInterleaver struct {
+	dims uint8
+}
+
+func (r reversedInterleaver) Spread(value uint32, dim uint8) uint64 {
+	return Dilate(value, r.dims) << (r.dims - 1 - dim)
+}
+
+func (r reversedInterleaver) Compact(code uint64, dim uint8) uint32 {
+	return Undilate(code>>(r.dims-1-dim), r.dims)
+}
+
+// Every in-table vector of m with its code, in ascending code order, by brute force.
+func allCodes(t *testing.T, m *Morton) (codes []uint64, vectors [][]uint32) {
+	t.Helper()
+	d := int(m.Dimensions)
+	v := make([]uint32, d)
+	for {
+		c, err := m.Encode(v)
+		if err != nil {
+			t.Fatalf("Encode(%v): %v", v, err)
+		}
+		codes = append(codes, c)
+		vectors = append(vectors, append([]uint32(nil), v...))
+
+		i := 0
+		for ; i < d; i++ {
+			if v[i]++; v[i] < m.Tables[i].Length {
+				break
+			}
+			v[i] = 0
+		}
+		if i == d {
+			break
+		}
+	}
+	sort.Sort(byCode{codes, vectors})
+	return
+}
+
+func TestForEach(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		m    *Morton
+	}{
+		{"2D of 5", New(2, 5)},
+		{"3D of 8", New(3, 8)},
+		{"2D of 5 Gray coded", New(2, 5, WithGrayCode())},
+		{"3D of 6 Gray coded", New(3, 6, WithGrayCode())},
+		{"2D of 5 scattered", New(2, 5, WithShardScatter())},
+		{"2D of 7 reversed", New(2, 7, WithInterleaver(reversedInterleaver{2}))},
+	} {
+		codes, vectors := allCodes(t, tc.m)
+		n := 0
+		tc.m.ForEach(func(vector []uint32, code uint64) bool {
+			if n >= len(codes) {
+				t.Fatalf("%v: more than %v pairs", tc.name, len(codes))
+			}
+			if code != codes[n] || !equalUint32s(vector, vectors[n]) {
+				t.Errorf("%v: pair %v is %v %v, want %v %v", tc.name, n, vector, code, vectors[n], codes[n])
+			}
+			if c, err := tc.m.Encode(vector); err != nil || c != code {
+				t.Errorf("%v: Encode(%v) = %v, %v, want %v", tc.name, vector, c, err, code)
+			}
+			n++
+			return true
+		})
+		if n != len(codes) {
+			t.Errorf("%v: %v pairs, want %v", tc.name, n, len(codes))
+		}
+	}
+}
+
+func TestForEachStops(t *testing.T) {
+	for _, m := range []*Morton{New(2, 5), New(2, 5, WithGrayCode())} {
+		codes, _ := allCodes(t, m)
+		var seen []uint64
+		m.ForEach(func(vector []uint32, code uint64) bool {
+			seen = append(seen, code)
+			return len(seen) < 7
+		})
+		if len(seen) != 7 {
+			t.Fatalf("enumeration continued to %v pairs after fn returned false", len(seen))
+		}
+		for i := range seen {
+			if seen[i] != codes[i] {
+				t.Errorf("pair %v has code %v, want %v", i, seen[i], codes[i])
+			}
+		}
+	}
+	new(Morton).ForEach(func([]uint32, uint64) bool {
+		t.Error("ForEach without tables called fn")
+		return false
+	})
+}
+
+func equalUint32s(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Brute force squared distances from point to every point of the box min..max, whose extremes the cell distances must match.
+func boxDistances(point, min, max []uint32) (near, far uint64) {
+	near = math.MaxUint64
+	v := append([]uint32(nil), min...)
+	for {
+		var d uint64
+		for i := range v {
+			g := uint64(absDiff(point[i], v[i]))
+			d += g * g
+		}
+		if d < near {
+			near = d
+		}
+		if d > far {
+			far = d
+		}
+		i := 0
+		for ; i < len(v); i++ {
+			if v[i] < max[i] {
+				v[i]++
+				break
+			}
+			v[i] = min[i]
+		}
+		if i == len(v) {
+			return
+		}
+	}
+}
+
+func TestDistanceToCell(t *testing.T) {
+	r := rand.New(rand.NewSource(111))
+	for _, m := range []*Morton{New(2, 16), New(3, 8)} {
+		d := int(m.Dimensions)
+		for n := 0; n < 200; n++ {
+			// Points reach past the domain on either side of the cells at its boundary
+			point := make([]uint32, d)
+			for i := range point {
+				point[i] = uint32(r.Intn(int(m.Tables[i].Length) + 4))
+			}
+			code := uint64(r.Int63n(1 << (uint(m.Bits()) * uint(d))))
+			for level := uint8(0); level <= m.Bits(); level++ {
+				min, max, err := m.CellBounds(code, level)
+				if err != nil {
+					t.Fatal(err)
+				}
+				near, far := boxDistances(point, min, max)
+				if got, err := m.MinDistanceSquaredToCell(point, code, level); err != nil || got != near {
+					t.Fatalf("%vD: MinDistanceSquaredToCell(%v, %v, %v) = %v, %v; want %v", d, point, code, level, got, err, near)
+				}
+				if got, err := m.MaxDistanceSquaredToCell(point, code, level); err != nil || got != far {
+					t.Fatalf("%vD: MaxDistanceSquaredToCell(%v, %v, %v) = %v, %v; want %v", d, point, code, level, got, err, far)
+				}
+				if got := m.MinDistanceToCell(point, code, level); got != math.Sqrt(float64(near)) {
+					t.Fatalf("%vD: MinDistanceToCell(%v, %v, %v) = %v", d, point, code, level, got)
+				}
+				if got := m.MaxDistanceToCell(point, code, level); got != math.Sqrt(float64(far)) {
+					t.Fatalf("%vD: MaxDistanceToCell(%v, %v, %v) = %v", d, point, code, level, got)
+				}
+			}
+		}
+	}
+}
+
+func TestDistanceToCellCases(t *testing.T) {
+	m := New(2, 16)
+	cell, _ := m.Encode([]uint32{4, 8}) // Level 2 covers 4..7 by 8..11
+	for _, tc := range []struct {
+		point     []uint32
+		near, far uint64
+	}{
+		{[]uint32{5, 9}, 0, 2*2 + 2*2},
+		{[]uint32{4, 8}, 0, 3*3 + 3*3},
+		{[]uint32{5, 12}, 1, 2*2 + 4*4}, // Aligned with the y face
+		{[]uint32{0, 8}, 4 * 4, 7*7 + 3*3},
+		{[]uint32{0, 0}, 4*4 + 8*8, 7*7 + 11*11},
+	} {
+		if got, _ := m.MinDistanceSquaredToCell(tc.point, cell, 2); got != tc.near {
+			t.Errorf("MinDistanceSquaredToCell(%v) = %v, want %v", tc.point, got, tc.near)
+		}
+		if got, _ := m.MaxDistanceSquaredToCell(tc.point, cell, 2); got != tc.far {
+			t.Errorf("MaxDistanceSquaredToCell(%v) = %v, want %v", tc.point, got, tc.far)
+		}
+	}
+
+	if _, err := m.MinDistanceSquaredToCell([]uint32{1}, cell, 2); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("MinDistanceSquaredToCell of 1 component returned %v", err)
+	}
+	if _, err := m.MaxDistanceSquaredToCell([]uint32{1, 1}, cell, 5); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("MaxDistanceSquaredToCell at level 5 returned %v", err)
+	}
+	if !math.IsNaN(m.MinDistanceToCell([]uint32{1, 1}, cell, 5)) || !math.IsNaN(m.MaxDistanceToCell(nil, cell, 0)) {
+		t.Error("Euclidean cell distances of invalid input are not NaN")
+	}
+}
+
+func TestEncodeLevels(t *testing.T) {
+	m := New(3, 1<<10)
+	r := rand.New(rand.NewSource(136))
+	// Levels in any order, with repeats
+	levels := []uint8{m.Bits(), 0, 3, 7, 3, 1}
+	for n := 0; n < 200; n++ {
+		v := []uint32{uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10))}
+		got, err := m.EncodeLevels(v, levels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(levels) {
+			t.Fatalf("EncodeLevels(%v) returned %v codes, want %v", v, len(got), len(levels))
+		}
+		full, _ := m.Encode(v)
+		for i, l := range levels {
+			if want, _ := m.AtLevel(full, l); got[i] != want {
+				t.Fatalf("EncodeLevels(%v) at level %v = %v, want %v", v, l, got[i], want)
+			}
+			min, max, _ := m.CellBounds(got[i], l)
+			if !inBox(v, min, max) {
+				t.Fatalf("%v lies outside its level %v cell, %v to %v", v, l, min, max)
+			}
+		}
+	}
+
+	if got, err := m.EncodeLevels([]uint32{1, 2, 3}, nil); err != nil || len(got) != 0 {
+		t.Errorf("EncodeLevels without levels = %v, %v", got, err)
+	}
+}
+
+func TestEncodeLevelsRejects(t *testing.T) {
+	m := New(2, 16)
+	if _, err := m.EncodeLevels([]uint32{1, 2}, []uint8{2, m.Bits() + 1}); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("EncodeLevels beyond the bit budget returned %v, want ErrInvalidLevel", err)
+	}
+	if _, err := m.EncodeLevels([]uint32{16, 2}, []uint8{1}); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("EncodeLevels beyond the tables returned %v, want ErrComponentOverflow", err)
+	}
+	for _, tc := range otherLayouts(2, 16) {
+		if got, err := tc.m.EncodeLevels([]uint32{5, 9}, []uint8{1, 2, 4}); !errors.Is(err, ErrUnsupportedLayout) {
+			t.Errorf("%v: EncodeLevels = %v, %v; want ErrUnsupportedLayout", tc.name, got, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Voxel keys: the local coordinates within a chunk form the low 3*LocalBits bits as a 3 dimension Morton code, and the chunk coordinates, biased by 2^(ChunkBits-1) so they may be negative, form the high 3*ChunkBits bits as another.
+type ChunkKeyer struct {
+	LocalBits uint8
+	ChunkBits uint8
+}
+
+// Both widths must be non-zero and fit 64 bits across 3 dimensions.
+func NewChunkKeyer(localBits, chunkBits uint8) (*ChunkKeyer, error) {
+	if localBits == 0 || chunkBits == 0 || 3*(uint(localBits)+uint(chunkBits)) > 64 {
+		return nil, errors.New("Chunk keys require non-zero bit widths totalling at most 64 bits across 3 dimensions")
+	}
+	return &ChunkKeyer{localBits, chunkBits}, nil
+}
+
+func (c *ChunkKeyer) bias() int64 {
+	return 1 << (c.ChunkBits - 1)
+}
+
+// Encodes the voxel at local within chunk.
+func (c *ChunkKeyer) Encode(chunk [3]int32, local [3]uint32) (uint64, error) {
+	var key uint64
+	for i := 0; i < 3; i++ {
+		if local[i] >= 1<<c.LocalBits {
+			return 0, fmt.Errorf("%w.  Local component %v exceeds %v bits", ErrComponentOverflow, i, c.LocalBits)
+		}
+		b := int64(chunk[i]) + c.bias()
+		if b < 0 || b >= 1<<c.ChunkBits {
+			return 0, fmt.Errorf("%w.  Chunk component %v exceeds %v signed bits", ErrComponentOverflow, i, c.ChunkBits)
+		}
+		key |= Dilate(local[i], 3)<<uint(i) | Dilate(uint32(b), 3)<<(uint(i)+3*uint(c.LocalBits))
+	}
+	return key, nil
+}
+
+// Inverse of Encode.
+func (c *ChunkKeyer) Decode(key uint64) (chunk [3]int32, local [3]uint32) {
+	high := key >> (3 * uint(c.LocalBits))
+	for i := 0; i < 3; i++ {
+		local[i] = Undilate(key>>uint(i), 3) & (1<<c.LocalBits - 1)
+		chunk[i] = int32(int64(Undilate(high>>uint(i), 3)&(1<<c.ChunkBits-1)) - c.bias())
+	}
+	return
+}
+
+// Key of the voxel one step along axis (0 to 2) in direction dir (+1 or -1), carrying into the neighboring chunk at chunk boundaries.  Stepping beyond the representable chunks is an error.
+func (c *ChunkKeyer) Neighbor(key uint64, axis uint8, dir int) (uint64, error) {
+	if axis > 2 || (dir != 1 && dir != -1) {
+		return 0, errors.New("Neighbor requires an axis of 0 to 2 and a direction of +1 or -1")
+	}
+
+	chunk, local := c.Decode(key)
+	top := uint32(1)<<c.LocalBits - 1
+	switch {
+	case dir > 0 && local[axis] == top:
+		local[axis] = 0
+		chunk[axis]++
+	case dir < 0 && local[axis] == 0:
+		local[axis] = top
+		chunk[axis]--
+	case dir > 0:
+		local[axis]++
+	default:
+		local[axis]--
+	}
+
+	// Chunks are at most 20 bits, so this never overflows int32; Encode checks the bias range
+	return c.Encode(chunk, local)
+}
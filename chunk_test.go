@@ -0,0 +1,109 @@
+package morton
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Splits a global voxel coordinate into its chunk and local coordinates.
+func splitVoxel(g int64, localBits uint8) (int32, uint32) {
+	return int32(g >> localBits), uint32(g & (1<<localBits - 1))
+}
+
+func TestChunkKeyerRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(115))
+	for _, widths := range [][2]uint8{{5, 10}, {4, 4}, {1, 1}, {10, 11}} {
+		c, err := NewChunkKeyer(widths[0], widths[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		half := int64(1) << (widths[1] - 1)
+		for n := 0; n < 500; n++ {
+			var chunk [3]int32
+			var local [3]uint32
+			for i := range chunk {
+				chunk[i] = int32(r.Int63n(2*half) - half)
+				local[i] = uint32(r.Intn(1 << widths[0]))
+			}
+			key, err := c.Encode(chunk, local)
+			if err != nil {
+				t.Fatalf("%v: Encode(%v, %v): %v", widths, chunk, local, err)
+			}
+			if gc, gl := c.Decode(key); gc != chunk || gl != local {
+				t.Fatalf("%v: Decode(Encode(%v, %v)) = %v, %v", widths, chunk, local, gc, gl)
+			}
+		}
+	}
+}
+
+func TestChunkKeyerNeighbor(t *testing.T) {
+	c, _ := NewChunkKeyer(5, 4)
+	r := rand.New(rand.NewSource(1152))
+	for n := 0; n < 300; n++ {
+		// Coordinates near chunk boundaries, in chunks -7 to 6 so every step stays representable
+		var global [3]int64
+		for i := range global {
+			global[i] = int64(r.Intn(14)-7)*32 + int64([]int{0, 1, 30, 31}[r.Intn(4)])
+		}
+		var chunk [3]int32
+		var local [3]uint32
+		for i := range global {
+			chunk[i], local[i] = splitVoxel(global[i], 5)
+		}
+		key, err := c.Encode(chunk, local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for axis := uint8(0); axis < 3; axis++ {
+			for _, dir := range []int{1, -1} {
+				got, err := c.Neighbor(key, axis, dir)
+				if err != nil {
+					t.Fatalf("Neighbor(%v, %v, %v): %v", global, axis, dir, err)
+				}
+				want := chunk
+				wantLocal := local
+				want[axis], wantLocal[axis] = splitVoxel(global[axis]+int64(dir), 5)
+				if gc, gl := c.Decode(got); gc != want || gl != wantLocal {
+					t.Fatalf("Neighbor(%v, %v, %v) decodes to %v, %v; want %v, %v", global, axis, dir, gc, gl, want, wantLocal)
+				}
+				if back, _ := c.Neighbor(got, axis, -dir); back != key {
+					t.Fatalf("Neighbor(%v, %v, %v) then back gave %v, want %v", global, axis, dir, back, key)
+				}
+			}
+		}
+	}
+}
+
+func TestChunkKeyerRejects(t *testing.T) {
+	for _, widths := range [][2]uint8{{0, 4}, {4, 0}, {11, 11}} {
+		if _, err := NewChunkKeyer(widths[0], widths[1]); err == nil {
+			t.Errorf("NewChunkKeyer(%v, %v) succeeded", widths[0], widths[1])
+		}
+	}
+
+	c, _ := NewChunkKeyer(5, 4)
+	for _, tc := range []struct {
+		chunk [3]int32
+		local [3]uint32
+	}{
+		{[3]int32{8, 0, 0}, [3]uint32{}},
+		{[3]int32{0, -9, 0}, [3]uint32{}},
+		{[3]int32{}, [3]uint32{0, 0, 32}},
+	} {
+		if _, err := c.Encode(tc.chunk, tc.local); err == nil {
+			t.Errorf("Encode(%v, %v) succeeded", tc.chunk, tc.local)
+		}
+	}
+
+	// Beyond the last chunk, and invalid steps
+	edge, _ := c.Encode([3]int32{7, 0, 0}, [3]uint32{31, 0, 0})
+	if _, err := c.Neighbor(edge, 0, 1); err == nil {
+		t.Error("Neighbor beyond the last chunk succeeded")
+	}
+	if _, err := c.Neighbor(edge, 3, 1); err == nil {
+		t.Error("Neighbor along axis 3 succeeded")
+	}
+	if _, err := c.Neighbor(edge, 0, 2); err == nil {
+		t.Error("Neighbor by 2 succeeded")
+	}
+}
@@ -0,0 +1,280 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Ranges a CodeIndex query is merged down to, beyond which filtering the over-scan is cheaper than more binary searches.
+const maxIndexRanges = 64
+
+// Pending changes are merged into the sorted codes once they exceed this, or an eighth of them.
+const minIndexPending = 64
+
+// Index of points by code, answering box queries with the points' ids.  Insertions are buffered and deletions marked until enough are pending, when they are merged into the sorted codes, so each costs amortized constant time beyond the buffer scans by queries.  Query, Count and Len may be called concurrently with one another, but not with Insert or Delete.
+type CodeIndex struct {
+	m     *Morton
+	codes []uint64
+	ids   []int
+
+	live    map[int]uint64 // Code of every id indexed
+	buffer  []indexEntry   // Inserted, unsorted
+	deleted map[int]uint64 // Codes still in the sorted codes, of deleted ids
+}
+
+type indexEntry struct {
+	code uint64
+	id   int
+}
+
+// Encodes and sorts points, identifying each by its position.  Queries decompose boxes into code ranges, so layouts other than the default return ErrUnsupportedLayout.
+func NewCodeIndex(m *Morton, points [][]uint32) (*CodeIndex, error) {
+	if err := m.checkLayout(); err != nil {
+		return nil, err
+	}
+	codes, perm, err := m.EncodeArgsort(points)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &CodeIndex{m: m, codes: codes, ids: perm, live: make(map[int]uint64, len(codes)), deleted: make(map[int]uint64)}
+	for i, id := range perm {
+		x.live[id] = codes[i]
+	}
+	return x, nil
+}
+
+// Number of points indexed.
+func (x *CodeIndex) Len() int {
+	return len(x.live)
+}
+
+// Adds point under id, which must not already be indexed.
+func (x *CodeIndex) Insert(point []uint32, id int) error {
+	if _, ok := x.live[id]; ok {
+		return errors.New(fmt.Sprint("Id ", id, " is already indexed"))
+	}
+	code, err := x.m.Encode(point)
+	if err != nil {
+		return err
+	}
+
+	x.live[id] = code
+	x.buffer = append(x.buffer, indexEntry{code, id})
+	x.maybeMerge()
+	return nil
+}
+
+// Removes the point under id, reporting whether there was one.
+func (x *CodeIndex) Delete(id int) bool {
+	code, ok := x.live[id]
+	if !ok {
+		return false
+	}
+	delete(x.live, id)
+
+	for i, e := range x.buffer {
+		if e.id == id {
+			last := len(x.buffer) - 1
+			x.buffer[i] = x.buffer[last]
+			x.buffer = x.buffer[:last]
+			return true
+		}
+	}
+	x.deleted[id] = code
+	x.maybeMerge()
+	return true
+}
+
+// Merges the pending changes into the sorted codes once there are enough of them.
+func (x *CodeIndex) maybeMerge() {
+	pending := len(x.buffer) + len(x.deleted)
+	if pending < minIndexPending || pending < len(x.codes)/8 {
+		return
+	}
+
+	sort.Slice(x.buffer, func(i, j int) bool { return x.buffer[i].code < x.buffer[j].code })
+	codes := make([]uint64, 0, len(x.live))
+	ids := make([]int, 0, len(x.live))
+	i, j := 0, 0
+	for i < len(x.codes) || j < len(x.buffer) {
+		if j == len(x.buffer) || i < len(x.codes) && x.codes[i] <= x.buffer[j].code {
+			if _, dead := x.deleted[x.ids[i]]; !dead {
+				codes, ids = append(codes, x.codes[i]), append(ids, x.ids[i])
+			}
+			i++
+			continue
+		}
+		codes, ids = append(codes, x.buffer[j].code), append(ids, x.buffer[j].id)
+		j++
+	}
+
+	x.codes, x.ids = codes, ids
+	x.buffer = x.buffer[:0]
+	x.deleted = make(map[int]uint64)
+}
+
+// Ids, in code order, of the points within the inclusive box [min, max], or nil if the box is invalid.  The box is decomposed into code ranges, each found by binary search, and points in the gaps merged across are filtered out.
+func (x *CodeIndex) Query(min, max []uint32) []int {
+	ranges, err := x.m.RangeDecompose(min, max)
+	if err != nil {
+		return nil
+	}
+	ranges = mergeRanges(ranges, maxIndexRanges)
+
+	var found []indexEntry
+	for _, r := range ranges {
+		for i := x.search(r.Lo); i < len(x.codes) && x.codes[i] <= r.Hi; i++ {
+			if _, dead := x.deleted[x.ids[i]]; !dead && x.m.InBox(x.codes[i], min, max) {
+				found = append(found, indexEntry{x.codes[i], x.ids[i]})
+			}
+		}
+	}
+	if n := len(found); x.bufferInBox(min, max, func(e indexEntry) { found = append(found, e) }) {
+		sort.SliceStable(found[n:], func(i, j int) bool { return found[n+i].code < found[n+j].code })
+		found = mergeEntries(found[:n], found[n:])
+	}
+
+	ids := make([]int, len(found))
+	for i, e := range found {
+		ids[i] = e.id
+	}
+	return ids
+}
+
+// Calls fn for each buffered entry within the box, reporting whether there were any.
+func (x *CodeIndex) bufferInBox(min, max []uint32, fn func(indexEntry)) (any bool) {
+	for _, e := range x.buffer {
+		if x.m.InBox(e.code, min, max) {
+			fn(e)
+			any = true
+		}
+	}
+	return
+}
+
+// Merges two code ordered entry lists.
+func mergeEntries(a, b []indexEntry) []indexEntry {
+	merged := make([]indexEntry, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if a[0].code <= b[0].code {
+			merged, a = append(merged, a[0]), a[1:]
+		} else {
+			merged, b = append(merged, b[0]), b[1:]
+		}
+	}
+	return append(append(merged, a...), b...)
+}
+
+// Number of points within the inclusive box [min, max], by binary searches over the sorted codes, corrected for the pending changes.
+func (x *CodeIndex) Count(min, max []uint32) (n int) {
+	ranges, err := x.m.RangeDecompose(min, max)
+	if err != nil {
+		return 0
+	}
+	for _, r := range ranges {
+		lo := x.search(r.Lo)
+		if r.Hi == ^uint64(0) {
+			n += len(x.codes) - lo
+			continue
+		}
+		n += x.search(r.Hi+1) - lo
+	}
+
+	for _, code := range x.deleted {
+		if x.m.InBox(code, min, max) {
+			n--
+		}
+	}
+	x.bufferInBox(min, max, func(indexEntry) { n++ })
+	return
+}
+
+// Position of the first code not less than code.
+func (x *CodeIndex) search(code uint64) int {
+	return sort.Search(len(x.codes), func(i int) bool { return x.codes[i] >= code })
+}
+
+// Estimates how many of the sorted codes lie within the inclusive box [min, max], by binary searches over a cover of at most budget cells (no limit if budget <= 0).  Starting from the whole domain, the occupied cell straddling the box's edges with the most codes is split until the budget is spent; the straddling cells that remain contribute in proportion to their overlap with the box, assuming codes spread evenly within them.  exact reports that none remain, so the estimate is the count.  Layouts other than the default estimate 0, inexact.
+func (m *Morton) EstimateCount(codes []uint64, min, max []uint32, budget int) (estimate uint64, exact bool) {
+	if m.checkBox(min, max) != nil || !m.defaultLayout() {
+		return 0, false
+	}
+
+	type estimateCell struct {
+		Cell
+		n        int
+		fraction float64
+	}
+	test := boxTest(min, max)
+	measure := func(c Cell) (estimateCell, bool) {
+		cmin, cmax, _ := m.CellBounds(c.Code, c.Level)
+		if test(cmin, cmax) == Outside {
+			return estimateCell{}, false
+		}
+		s, _ := m.cellShift(c.Level)
+		lo := sort.Search(len(codes), func(i int) bool { return codes[i] >= c.Code })
+		n := sort.Search(len(codes)-lo, func(i int) bool { return codes[lo+i] > c.Code|lowMask(s) })
+
+		fraction := 1.0
+		for i := range cmin {
+			overlap := float64(minUint32(cmax[i], max[i])) - float64(maxUint32(cmin[i], min[i])) + 1
+			fraction *= overlap / (float64(cmax[i]) - float64(cmin[i]) + 1)
+		}
+		return estimateCell{c, n, fraction}, n > 0
+	}
+
+	var cells []estimateCell
+	if root, ok := measure(Cell{}); ok {
+		cells = append(cells, root)
+	}
+	children := 1 << m.Dimensions
+	for {
+		// The occupied straddling cell with the most codes
+		split := -1
+		for i, c := range cells {
+			if c.fraction < 1 && (split < 0 || c.n > cells[split].n) {
+				split = i
+			}
+		}
+		if split < 0 {
+			exact = true
+			break
+		}
+		if budget > 0 && len(cells)-1+children > budget {
+			break
+		}
+
+		parent := cells[split].Cell
+		cells[split] = cells[len(cells)-1]
+		cells = cells[:len(cells)-1]
+		s, _ := m.cellShift(parent.Level + 1)
+		for k := 0; k < children; k++ {
+			if c, ok := measure(Cell{parent.Code | uint64(k)<<s, parent.Level + 1}); ok {
+				cells = append(cells, c)
+			}
+		}
+	}
+
+	var total float64
+	for _, c := range cells {
+		total += float64(c.n) * c.fraction
+	}
+	return uint64(math.Round(total)), exact
+}
+
+func minUint32(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxUint32(a, b uint32) uint32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,229 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomPoints(r *rand.Rand, m *Morton, n int) [][]uint32 {
+	points := make([][]uint32, n)
+	for i := range points {
+		points[i] = make([]uint32, m.Dimensions)
+		for k := range points[i] {
+			points[i][k] = uint32(r.Intn(int(m.Tables[k].Length)))
+		}
+	}
+	return points
+}
+
+// Checks Query and Count against the ids of the in-box points of a model, by brute force.
+func checkIndex(t *testing.T, x *CodeIndex, model map[int][]uint32, min, max []uint32) {
+	t.Helper()
+	var want []int
+	for id, p := range model {
+		if inBox(p, min, max) {
+			want = append(want, id)
+		}
+	}
+	got := x.Query(min, max)
+	if n := x.Count(min, max); n != len(want) {
+		t.Fatalf("Count(%v, %v) = %v, want %v", min, max, n, len(want))
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Query(%v, %v) found %v points, want %v", min, max, len(got), len(want))
+	}
+	for i, id := range got {
+		if i > 0 && x.m.MustEncode(model[got[i-1]]) > x.m.MustEncode(model[id]) {
+			t.Fatalf("Query(%v, %v) is out of code order at %v", min, max, i)
+		}
+	}
+	sort.Ints(got)
+	sort.Ints(want)
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("Query(%v, %v) found id %v, want %v", min, max, got[i], want[i])
+		}
+	}
+}
+
+func TestCodeIndexQuery(t *testing.T) {
+	r := rand.New(rand.NewSource(133))
+	for _, m := range []*Morton{New(2, 64), New(3, 32), New(2, 100)} {
+		points := randomPoints(r, m, 3000)
+		x, err := NewCodeIndex(m, points)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.Len() != len(points) {
+			t.Fatalf("Len() = %v, want %v", x.Len(), len(points))
+		}
+		model := make(map[int][]uint32)
+		for i, p := range points {
+			model[i] = p
+		}
+		for n := 0; n < 100; n++ {
+			min, max := randomBox(r, m)
+			checkIndex(t, x, model, min, max)
+		}
+		// The whole domain, and a single cell
+		all := make([]uint32, m.Dimensions)
+		for i := range all {
+			all[i] = m.Tables[i].Length - 1
+		}
+		checkIndex(t, x, model, make([]uint32, m.Dimensions), all)
+		checkIndex(t, x, model, points[0], points[0])
+	}
+}
+
+func TestCodeIndexEmpty(t *testing.T) {
+	m := New(2, 64)
+	// Points clustered in one corner leave the rest empty
+	x, _ := NewCodeIndex(m, [][]uint32{{1, 1}, {2, 3}, {2, 3}})
+	if got := x.Query([]uint32{10, 10}, []uint32{63, 63}); len(got) != 0 {
+		t.Errorf("Query of an empty box = %v", got)
+	}
+	if got := x.Query([]uint32{2, 3}, []uint32{2, 3}); len(got) != 2 {
+		t.Errorf("Query of a duplicated point = %v, want both ids", got)
+	}
+	// Invalid boxes find nothing
+	if got := x.Query([]uint32{5, 5}, []uint32{4, 4}); got != nil || x.Count([]uint32{5}, []uint32{6}) != 0 {
+		t.Errorf("Query of an inverted box = %v", got)
+	}
+
+	empty, err := NewCodeIndex(m, nil)
+	if err != nil || empty.Len() != 0 || len(empty.Query([]uint32{0, 0}, []uint32{63, 63})) != 0 {
+		t.Errorf("Index of no points = %v, %v", empty, err)
+	}
+
+	if _, err := NewCodeIndex(New(2, 64, WithGrayCode()), nil); !errors.Is(err, ErrUnsupportedLayout) {
+		t.Errorf("NewCodeIndex of a Gray coded Morton returned %v", err)
+	}
+	if _, err := NewCodeIndex(m, [][]uint32{{64, 0}}); err == nil {
+		t.Error("NewCodeIndex of a point beyond the tables succeeded")
+	}
+}
+
+// Interleaved inserts, deletes and queries against a map of the live points, across many merges.
+func TestCodeIndexMaintenance(t *testing.T) {
+	r := rand.New(rand.NewSource(134))
+	m := New(2, 64)
+	initial := randomPoints(r, m, 500)
+	x, err := NewCodeIndex(m, initial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := make(map[int][]uint32)
+	for i, p := range initial {
+		model[i] = p
+	}
+
+	next := len(initial)
+	for step := 0; step < 5000; step++ {
+		switch op := r.Intn(10); {
+		case op < 4:
+			p := randomPoints(r, m, 1)[0]
+			if err := x.Insert(p, next); err != nil {
+				t.Fatal(err)
+			}
+			model[next] = p
+			next++
+		case op < 7:
+			// Existing ids, including freshly buffered ones, and some that were never there
+			id := r.Intn(next + 10)
+			_, want := model[id]
+			if got := x.Delete(id); got != want {
+				t.Fatalf("Step %v: Delete(%v) = %v, want %v", step, id, got, want)
+			}
+			delete(model, id)
+		default:
+			min, max := randomBox(r, m)
+			checkIndex(t, x, model, min, max)
+		}
+		if x.Len() != len(model) {
+			t.Fatalf("Step %v: Len() = %v, want %v", step, x.Len(), len(model))
+		}
+	}
+	checkIndex(t, x, model, []uint32{0, 0}, []uint32{63, 63})
+}
+
+func TestCodeIndexInsertRejects(t *testing.T) {
+	m := New(2, 64)
+	x, _ := NewCodeIndex(m, [][]uint32{{1, 1}})
+	if err := x.Insert([]uint32{2, 2}, 0); err == nil {
+		t.Error("Insert of an indexed id succeeded")
+	}
+	if err := x.Insert([]uint32{64, 2}, 1); err == nil {
+		t.Error("Insert beyond the tables succeeded")
+	}
+	if x.Len() != 1 {
+		t.Errorf("Len() after rejected inserts = %v, want 1", x.Len())
+	}
+	// A deleted id may be inserted again
+	x.Delete(0)
+	if err := x.Insert([]uint32{3, 3}, 0); err != nil || len(x.Query([]uint32{3, 3}, []uint32{3, 3})) != 1 || len(x.Query([]uint32{1, 1}, []uint32{1, 1})) != 0 {
+		t.Errorf("Reinserting a deleted id: %v", err)
+	}
+}
+
+func TestEstimateCount(t *testing.T) {
+	m := New(2, 256)
+	r := rand.New(rand.NewSource(135))
+	datasets := map[string][]uint64{
+		"uniform": sortedCodes(r, m, 20000),
+	}
+	clustered := make([]uint64, 20000)
+	for i := range clustered {
+		x := math.Min(255, math.Max(0, 80+r.NormFloat64()*25))
+		y := math.Min(255, math.Max(0, 170+r.NormFloat64()*15))
+		clustered[i] = m.MustEncode([]uint32{uint32(x), uint32(y)})
+	}
+	sort.Slice(clustered, func(i, j int) bool { return clustered[i] < clustered[j] })
+	datasets["clustered"] = clustered
+
+	for name, codes := range datasets {
+		var errSum float64
+		const boxes = 200
+		for n := 0; n < boxes; n++ {
+			min, max := randomBox(r, m)
+			count := 0
+			for _, c := range codes {
+				if m.InBox(c, min, max) {
+					count++
+				}
+			}
+
+			if got, exact := m.EstimateCount(codes, min, max, 0); !exact || got != uint64(count) {
+				t.Fatalf("%v: unlimited EstimateCount(%v, %v) = %v, %v; want %v exactly", name, min, max, got, exact, count)
+			}
+			got, exact := m.EstimateCount(codes, min, max, 64)
+			if exact && got != uint64(count) {
+				t.Fatalf("%v: EstimateCount(%v, %v) = %v, exact, want %v", name, min, max, got, count)
+			}
+			// Relative to the dataset, so that small counts don't dominate
+			errSum += math.Abs(float64(got)-float64(count)) / float64(len(codes))
+		}
+		if mean := errSum / boxes; mean > 0.005 {
+			t.Errorf("%v: mean EstimateCount error with a budget of 64 cells is %v of the points", name, mean)
+		}
+	}
+}
+
+func TestEstimateCountEdges(t *testing.T) {
+	m := New(2, 16)
+	codes := []uint64{0, 5, 5, 200, 255}
+	if got, exact := m.EstimateCount(codes, []uint32{0, 0}, []uint32{15, 15}, 1); !exact || got != 5 {
+		t.Errorf("EstimateCount of the domain = %v, %v; want 5, exact", got, exact)
+	}
+	if got, exact := m.EstimateCount(nil, []uint32{1, 1}, []uint32{3, 3}, 4); !exact || got != 0 {
+		t.Errorf("EstimateCount of no codes = %v, %v; want 0, exact", got, exact)
+	}
+	if got, exact := m.EstimateCount(codes, []uint32{3, 3}, []uint32{1, 1}, 4); exact || got != 0 {
+		t.Errorf("EstimateCount of an inverted box = %v, %v", got, exact)
+	}
+	if _, exact := New(2, 16, WithShardScatter()).EstimateCount(codes, []uint32{0, 0}, []uint32{15, 15}, 0); exact {
+		t.Error("EstimateCount of a scattered Morton is exact")
+	}
+}
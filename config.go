@@ -0,0 +1,193 @@
+package morton
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+/*
+A saved configuration holds what Create needs to reproduce a Morton, not its tables.  It is big-endian: "MRTC", a version byte, the number of
+dimensions, a flags byte and a reserved zero byte, then the table length as 4 bytes and the time unit as 8.  If the transform flag is set,
+the transform follows as the offsets, scales and rotation, each an 8 byte float.  If the mapped flag is set, the table length counts entries
+rather than indices, and each table's indices follow as 4 bytes apiece, so that MapCoords tables load with the same holes.  Custom
+Interleavers cannot be saved.
+*/
+
+const (
+	configVersion    = 1
+	configHeaderSize = 20
+)
+
+const (
+	configGray = 1 << iota
+	configColor
+	configScatter
+	configTransform
+	configMapped
+)
+
+var configMagic = [4]byte{'M', 'R', 'T', 'C'}
+
+var (
+	_ encoding.BinaryMarshaler   = (*Morton)(nil)
+	_ encoding.BinaryUnmarshaler = (*Morton)(nil)
+)
+
+// Encodes the configuration, options and transform included, so that UnmarshalBinary recreates identical codes for identical inputs.
+func (m *Morton) MarshalBinary() ([]byte, error) {
+	if len(m.Tables) == 0 {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if m.interleaver != nil {
+		return nil, errors.New("Configurations with a custom Interleaver cannot be saved")
+	}
+	// MapCoords keeps the entries but leaves holes, so that Length is one past the largest index rather than the count
+	length := uint32(len(m.Tables[0].Encode))
+	var mapped bool
+	for _, t := range m.Tables {
+		if uint32(len(t.Encode)) != length || length == 0 || t.Encode[length-1].Index+1 != t.Length {
+			return nil, errors.New("Configurations with tables of differing lengths cannot be saved")
+		}
+		if t.Length != length {
+			mapped = true
+		}
+	}
+
+	var flags byte
+	if m.gray {
+		flags |= configGray
+	}
+	if m.color {
+		flags |= configColor
+	}
+	if m.scatter {
+		flags |= configScatter
+	}
+	if m.transform != nil {
+		flags |= configTransform
+	}
+	if mapped {
+		flags |= configMapped
+	}
+
+	data := make([]byte, configHeaderSize, configHeaderSize+(2*int(m.Dimensions)+1)*8)
+	copy(data, configMagic[:])
+	data[4], data[5], data[6] = configVersion, m.Dimensions, flags
+	binary.BigEndian.PutUint32(data[8:], length)
+	binary.BigEndian.PutUint64(data[12:], uint64(m.timeUnit))
+	if t := m.transform; t != nil {
+		for _, f := range append(append(append([]float64(nil), t.Offset...), t.Scale...), t.Rotation) {
+			data = binary.BigEndian.AppendUint64(data, math.Float64bits(f))
+		}
+	}
+	if mapped {
+		for _, t := range m.Tables {
+			for _, b := range t.Encode {
+				data = binary.BigEndian.AppendUint32(data, b.Index)
+			}
+		}
+	}
+	return data, nil
+}
+
+// Replaces m with the configuration encoded by MarshalBinary, creating its tables.
+func (m *Morton) UnmarshalBinary(data []byte) error {
+	if len(data) < configHeaderSize || [4]byte(data[:4]) != configMagic {
+		return errors.New("Not a saved Morton configuration")
+	}
+	if data[4] != configVersion {
+		return errors.New(fmt.Sprint("Unsupported configuration version ", data[4]))
+	}
+	d, flags := data[5], data[6]
+	length := binary.BigEndian.Uint32(data[8:])
+
+	opts := []Option{WithTimeUnit(time.Duration(binary.BigEndian.Uint64(data[12:])))}
+	if flags&configGray != 0 {
+		opts = append(opts, WithGrayCode())
+	}
+	if flags&configColor != 0 {
+		opts = append(opts, WithColor(true))
+	}
+	if flags&configScatter != 0 {
+		opts = append(opts, WithShardScatter())
+	}
+
+	rest := data[configHeaderSize:]
+	if flags&configTransform != 0 {
+		if len(rest) < (2*int(d)+1)*8 {
+			return errors.New("Saved transform is truncated or malformed")
+		}
+		f := make([]float64, 2*int(d)+1)
+		for i := range f {
+			f[i] = math.Float64frombits(binary.BigEndian.Uint64(rest[8*i:]))
+		}
+		opts = append(opts, WithTransform(f[:d], f[d:2*d]), WithRotation(f[2*d]))
+		rest = rest[len(f)*8:]
+	}
+	var indices [][]uint32
+	if flags&configMapped != 0 {
+		if uint64(len(rest)) < uint64(d)*uint64(length)*4 {
+			return errors.New("Saved table indices are truncated")
+		}
+		indices = make([][]uint32, d)
+		for k := range indices {
+			indices[k] = make([]uint32, length)
+			for i := range indices[k] {
+				indices[k][i] = binary.BigEndian.Uint32(rest)
+				rest = rest[4:]
+			}
+		}
+	}
+	if len(rest) != 0 {
+		return errors.New("Saved configuration has trailing bytes")
+	}
+
+	var c Morton
+	if err := c.Create(d, length, opts...); err != nil {
+		return err
+	}
+	if indices != nil {
+		// MapCoords only asks for one dimension at a time, and checks that the indices are increasing
+		mc, err := c.MapCoords(func(in []uint32) []uint32 {
+			out := make([]uint32, len(in))
+			for k, v := range in {
+				out[k] = indices[k][v]
+			}
+			return out
+		})
+		if err != nil {
+			return err
+		}
+		c = *mc
+	}
+	*m = c
+	return nil
+}
+
+// Writes the configuration, see MarshalBinary.
+func (m *Morton) Save(w io.Writer) error {
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Reads a configuration written by Save, and creates its Morton.
+func Load(r io.Reader) (*Morton, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Morton)
+	if err := m.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
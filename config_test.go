@@ -0,0 +1,140 @@
+package morton
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 10}, Axis{Min: 0, Max: 1, Bits: 10})
+	for name, m := range map[string]*Morton{
+		"default":   New(2, 1<<10),
+		"gray":      New(2, 1<<10, WithGrayCode(), WithColor(true)),
+		"scatter":   New(2, 1<<10, WithShardScatter()),
+		"transform": New(2, 1<<10, WithTransform([]float64{500000, 4000000}, []float64{1e-5, 2e-5}), WithRotation(math.Pi/6), WithTimeUnit(time.Hour)),
+	} {
+		var buf bytes.Buffer
+		if err := m.Save(&buf); err != nil {
+			t.Fatalf("%v: Save: %v", name, err)
+		}
+		loaded, err := Load(&buf)
+		if err != nil {
+			t.Fatalf("%v: Load: %v", name, err)
+		}
+		if loaded.HashCode() != m.HashCode() {
+			t.Errorf("%v: the loaded configuration hashes differently", name)
+		}
+
+		// Identical inputs give identical codes
+		r := rand.New(rand.NewSource(146))
+		for n := 0; n < 200; n++ {
+			v := []uint32{uint32(r.Intn(1000)), uint32(r.Intn(1000))}
+			if a, b := m.MustEncode(v), loaded.MustEncode(v); a != b {
+				t.Fatalf("%v: Encode(%v) = %v, loaded %v", name, v, a, b)
+			}
+			world := []float64{r.Float64(), r.Float64()}
+			if tr := m.Transform(); tr != nil {
+				world = tr.inverse(world)
+			}
+			a, errA := m.EncodeFloat(world, q)
+			b, errB := loaded.EncodeFloat(world, q)
+			if a != b || errA != nil || errB != nil {
+				t.Fatalf("%v: EncodeFloat(%v) = %v, %v; loaded %v, %v", name, world, a, errA, b, errB)
+			}
+		}
+		at := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+		a, _ := m.EncodeTime(at, 1, []uint32{7})
+		b, _ := loaded.EncodeTime(at, 1, []uint32{7})
+		if a != b {
+			t.Errorf("%v: EncodeTime = %v, loaded %v", name, a, b)
+		}
+	}
+}
+
+func TestSaveLoadMapped(t *testing.T) {
+	for name, m := range map[string]*Morton{
+		"default":   New(2, 16),
+		"gray":      New(2, 16, WithGrayCode()),
+		"transform": New(2, 16, WithTransform(nil, []float64{2, 2})),
+	} {
+		mapped, err := m.MapCoords(func(in []uint32) []uint32 { return []uint32{2 * in[0], 2 * in[1]} })
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := mapped.Save(&buf); err != nil {
+			t.Fatalf("%v: Save: %v", name, err)
+		}
+		loaded, err := Load(&buf)
+		if err != nil {
+			t.Fatalf("%v: Load: %v", name, err)
+		}
+		if loaded.HashCode() != mapped.HashCode() {
+			t.Errorf("%v: the loaded configuration hashes differently", name)
+		}
+		for x := uint32(0); x < 16; x++ {
+			for y := uint32(0); y < 16; y++ {
+				v := []uint32{2 * x, 2 * y}
+				if a, b := mapped.MustEncode(v), loaded.MustEncode(v); a != b {
+					t.Fatalf("%v: Encode(%v) = %v, loaded %v", name, v, a, b)
+				}
+			}
+		}
+		// The holes load as holes
+		if _, err := loaded.Encode([]uint32{3, 4}); err == nil {
+			t.Errorf("%v: Encode of a hole in the loaded configuration succeeded", name)
+		}
+	}
+}
+
+func TestSaveRejects(t *testing.T) {
+	if _, err := new(Morton).MarshalBinary(); err == nil {
+		t.Error("MarshalBinary without tables succeeded")
+	}
+	if _, err := New(2, 16, WithInterleaver(reversedInterleaver{2})).MarshalBinary(); err == nil {
+		t.Error("MarshalBinary with a custom Interleaver succeeded")
+	}
+	m := New(2, 16)
+	m.Tables[1].Length = 8
+	if _, err := m.MarshalBinary(); err == nil {
+		t.Error("MarshalBinary of differing tables succeeded")
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	data, _ := New(2, 16, WithTransform(nil, []float64{2, 2})).MarshalBinary()
+	version := append([]byte(nil), data...)
+	version[4] = configVersion + 1
+	for name, bad := range map[string][]byte{
+		"empty":          nil,
+		"header only":    data[:configHeaderSize-1],
+		"wrong magic":    append([]byte("MRTN"), data[4:]...),
+		"newer version":  version,
+		"truncated":      data[:len(data)-1],
+		"trailing bytes": append(append([]byte(nil), data...), 0),
+	} {
+		if _, err := Load(bytes.NewReader(bad)); err == nil {
+			t.Errorf("Load of %v data succeeded", name)
+		}
+	}
+	m, _ := New(2, 16).MapCoords(func(in []uint32) []uint32 { return []uint32{2 * in[0], in[1] + 1} })
+	mapped, _ := m.MarshalBinary()
+	unordered := append([]byte(nil), mapped...)
+	unordered[configHeaderSize+7] = 0
+	for name, bad := range map[string][]byte{
+		"truncated indices":   mapped[:len(mapped)-1],
+		"unordered indices":   unordered,
+		"trailing index byte": append(append([]byte(nil), mapped...), 0),
+	} {
+		if _, err := Load(bytes.NewReader(bad)); err == nil {
+			t.Errorf("Load of %v succeeded", name)
+		}
+	}
+	plain, _ := New(2, 16).MarshalBinary()
+	if _, err := Load(bytes.NewReader(append(plain, 1))); err == nil {
+		t.Error("Load of a plain configuration with trailing bytes succeeded")
+	}
+}
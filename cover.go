@@ -0,0 +1,75 @@
+package morton
+
+// Aligned cell of the quadtree, or its higher dimensional analogue; see AtLevel.
+type Cell struct {
+	Code  uint64
+	Level uint8
+}
+
+// Covers the inclusive box [min, max] with disjoint aligned cells in ascending code order, using the largest cells that fit within it and refining at its edges.  With maxCells > 0, the cover is instead taken at the deepest level whose cell count fits: edge cells are kept whole there and may extend beyond the box.
+func (m *Morton) CellCover(min, max []uint32, maxCells int) ([]Cell, error) {
+	if err := m.checkBox(min, max); err != nil {
+		return nil, err
+	}
+	if err := m.checkLayout(); err != nil {
+		return nil, err
+	}
+
+	test := boxTest(min, max)
+	cover := func(level uint8) ([]Cell, bool) {
+		var cells []Cell
+		ok := m.walk(level, test, func(code uint64, level uint8, c Containment) bool {
+			if maxCells > 0 && len(cells) == maxCells {
+				return false
+			}
+			cells = append(cells, Cell{code, level})
+			return true
+		})
+		return cells, ok
+	}
+
+	if maxCells <= 0 {
+		cells, _ := cover(m.Bits())
+		return cells, nil
+	}
+
+	// Deepening a level only splits cells, so counts grow with it
+	cells, _ := cover(0)
+	for level := uint8(1); level <= m.Bits(); level++ {
+		deeper, ok := cover(level)
+		if !ok {
+			break
+		}
+		cells = deeper
+	}
+	return cells, nil
+}
+
+// Calls yield, in ascending code order, for each cell at level that test finds inside, or partial with keepPartial, until yield returns false.  Subtrees inside are enumerated without testing their cells.
+func (m *Morton) cellsAt(level uint8, test CellTest, keepPartial bool, yield func(Cell) bool) {
+	shift, err := m.cellShift(level)
+	if err != nil {
+		return
+	}
+	m.walk(level, test, func(code uint64, l uint8, c Containment) bool {
+		if c == Partial && !keepPartial {
+			return true
+		}
+		s, _ := m.cellShift(l)
+		for n, last := uint64(0), lowMask(s-shift); ; n++ {
+			if !yield(Cell{code + n<<shift, level}) {
+				return false
+			}
+			if n == last {
+				return true
+			}
+		}
+	})
+}
+
+// Iterates, in ascending code order, over the cells at level that test finds inside or partial, so that frustums, spheres and boxes alike can cull.  Subtrees outside are skipped and those inside enumerated without further tests.  Layouts other than the default yield nothing.
+func (m *Morton) VisibleCells(level uint8, test CellTest) func(yield func(Cell) bool) {
+	return func(yield func(Cell) bool) {
+		m.cellsAt(level, test, true, yield)
+	}
+}
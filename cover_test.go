@@ -0,0 +1,227 @@
+package morton
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Random inclusive boxes within m's tables.
+func randomBox(r *rand.Rand, m *Morton) (min, max []uint32) {
+	min, max = make([]uint32, m.Dimensions), make([]uint32, m.Dimensions)
+	for i := range min {
+		a, b := uint32(r.Intn(int(m.Tables[i].Length))), uint32(r.Intn(int(m.Tables[i].Length)))
+		if a > b {
+			a, b = b, a
+		}
+		min[i], max[i] = a, b
+	}
+	return
+}
+
+func inBox(v, min, max []uint32) bool {
+	for i := range v {
+		if v[i] < min[i] || v[i] > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Number of cells containing each code.
+func cellCounts(t *testing.T, m *Morton, cells []Cell, codes []uint64) []int {
+	counts := make([]int, len(codes))
+	for _, c := range cells {
+		for n, code := range codes {
+			if at, _ := m.AtLevel(code, c.Level); at == c.Code {
+				counts[n]++
+			}
+		}
+	}
+	return counts
+}
+
+func TestCellCover(t *testing.T) {
+	r := rand.New(rand.NewSource(126))
+	for _, m := range []*Morton{New(2, 32), New(3, 8)} {
+		codes, vectors := allCodes(t, m)
+		for n := 0; n < 50; n++ {
+			min, max := randomBox(r, m)
+			cells, err := m.CellCover(min, max, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, c := range cells {
+				if i > 0 && cells[i-1].Code >= c.Code {
+					t.Fatalf("CellCover(%v, %v) is out of order at %v", min, max, i)
+				}
+				lo, hi, _ := m.CellBounds(c.Code, c.Level)
+				if !inBox(lo, min, max) || !inBox(hi, min, max) {
+					t.Fatalf("CellCover(%v, %v) cell %+v extends beyond the box", min, max, c)
+				}
+				// Maximal: the parent cell does not fit
+				if c.Level > 0 {
+					lo, hi, _ := m.CellBounds(c.Code, c.Level-1)
+					if inBox(lo, min, max) && inBox(hi, min, max) {
+						t.Fatalf("CellCover(%v, %v) cell %+v could be merged into its parent", min, max, c)
+					}
+				}
+			}
+			// Disjoint, with the box as their union
+			for i, count := range cellCounts(t, m, cells, codes) {
+				if want := inBox(vectors[i], min, max); want && count != 1 || !want && count != 0 {
+					t.Fatalf("CellCover(%v, %v) covers %v %v times", min, max, vectors[i], count)
+				}
+			}
+		}
+	}
+}
+
+func TestCellCoverLimited(t *testing.T) {
+	m := New(2, 32)
+	codes, vectors := allCodes(t, m)
+	r := rand.New(rand.NewSource(1262))
+	for n := 0; n < 30; n++ {
+		min, max := randomBox(r, m)
+		exact, _ := m.CellCover(min, max, 0)
+		prev := len(exact)
+		for _, limit := range []int{len(exact), 64, 16, 8, 4, 1} {
+			cells, err := m.CellCover(min, max, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cells) > limit || len(cells) > prev {
+				t.Fatalf("CellCover(%v, %v, %v) has %v cells, after %v", min, max, limit, len(cells), prev)
+			}
+			prev = len(cells)
+			// Still disjoint, covering the box and perhaps slop beyond it
+			for i, count := range cellCounts(t, m, cells, codes) {
+				if count > 1 || inBox(vectors[i], min, max) && count != 1 {
+					t.Fatalf("CellCover(%v, %v, %v) covers %v %v times", min, max, limit, vectors[i], count)
+				}
+			}
+		}
+	}
+}
+
+func TestCellCoverRejects(t *testing.T) {
+	m := New(2, 32)
+	if _, err := m.CellCover([]uint32{5, 5}, []uint32{4, 6}, 0); err == nil {
+		t.Error("CellCover of an inverted box succeeded")
+	}
+	if _, err := m.CellCover([]uint32{0}, []uint32{1}, 0); err == nil {
+		t.Error("CellCover of a 1 dimension box succeeded")
+	}
+	if _, err := New(2, 32, WithShardScatter()).CellCover([]uint32{0, 0}, []uint32{1, 1}, 0); err == nil {
+		t.Error("CellCover of a scattered Morton succeeded")
+	}
+}
+
+func visibleCells(m *Morton, level uint8, test CellTest) (cells []Cell) {
+	m.VisibleCells(level, test)(func(c Cell) bool {
+		cells = append(cells, c)
+		return true
+	})
+	return
+}
+
+func TestVisibleCellsBox(t *testing.T) {
+	r := rand.New(rand.NewSource(130))
+	for _, m := range []*Morton{New(2, 32), New(3, 8)} {
+		codes, vectors := allCodes(t, m)
+		for n := 0; n < 30; n++ {
+			min, max := randomBox(r, m)
+			cover, _ := m.CellCover(min, max, 0)
+			for level := uint8(0); level <= m.Bits(); level++ {
+				got := visibleCells(m, level, boxTest(min, max))
+
+				// The cells at level meeting the box, from brute force and from the exact cover
+				fromPoints := make(map[uint64]bool)
+				for i, code := range codes {
+					if inBox(vectors[i], min, max) {
+						at, _ := m.AtLevel(code, level)
+						fromPoints[at] = true
+					}
+				}
+				fromCover := make(map[uint64]bool)
+				for _, c := range cover {
+					if c.Level >= level {
+						at, _ := m.AtLevel(c.Code, level)
+						fromCover[at] = true
+						continue
+					}
+					s, _ := m.cellShift(level)
+					span, _ := m.cellShift(c.Level)
+					for k := uint64(0); k < 1<<(span-s); k++ {
+						fromCover[c.Code+k<<s] = true
+					}
+				}
+
+				if len(got) != len(fromPoints) || len(got) != len(fromCover) {
+					t.Fatalf("%vD: VisibleCells(%v) of %v, %v has %v cells; %v meet the box, and %v the cover", m.Dimensions, level, min, max, len(got), len(fromPoints), len(fromCover))
+				}
+				for i, c := range got {
+					if c.Level != level || !fromPoints[c.Code] || !fromCover[c.Code] || i > 0 && got[i-1].Code >= c.Code {
+						t.Fatalf("%vD: VisibleCells(%v) of %v, %v gave %+v at %v", m.Dimensions, level, min, max, c, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestVisibleCellsAll(t *testing.T) {
+	m := New(3, 16)
+	always := func(min, max []uint32) Containment { return Inside }
+	for level := uint8(0); level <= m.Bits(); level++ {
+		got := visibleCells(m, level, always)
+		if want := 1 << (3 * uint(level)); len(got) != want {
+			t.Fatalf("VisibleCells(%v) of everything has %v cells, want %v", level, len(got), want)
+		}
+		s, _ := m.cellShift(level)
+		for i, c := range got {
+			if c.Code != uint64(i)<<s || c.Level != level {
+				t.Fatalf("VisibleCells(%v) of everything gave %+v at %v", level, c, i)
+			}
+		}
+	}
+
+	// Stopping early, and a sphere test that is partial everywhere near its surface
+	n := 0
+	m.VisibleCells(4, always)(func(Cell) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Errorf("VisibleCells yielded %v cells after being stopped at 10", n)
+	}
+	sphere := func(min, max []uint32) Containment {
+		var near, far float64
+		for i := range min {
+			lo, hi := float64(min[i])-8, float64(max[i])-8
+			if lo > 0 {
+				near += lo * lo
+			} else if hi < 0 {
+				near += hi * hi
+			}
+			far += math.Max(lo*lo, hi*hi)
+		}
+		switch {
+		case near > 25:
+			return Outside
+		case far <= 25:
+			return Inside
+		}
+		return Partial
+	}
+	for _, c := range visibleCells(m, 4, sphere) {
+		v := m.Decode(c.Code)
+		var d float64
+		for _, x := range v {
+			d += (float64(x) - 8) * (float64(x) - 8)
+		}
+		if d > 25 {
+			t.Errorf("Sphere VisibleCells includes %v, outside it", v)
+		}
+	}
+}
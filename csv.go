@@ -0,0 +1,146 @@
+package morton
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Configures EncodeCSV and DecodeCSV.
+type CSVOptions struct {
+	// Field delimiter, ',' when zero.
+	Delimiter rune
+	// Skip the first record of the input, whatever its number of fields.
+	SkipHeader bool
+	// Codes are hexadecimal, without a prefix, rather than decimal.  A 0x prefix is accepted on input.
+	Hex bool
+}
+
+// The reader does not check field counts, so that a header of any width can be skipped; see checkFields.
+func (o CSVOptions) reader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	if o.Delimiter != 0 {
+		cr.Comma = o.Delimiter
+	}
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.TrimLeadingSpace = true
+	return cr
+}
+
+func checkFields(cr *csv.Reader, record []string, fields int) error {
+	if len(record) != fields {
+		line, _ := cr.FieldPos(0)
+		return fmt.Errorf("Line %v has %v fields, not %v: %w", line, len(record), fields, csv.ErrFieldCount)
+	}
+	return nil
+}
+
+func (o CSVOptions) writer(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	if o.Delimiter != 0 {
+		cw.Comma = o.Delimiter
+	}
+	return cw
+}
+
+// Streams records of Dimensions coordinates from r, writing the code of each as a record to w.  Memory use is constant regardless of input size, and errors are annotated with the input line.
+func (m *Morton) EncodeCSV(r io.Reader, w io.Writer, opts CSVOptions) error {
+	cr, cw := opts.reader(r), opts.writer(w)
+	vector := make([]uint32, m.Dimensions)
+	out := make([]string, 1)
+
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first && opts.SkipHeader {
+			continue
+		}
+		if err := checkFields(cr, record, int(m.Dimensions)); err != nil {
+			return err
+		}
+
+		for k, field := range record {
+			v, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				line, _ := cr.FieldPos(k)
+				return fmt.Errorf("Line %v, component %v: %w", line, k, err)
+			}
+			vector[k] = uint32(v)
+		}
+		code, err := m.Encode(vector)
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return fmt.Errorf("Line %v: %w", line, err)
+		}
+
+		if opts.Hex {
+			out[0] = strconv.FormatUint(code, 16)
+		} else {
+			out[0] = strconv.FormatUint(code, 10)
+		}
+		if err := cw.Write(out); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// Streams records of a single code from r, writing the decoded coordinates of each as a record to w.  See EncodeCSV.
+func (m *Morton) DecodeCSV(r io.Reader, w io.Writer, opts CSVOptions) error {
+	if m.Dimensions == 0 {
+		return errors.New("Decoding requires at least one dimension")
+	}
+
+	cr, cw := opts.reader(r), opts.writer(w)
+	vector := make([]uint32, m.Dimensions)
+	out := make([]string, m.Dimensions)
+
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first && opts.SkipHeader {
+			continue
+		}
+		if err := checkFields(cr, record, 1); err != nil {
+			return err
+		}
+
+		var code uint64
+		if opts.Hex {
+			code, err = strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(record[0], "0x"), "0X"), 16, 64)
+		} else {
+			code, err = strconv.ParseUint(record[0], 10, 64)
+		}
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return fmt.Errorf("Line %v: %w", line, err)
+		}
+
+		m.decode(code, vector)
+		for k, v := range vector {
+			out[k] = strconv.FormatUint(uint64(v), 10)
+		}
+		if err := cw.Write(out); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,91 @@
+package morton
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	m := New(3, 64)
+	input := "x,y,z\n1,2,3\n63, 0, 7\n0,0,0\n"
+	for _, opts := range []CSVOptions{{SkipHeader: true}, {SkipHeader: true, Hex: true}} {
+		var codes bytes.Buffer
+		if err := m.EncodeCSV(strings.NewReader(input), &codes, opts); err != nil {
+			t.Fatalf("EncodeCSV(%+v): %v", opts, err)
+		}
+		lines := strings.Split(strings.TrimSpace(codes.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("EncodeCSV(%+v) wrote %q", opts, codes.String())
+		}
+		// 1, 2 and 3 encode to 53
+		if opts.Hex && lines[0] != "35" || !opts.Hex && lines[0] != "53" {
+			t.Errorf("EncodeCSV(%+v) wrote %q first, want the code 53", opts, lines[0])
+		}
+
+		var out bytes.Buffer
+		decodeOpts := opts
+		decodeOpts.SkipHeader = false
+		if err := m.DecodeCSV(&codes, &out, decodeOpts); err != nil {
+			t.Fatalf("DecodeCSV(%+v): %v", decodeOpts, err)
+		}
+		if want := "1,2,3\n63,0,7\n0,0,0\n"; out.String() != want {
+			t.Errorf("DecodeCSV(%+v) wrote %q, want %q", decodeOpts, out.String(), want)
+		}
+	}
+}
+
+func TestCSVHeaderWidth(t *testing.T) {
+	m := New(2, 16)
+	// Headers need not have as many fields as the records
+	for _, header := range []string{"x", "x,y,label", "point"} {
+		var out bytes.Buffer
+		if err := m.EncodeCSV(strings.NewReader(header+"\n1,2\n"), &out, CSVOptions{SkipHeader: true}); err != nil {
+			t.Errorf("EncodeCSV with header %q: %v", header, err)
+		}
+	}
+	var out bytes.Buffer
+	if err := m.DecodeCSV(strings.NewReader("code,label\n9\n"), &out, CSVOptions{SkipHeader: true}); err != nil || out.String() != "1,2\n" {
+		t.Errorf("DecodeCSV with a wide header = %q, %v", out.String(), err)
+	}
+}
+
+func TestCSVDelimiter(t *testing.T) {
+	m := New(2, 16)
+	var out bytes.Buffer
+	if err := m.EncodeCSV(strings.NewReader("1;2\n"), &out, CSVOptions{Delimiter: ';'}); err != nil || out.String() != "9\n" {
+		t.Errorf("EncodeCSV with ';' = %q, %v", out.String(), err)
+	}
+}
+
+func TestCSVMalformed(t *testing.T) {
+	m := New(2, 16)
+	for _, tc := range []struct {
+		input, line string
+		fields      bool
+	}{
+		{"1,2\n3\n", "Line 2", true},
+		{"1,2\n3,4,5\n", "Line 2", true},
+		{"1,2\n3,x\n", "Line 2", false},
+		{"1,2\n3,-1\n", "Line 2", false},
+		{"1,2\n4,5\n16,0\n", "Line 3", false},
+	} {
+		err := m.EncodeCSV(strings.NewReader(tc.input), new(bytes.Buffer), CSVOptions{})
+		switch {
+		case err == nil:
+			t.Errorf("EncodeCSV(%q) succeeded", tc.input)
+		case !strings.HasPrefix(err.Error(), tc.line):
+			t.Errorf("EncodeCSV(%q) returned %q, want it to start with %q", tc.input, err, tc.line)
+		case tc.fields != errors.Is(err, csv.ErrFieldCount):
+			t.Errorf("EncodeCSV(%q) returned %v", tc.input, err)
+		}
+	}
+
+	for _, input := range []string{"5\n3,4\n", "5\nzz\n"} {
+		if err := m.DecodeCSV(strings.NewReader(input), new(bytes.Buffer), CSVOptions{}); err == nil || !strings.HasPrefix(err.Error(), "Line 2") {
+			t.Errorf("DecodeCSV(%q) returned %v", input, err)
+		}
+	}
+}
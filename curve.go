@@ -0,0 +1,219 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// An ordering of N-dimensional coordinates onto a single unsigned integer.  Helpers that only need to encode and decode accept a Curve, so alternative orderings are interchangeable with Morton.
+//
+// The methods follow Morton's existing API rather than introducing new names: the dimension count is Dims(), as Morton's Dimensions field already takes that name, and Decode returns no error, as Morton.Decode never has.  Decode of a code no vector encodes to yields some vector regardless; CheckedDecode reports such codes as errors.
+type Curve interface {
+	Dims() uint8
+	Encode(vector []uint32) (uint64, error)
+	Decode(code uint64) []uint32
+}
+
+var (
+	_ Curve = (*Morton)(nil)
+	_ Curve = (*RowMajor)(nil)
+)
+
+// Number of dimensions, satisfying Curve.
+func (m *Morton) Dims() uint8 {
+	return m.Dimensions
+}
+
+// Reports whether m has a single dimension, the degenerate case in which codes equal their coordinates and the tables hold the identity mapping.
+func (m *Morton) Is1D() bool {
+	return m.Dimensions == 1
+}
+
+func (m *Morton) Is2D() bool {
+	return m.Dimensions == 2
+}
+
+func (m *Morton) Is3D() bool {
+	return m.Dimensions == 3
+}
+
+// Row-major (lexicographic) ordering, with dimension 0 the most significant.  Mostly useful as a baseline against which to compare other curves.
+type RowMajor struct {
+	Dimensions uint8
+	Size       uint32
+}
+
+// Convenience function
+func NewRowMajor(dimensions uint8, size uint32) (*RowMajor, error) {
+	if dimensions == 0 || size == 0 {
+		return nil, errors.New("Row-major ordering requires at least one dimension and a non-zero size")
+	}
+	// size^dimensions codes must fit in a uint64
+	total := uint64(1)
+	for i := uint8(0); i < dimensions; i++ {
+		hi, lo := bits.Mul64(total, uint64(size))
+		if hi != 0 {
+			return nil, errors.New("Row-major code space exceeds 64 bits")
+		}
+		total = lo
+	}
+	return &RowMajor{Dimensions: dimensions, Size: size}, nil
+}
+
+func (r *RowMajor) Dims() uint8 {
+	return r.Dimensions
+}
+
+func (r *RowMajor) Encode(vector []uint32) (code uint64, err error) {
+	if len(vector) > int(r.Dimensions) {
+		err = ErrDimensionMismatch
+		return
+	}
+	for k := 0; k < int(r.Dimensions); k++ {
+		var v uint32
+		if k < len(vector) {
+			v = vector[k]
+		}
+		if v >= r.Size {
+			err = errors.New(fmt.Sprint("Input vector component, ", k, " exceeds the row-major size"))
+			return
+		}
+		code = code*uint64(r.Size) + uint64(v)
+	}
+	return
+}
+
+func (r *RowMajor) Decode(code uint64) []uint32 {
+	result := make([]uint32, r.Dimensions)
+	for k := int(r.Dimensions) - 1; k >= 0; k-- {
+		result[k] = uint32(code % uint64(r.Size))
+		code /= uint64(r.Size)
+	}
+	return result
+}
+
+// Decodes code along c, or fails if no vector in c's domain encodes to it, e.g. for codes beyond a table's length.
+func CheckedDecode(c Curve, code uint64) ([]uint32, error) {
+	vector := c.Decode(code)
+	if back, err := c.Encode(vector); err != nil || back != code {
+		return nil, errors.New(fmt.Sprint("Code ", code, " is not the code of any vector"))
+	}
+	return vector, nil
+}
+
+// Sorts points, in place, into the order of curve c.  Each point is encoded once.
+func SortPoints(c Curve, points [][]uint32) error {
+	codes := make([]uint64, len(points))
+	for i, p := range points {
+		code, err := c.Encode(p)
+		if err != nil {
+			return err
+		}
+		codes[i] = code
+	}
+
+	sort.Sort(byCode{codes, points})
+	return nil
+}
+
+// Sorts points by their parallel codes.
+type byCode struct {
+	codes  []uint64
+	points [][]uint32
+}
+
+func (b byCode) Len() int {
+	return len(b.codes)
+}
+
+func (b byCode) Swap(i, j int) {
+	b.codes[i], b.codes[j] = b.codes[j], b.codes[i]
+	b.points[i], b.points[j] = b.points[j], b.points[i]
+}
+
+func (b byCode) Less(i, j int) bool {
+	return b.codes[i] < b.codes[j]
+}
+
+// Groups the indices of points by bin, where a bin is a run of width consecutive codes along curve c (i.e., the key is code / width).
+func BinPoints(c Curve, points [][]uint32, width uint64) (map[uint64][]int, error) {
+	if width == 0 {
+		return nil, errors.New("Bin width must be non-zero")
+	}
+
+	bins := make(map[uint64][]int)
+	for i, p := range points {
+		code, err := c.Encode(p)
+		if err != nil {
+			return nil, err
+		}
+		bins[code/width] = append(bins[code/width], i)
+	}
+	return bins, nil
+}
+
+// Space-filling curve.  An alias of Curve; Morton already has a Dimensions field, so the dimension count is reported by Dims().
+type SFC = Curve
+
+var _ SFC = (*HilbertMorton)(nil)
+
+// Hilbert curve ordering over a Morton configuration, see HilbertEncode.
+type HilbertMorton struct {
+	Morton *Morton
+}
+
+func (h *HilbertMorton) Dims() uint8 {
+	return h.Morton.Dimensions
+}
+
+func (h *HilbertMorton) Encode(vector []uint32) (uint64, error) {
+	return h.Morton.HilbertEncode(vector)
+}
+
+func (h *HilbertMorton) Decode(code uint64) []uint32 {
+	return h.Morton.HilbertDecode(code)
+}
+
+type CurveType uint8
+
+const (
+	ZOrderCurve CurveType = iota
+	HilbertCurve
+	RowMajorCurve
+)
+
+func (c CurveType) String() string {
+	switch c {
+	case ZOrderCurve:
+		return "Z-order"
+	case HilbertCurve:
+		return "Hilbert"
+	case RowMajorCurve:
+		return "row-major"
+	}
+	return fmt.Sprintf("CurveType(%d)", uint8(c))
+}
+
+// Creates a curve of the given type, so that callers can swap orderings without changing their indexing code.
+func NewCurve(ctype CurveType, dimensions uint8, size uint32) (SFC, error) {
+	switch ctype {
+	case ZOrderCurve, HilbertCurve:
+		m := new(Morton)
+		if err := m.Create(dimensions, size); err != nil {
+			return nil, err
+		}
+		if ctype == HilbertCurve {
+			return &HilbertMorton{m}, nil
+		}
+		return m, nil
+	case RowMajorCurve:
+		r, err := NewRowMajor(dimensions, size)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	return nil, errors.New(fmt.Sprint("Unknown curve type, ", ctype))
+}
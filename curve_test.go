@@ -0,0 +1,160 @@
+package morton
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// Curves over 3 dimensions of 8, for tests that run against each.
+func testCurves(t *testing.T) map[string]Curve {
+	t.Helper()
+	curves := make(map[string]Curve)
+	for _, ct := range []CurveType{ZOrderCurve, HilbertCurve, RowMajorCurve} {
+		c, err := NewCurve(ct, 3, 8)
+		if err != nil {
+			t.Fatalf("NewCurve(%v): %v", ct, err)
+		}
+		curves[ct.String()] = c
+	}
+	return curves
+}
+
+func TestCurveRoundTrip(t *testing.T) {
+	for name, c := range testCurves(t) {
+		if c.Dims() != 3 {
+			t.Errorf("%v: Dims() = %v, want 3", name, c.Dims())
+		}
+		seen := make(map[uint64]bool)
+		v := make([]uint32, 3)
+		for v[0] = 0; v[0] < 8; v[0]++ {
+			for v[1] = 0; v[1] < 8; v[1]++ {
+				for v[2] = 0; v[2] < 8; v[2]++ {
+					code, err := c.Encode(v)
+					if err != nil {
+						t.Fatalf("%v: Encode(%v): %v", name, v, err)
+					}
+					if seen[code] {
+						t.Fatalf("%v: code %v is repeated", name, code)
+					}
+					seen[code] = true
+					if got, err := CheckedDecode(c, code); err != nil || !equalUint32s(got, v) {
+						t.Fatalf("%v: CheckedDecode(%v) = %v, %v; want %v", name, code, got, err, v)
+					}
+				}
+			}
+		}
+		if _, err := c.Encode([]uint32{8, 0, 0}); err == nil {
+			t.Errorf("%v: Encode beyond the size succeeded", name)
+		}
+	}
+}
+
+func TestCheckedDecodeRejects(t *testing.T) {
+	m := New(2, 5)
+	// x = 7 is beyond the table, yet decodes
+	if _, err := CheckedDecode(m, 0x15); err == nil {
+		t.Error("CheckedDecode of a code beyond the tables succeeded")
+	}
+	r, _ := NewRowMajor(2, 5)
+	if _, err := CheckedDecode(r, 25); err == nil {
+		t.Error("CheckedDecode of a row-major code beyond the grid succeeded")
+	}
+}
+
+func TestSortPoints(t *testing.T) {
+	r := rand.New(rand.NewSource(105))
+	for name, c := range testCurves(t) {
+		points := make([][]uint32, 100)
+		for i := range points {
+			points[i] = []uint32{uint32(r.Intn(8)), uint32(r.Intn(8)), uint32(r.Intn(8))}
+		}
+		if err := SortPoints(c, points); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		for i := 1; i < len(points); i++ {
+			a, _ := c.Encode(points[i-1])
+			b, _ := c.Encode(points[i])
+			if a > b {
+				t.Fatalf("%v: points %v and %v are out of order", name, i-1, i)
+			}
+		}
+		if err := SortPoints(c, [][]uint32{{1, 2, 3}, {9, 0, 0}}); err == nil {
+			t.Errorf("%v: SortPoints of an out of range point succeeded", name)
+		}
+	}
+
+	// Row-major order is lexicographic
+	rm, _ := NewRowMajor(2, 4)
+	points := [][]uint32{{1, 0}, {0, 3}, {0, 1}}
+	SortPoints(rm, points)
+	if !equalUint32s(points[0], []uint32{0, 1}) || !equalUint32s(points[2], []uint32{1, 0}) {
+		t.Errorf("Row-major SortPoints gave %v", points)
+	}
+}
+
+func TestBinPoints(t *testing.T) {
+	points := [][]uint32{{0, 0, 0}, {1, 1, 1}, {7, 7, 7}, {0, 0, 1}}
+	for name, c := range testCurves(t) {
+		bins, err := BinPoints(c, points, 8)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		n := 0
+		for key, indices := range bins {
+			for _, i := range indices {
+				if code, _ := c.Encode(points[i]); code/8 != key {
+					t.Errorf("%v: point %v is in bin %v, not %v", name, i, key, code/8)
+				}
+				n++
+			}
+		}
+		if n != len(points) {
+			t.Errorf("%v: %v points binned, want %v", name, n, len(points))
+		}
+		if _, err := BinPoints(c, points, 0); err == nil {
+			t.Errorf("%v: BinPoints of width 0 succeeded", name)
+		}
+	}
+}
+
+func TestOneDimension(t *testing.T) {
+	m := New(1, 1<<20)
+	if !m.Is1D() || m.Is2D() || m.Is3D() {
+		t.Errorf("1D Morton reports Is1D %v, Is2D %v, Is3D %v", m.Is1D(), m.Is2D(), m.Is3D())
+	}
+	for i, b := range m.Tables[0].Encode {
+		if b.Index != uint32(i) || b.Value != uint64(i) {
+			t.Fatalf("1D table entry %v is %v, want the identity", i, b)
+		}
+	}
+	for _, x := range []uint32{0, 1, 2, 12345, 1<<20 - 1} {
+		code, err := m.Encode([]uint32{x})
+		if err != nil || code != uint64(x) {
+			t.Fatalf("Encode([%v]) = %v, %v; want %v", x, code, err, x)
+		}
+		if got := m.Decode(code); len(got) != 1 || got[0] != x {
+			t.Fatalf("Decode(Encode([%v])) = %v", x, got)
+		}
+	}
+	if _, err := m.Encode([]uint32{1 << 20}); err == nil {
+		t.Error("1D Encode beyond the table succeeded")
+	}
+}
+
+func TestDimensionPredicates(t *testing.T) {
+	for d := uint8(1); d <= 4; d++ {
+		m := New(d, 4)
+		if m.Is1D() != (d == 1) || m.Is2D() != (d == 2) || m.Is3D() != (d == 3) {
+			t.Errorf("%vD Morton reports Is1D %v, Is2D %v, Is3D %v", d, m.Is1D(), m.Is2D(), m.Is3D())
+		}
+	}
+
+	// Zero dimensions are rejected rather than silently producing empty codes
+	if err := new(Morton).Create(0, 16); !errors.Is(err, ErrInvalidDimensions) {
+		t.Errorf("Create(0, 16) returned %v, want ErrInvalidDimensions", err)
+	}
+	if got := new(Morton).Decode(5); got != nil {
+		t.Errorf("Decode without dimensions = %v, want nil", got)
+	}
+}
@@ -0,0 +1,121 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Masks for each stage of dilation by d, indexed [d][stage].  At stage k, source bit i sits at (i mod 2^k) + (i / 2^k) * 2^k * d, so stage 0 is fully dilated and stage 5 is compact.
+var dilationMasks [65][6]uint64
+
+func init() {
+	for d := uint(2); d <= 64; d++ {
+		n := (64 + d - 1) / d
+		if n > 32 {
+			n = 32
+		}
+		for k := uint(0); k < 6; k++ {
+			g := uint(1) << k
+			for i := uint(0); i < n; i++ {
+				dilationMasks[d][k] |= 1 << (i%g + i/g*g*d)
+			}
+		}
+	}
+}
+
+// Spreads the bits of value so that they are dims bits apart: bit i moves to bit i*dims.  Bits that would land beyond bit 63 are dropped, so at most ceil(64/dims) bits of value are kept.
+func Dilate(value uint32, dims uint8) uint64 {
+	switch dims {
+	case 0:
+		return 0
+	case 1:
+		return uint64(value)
+	}
+
+	masks, d := &dilationMasks[dims], uint(dims)
+	x := uint64(value) & masks[5]
+	for k := 4; k >= 0; k-- {
+		x = (x | x<<((uint(1)<<uint(k))*(d-1))) & masks[k]
+	}
+	return x
+}
+
+// Inverse of Dilate: gathers every dims-th bit of code, starting at bit 0, into a compact value.
+func Undilate(code uint64, dims uint8) uint32 {
+	switch dims {
+	case 0:
+		return 0
+	case 1:
+		return uint32(code)
+	}
+
+	masks, d := &dilationMasks[dims], uint(dims)
+	x := code & masks[0]
+	for k := 0; k < 5; k++ {
+		x = (x | x>>((uint(1)<<uint(k))*(d-1))) & masks[k+1]
+	}
+	return uint32(x)
+}
+
+// Mask selecting the interleaved bit positions (lane) owned by dimension dim, across all 64 bits.
+func MaskForDimension(dims, dim uint8) (mask uint64) {
+	if dims == 0 {
+		return
+	}
+	for i := uint(dim); i < 64; i += uint(dims) {
+		mask |= 1 << i
+	}
+	return
+}
+
+// Adds the lanes of a and b selected by mask, as if each were undilated, added and dilated again.  The carry is propagated across the bits of other dimensions by setting them in a first.  Bits outside mask are zero in the result, so combine with a &^ mask to keep the other dimensions.  Overflow wraps modulo 2^n, where n is the number of bits in mask.
+func AddDilated(a, b, mask uint64) uint64 {
+	return ((a | ^mask) + (b & mask)) & mask
+}
+
+// Subtracts the lanes of b from those of a selected by mask; the borrow wraps like AddDilated's carry.
+func SubDilated(a, b, mask uint64) uint64 {
+	return ((a & mask) - (b & mask)) & mask
+}
+
+// Moves the lanes of a code of dims dimensions into lanes offset and up of a code of total dimensions, reporting whether every bit fit.
+func regroup(code uint64, dims, offset, total uint8) (result uint64, ok bool) {
+	var back uint64
+	for i := uint8(0); i < dims; i++ {
+		v := Undilate(code>>i, dims)
+		lane := Dilate(v, total) << (offset + i)
+		result |= lane
+		back |= Dilate(Undilate(lane>>(offset+i), total), dims) << i
+	}
+	return result, back == code
+}
+
+// Interleaves code a, of aDims dimensions, with code b, of bDims, into the code of their concatenated vectors: its first aDims components are a's, the rest b's, e.g. a spatial code with an attribute code.  An error wrapping ErrCapacityExceeded means a component of either doesn't fit the lanes of aDims + bDims dimensions.  Both codes are taken in the default layout.
+func CombineCodes(a uint64, aDims uint8, b uint64, bDims uint8) (uint64, error) {
+	if aDims == 0 || bDims == 0 || int(aDims)+int(bDims) > 64 {
+		return 0, errors.New(fmt.Sprint("Combined codes require at least one dimension each and at most 64 in total, not ", aDims, " and ", bDims))
+	}
+	total := aDims + bDims
+	ca, okA := regroup(a, aDims, 0, total)
+	cb, okB := regroup(b, bDims, aDims, total)
+	if !okA || !okB {
+		return 0, fmt.Errorf("%w.  Components of codes 0x%x and 0x%x do not fit %v dimensions", ErrCapacityExceeded, a, b, total)
+	}
+	return ca | cb, nil
+}
+
+// Inverse of CombineCodes: a holds the first firstGroup of code's dims components, b the rest.  Unless 0 < firstGroup < dims <= 64, both are zero.
+func SplitCode(code uint64, dims, firstGroup uint8) (a, b uint64) {
+	if firstGroup == 0 || firstGroup >= dims || dims > 64 {
+		return
+	}
+	for i := uint8(0); i < dims; i++ {
+		v := Undilate(code>>i, dims)
+		if i < firstGroup {
+			a |= Dilate(v, firstGroup) << i
+		} else {
+			b |= Dilate(v, dims-firstGroup) << (i - firstGroup)
+		}
+	}
+	return
+}
@@ -0,0 +1,205 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+// Bit by bit reference for Dilate: bit j of value moves to bit j*dims, if that is below 64.
+func referenceDilate(value uint32, dims uint8) (code uint64) {
+	if dims == 0 {
+		return 0
+	}
+	for j := uint(0); j < 32; j++ {
+		if pos := j * uint(dims); pos < 64 {
+			code |= uint64(value>>j&1) << pos
+		}
+	}
+	return
+}
+
+func TestDilateExhaustive(t *testing.T) {
+	for dims := uint8(2); dims <= 8; dims++ {
+		for v := uint32(0); v < 1<<16; v++ {
+			code := Dilate(v, dims)
+			if want := referenceDilate(v, dims); code != want {
+				t.Fatalf("Dilate(%#x, %v) = %#x, want %#x", v, dims, code, want)
+			}
+			if back := Undilate(code, dims); back != v&dilateFit(dims) {
+				t.Fatalf("Undilate(Dilate(%#x, %v)) = %#x", v, dims, back)
+			}
+		}
+	}
+}
+
+// The bits of a value that Dilate keeps within 64 bits.
+func dilateFit(dims uint8) (fit uint32) {
+	for j := uint(0); j < 32 && dims > 0 && j*uint(dims) < 64; j++ {
+		fit |= 1 << j
+	}
+	return
+}
+
+func TestDilateRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(108))
+	for dims := uint8(0); dims <= 64; dims++ {
+		for n := 0; n < 300; n++ {
+			v := r.Uint32()
+			code := Dilate(v, dims)
+			if want := referenceDilate(v, dims); code != want {
+				t.Fatalf("Dilate(%#x, %v) = %#x, want %#x", v, dims, code, want)
+			}
+			// Only the bits that fit survive the round trip
+			fit := dilateFit(dims)
+			if back := Undilate(code, dims); back != v&fit {
+				t.Fatalf("Undilate(Dilate(%#x, %v)) = %#x, want %#x", v, dims, back, v&fit)
+			}
+			// Bits of other lanes are ignored
+			if dims > 1 {
+				if back := Undilate(code|^MaskForDimension(dims, 0), dims); back != v&fit {
+					t.Fatalf("Undilate of %#x with other lanes set = %#x, want %#x", code, back, v&fit)
+				}
+			}
+		}
+	}
+}
+
+func TestDilateMatchesEncode(t *testing.T) {
+	m := New(3, 64)
+	codes, vectors := allCodes(t, m)
+	for n, code := range codes {
+		var want uint64
+		for i, v := range vectors[n] {
+			want |= Dilate(v, 3) << uint(i)
+			if got := Undilate(code>>uint(i), 3); got != v {
+				t.Fatalf("Undilate of component %v of %#x = %v, want %v", i, code, got, v)
+			}
+		}
+		if code != want {
+			t.Fatalf("Encode(%v) = %#x, dilated components %#x", vectors[n], code, want)
+		}
+	}
+}
+
+func TestMaskForDimension(t *testing.T) {
+	if got := MaskForDimension(2, 0); got != 0x5555555555555555 {
+		t.Errorf("MaskForDimension(2, 0) = %#x", got)
+	}
+	if got := MaskForDimension(3, 2); got != 0x4924924924924924 {
+		t.Errorf("MaskForDimension(3, 2) = %#x", got)
+	}
+	for dims := uint8(1); dims <= 8; dims++ {
+		var all uint64
+		for dim := uint8(0); dim < dims; dim++ {
+			mask := MaskForDimension(dims, dim)
+			if all&mask != 0 {
+				t.Errorf("lanes of %v dimensions overlap at %v", dims, dim)
+			}
+			all |= mask
+		}
+		if all != ^uint64(0) {
+			t.Errorf("lanes of %v dimensions cover %#x", dims, all)
+		}
+	}
+}
+
+func TestAddSubDilated(t *testing.T) {
+	r := rand.New(rand.NewSource(109))
+	for dims := uint8(2); dims <= 6; dims++ {
+		for n := 0; n < 1000; n++ {
+			dim := uint8(r.Intn(int(dims)))
+			mask := MaskForDimension(dims, dim)
+			limit := uint32(lowMask(uint8(bits.OnesCount64(mask))))
+			x, y := r.Uint32()&limit, r.Uint32()&limit
+			// Other lanes hold noise, which must neither carry in nor survive
+			a := Dilate(x, dims)<<dim | r.Uint64()&^mask
+			b := Dilate(y, dims)<<dim | r.Uint64()&^mask
+
+			sum := AddDilated(a, b, mask)
+			if sum&^mask != 0 {
+				t.Fatalf("AddDilated left bits %#x outside the lane", sum&^mask)
+			}
+			if got, want := Undilate(sum>>dim, dims), (x+y)&limit; got != want {
+				t.Fatalf("%vD lane %v: %v + %v gave %v, want %v", dims, dim, x, y, got, want)
+			}
+			diff := SubDilated(a, b, mask)
+			if got, want := Undilate(diff>>dim, dims), (x-y)&limit; got != want {
+				t.Fatalf("%vD lane %v: %v - %v gave %v, want %v", dims, dim, x, y, got, want)
+			}
+		}
+	}
+
+	// Overflow wraps modulo the lane
+	mask := MaskForDimension(2, 1)
+	if got := AddDilated(Dilate(0xffffffff, 2)<<1, Dilate(1, 2)<<1, mask); got != 0 {
+		t.Errorf("AddDilated overflowing the lane = %#x, want 0", got)
+	}
+	if got := SubDilated(0, Dilate(1, 2)<<1, mask); got != mask {
+		t.Errorf("SubDilated below zero = %#x, want %#x", got, mask)
+	}
+}
+
+func TestCombineCodes(t *testing.T) {
+	r := rand.New(rand.NewSource(150))
+	for _, tc := range []struct{ aDims, bDims uint8 }{{2, 1}, {2, 2}, {1, 3}, {3, 2}, {1, 1}} {
+		total := tc.aDims + tc.bDims
+		const size = 1 << 10
+		ma, mb, mc := New(tc.aDims, size), New(tc.bDims, size), New(total, size)
+		for n := 0; n < 500; n++ {
+			v := make([]uint32, total)
+			for i := range v {
+				v[i] = uint32(r.Intn(size))
+			}
+			a, b := ma.MustEncode(v[:tc.aDims]), mb.MustEncode(v[tc.aDims:])
+			c, err := CombineCodes(a, tc.aDims, b, tc.bDims)
+			if err != nil {
+				t.Fatalf("CombineCodes of %v: %v", v, err)
+			}
+			// The combined code is that of the concatenated vectors
+			if got := mc.Decode(c); !equalUint32s(got, v) {
+				t.Fatalf("CombineCodes of %v and %v decodes to %v", v[:tc.aDims], v[tc.aDims:], got)
+			}
+			if sa, sb := SplitCode(c, total, tc.aDims); sa != a || sb != b {
+				t.Fatalf("SplitCode(CombineCodes(%v, %v)) = %v, %v", a, b, sa, sb)
+			}
+		}
+	}
+}
+
+func TestSplitCode(t *testing.T) {
+	r := rand.New(rand.NewSource(150))
+	for n := 0; n < 2000; n++ {
+		dims := uint8(2 + r.Intn(7))
+		first := uint8(1 + r.Intn(int(dims)-1))
+		// Any code of dims dimensions splits and combines back
+		c := r.Uint64()
+		a, b := SplitCode(c, dims, first)
+		got, err := CombineCodes(a, first, b, dims-first)
+		if err != nil || got != c {
+			t.Fatalf("CombineCodes(SplitCode(%x, %v, %v)) = %x, %v", c, dims, first, got, err)
+		}
+	}
+	for _, tc := range []struct{ dims, first uint8 }{{2, 0}, {2, 2}, {3, 4}, {65, 1}} {
+		if a, b := SplitCode(math.MaxUint64, tc.dims, tc.first); a != 0 || b != 0 {
+			t.Errorf("SplitCode(%v, %v) = %v, %v; want zeros", tc.dims, tc.first, a, b)
+		}
+	}
+}
+
+func TestCombineCodesRejects(t *testing.T) {
+	for _, dims := range [][2]uint8{{0, 1}, {1, 0}, {60, 5}} {
+		if _, err := CombineCodes(1, dims[0], 1, dims[1]); err == nil {
+			t.Errorf("CombineCodes of %v and %v dimensions succeeded", dims[0], dims[1])
+		}
+	}
+	// 1D codes hold 64 bit components, but 2D lanes only 32
+	if _, err := CombineCodes(1<<32, 1, 0, 1); !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("CombineCodes of a 33 bit component into 2 dimensions returned %v, want ErrCapacityExceeded", err)
+	}
+	if _, err := CombineCodes(0, 1, 1<<42, 2); !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("CombineCodes of a 22 bit component into 3 dimensions returned %v, want ErrCapacityExceeded", err)
+	}
+}
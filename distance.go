@@ -0,0 +1,117 @@
+package morton
+
+import (
+	"container/heap"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Absolute difference without signed overflow.
+func absDiff(x, y uint32) uint32 {
+	if x > y {
+		return x - y
+	}
+	return y - x
+}
+
+// Largest per-dimension difference between the decoded coordinates of a and b.
+func (m *Morton) DistanceChebyshev(a, b uint64) (dist uint32) {
+	va, vb := m.Decode(a), m.Decode(b)
+	for i := range va {
+		if d := absDiff(va[i], vb[i]); d > dist {
+			dist = d
+		}
+	}
+	return
+}
+
+// Sum of the per-dimension differences between the decoded coordinates of a and b.
+func (m *Morton) DistanceManhattan(a, b uint64) (dist uint64) {
+	va, vb := m.Decode(a), m.Decode(b)
+	for i := range va {
+		dist += uint64(absDiff(va[i], vb[i]))
+	}
+	return
+}
+
+// Sum of the squared per-dimension differences between the decoded coordinates of a and b, saturating at math.MaxUint64.
+func (m *Morton) DistanceSquaredEuclidean(a, b uint64) (dist uint64) {
+	va, vb := m.Decode(a), m.Decode(b)
+	for i := range va {
+		d := uint64(absDiff(va[i], vb[i]))
+		var carry uint64
+		if dist, carry = bits.Add64(dist, d*d, 0); carry != 0 {
+			return math.MaxUint64
+		}
+	}
+	return
+}
+
+// Of the codes in sortedCodes on either side of query, the one nearer in code order, and its index, or index -1 if sortedCodes is empty.  It is a binary search without allocation, but only approximate: codes adjacent in order are usually near in space, yet across a high level cell boundary the true nearest neighbour can lie far away in code order, so the result carries no distance bound.
+func (m *Morton) ApproximateNearestNeighbor(query uint64, sortedCodes []uint64) (nearest uint64, index int) {
+	i := sort.Search(len(sortedCodes), func(j int) bool { return sortedCodes[j] >= query })
+	switch {
+	case len(sortedCodes) == 0:
+		return 0, -1
+	case i == len(sortedCodes):
+		i--
+	case i > 0 && query-sortedCodes[i-1] < sortedCodes[i]-query:
+		i--
+	}
+	return sortedCodes[i], i
+}
+
+// Max-heap of candidates by distance, ties by code, holding the k nearest seen.
+type distanceHeap []codeDistance
+
+type codeDistance struct {
+	code, dist uint64
+}
+
+func (h distanceHeap) Len() int { return len(h) }
+func (h distanceHeap) Less(i, j int) bool {
+	if h[i].dist != h[j].dist {
+		return h[i].dist > h[j].dist
+	}
+	return h[i].code > h[j].code
+}
+func (h distanceHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *distanceHeap) Push(x any)   { *h = append(*h, x.(codeDistance)) }
+func (h *distanceHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// The k codes nearest center by Euclidean distance, nearest first, ties by code.  codes needn't be sorted; a heap of the k nearest makes this O(n log k).
+func (m *Morton) TopK(center []uint32, codes []uint64, k int) ([]uint64, error) {
+	if len(center) != int(m.Dimensions) {
+		return nil, ErrDimensionMismatch
+	}
+	c, err := m.Encode(center)
+	if err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		return nil, nil
+	}
+
+	h := make(distanceHeap, 0, k)
+	for _, code := range codes {
+		cd := codeDistance{code, m.DistanceSquaredEuclidean(code, c)}
+		if len(h) < k {
+			heap.Push(&h, cd)
+		} else if cd.dist < h[0].dist || cd.dist == h[0].dist && cd.code < h[0].code {
+			h[0] = cd
+			heap.Fix(&h, 0)
+		}
+	}
+
+	nearest := make([]uint64, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		nearest[i] = heap.Pop(&h).(codeDistance).code
+	}
+	return nearest, nil
+}
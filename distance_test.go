@@ -0,0 +1,226 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestDistances(t *testing.T) {
+	r := rand.New(rand.NewSource(110))
+	for d := uint8(2); d <= 6; d++ {
+		m := New(d, 1<<(60/uint(d)/2))
+		for n := 0; n < 500; n++ {
+			a, b := make([]uint32, d), make([]uint32, d)
+			for i := range a {
+				a[i] = uint32(r.Intn(int(m.Tables[i].Length)))
+				b[i] = uint32(r.Intn(int(m.Tables[i].Length)))
+			}
+			ca, cb := m.MustEncode(a), m.MustEncode(b)
+
+			var cheb, man, sq uint64
+			for i := range a {
+				diff := uint64(math.Abs(float64(a[i]) - float64(b[i])))
+				if diff > cheb {
+					cheb = diff
+				}
+				man += diff
+				sq += diff * diff
+			}
+			if got := m.DistanceChebyshev(ca, cb); uint64(got) != cheb {
+				t.Fatalf("%vD: DistanceChebyshev(%v, %v) = %v, want %v", d, a, b, got, cheb)
+			}
+			if got := m.DistanceManhattan(ca, cb); got != man {
+				t.Fatalf("%vD: DistanceManhattan(%v, %v) = %v, want %v", d, a, b, got, man)
+			}
+			if got := m.DistanceSquaredEuclidean(ca, cb); got != sq {
+				t.Fatalf("%vD: DistanceSquaredEuclidean(%v, %v) = %v, want %v", d, a, b, got, sq)
+			}
+			if m.DistanceManhattan(cb, ca) != man || m.DistanceChebyshev(ca, ca) != 0 {
+				t.Fatalf("%vD: distances between %v and %v are not symmetric, or not 0 to itself", d, a, b)
+			}
+		}
+	}
+}
+
+func TestDistancesWide(t *testing.T) {
+	// Differences near 2^32 neither overflow nor go negative
+	m := New(1, 1<<21)
+	a, b := uint64(0), uint64(math.MaxUint32)
+	if got := m.DistanceChebyshev(a, b); got != math.MaxUint32 {
+		t.Errorf("DistanceChebyshev = %v, want %v", got, uint32(math.MaxUint32))
+	}
+	if got := m.DistanceManhattan(b, a); got != math.MaxUint32 {
+		t.Errorf("DistanceManhattan = %v, want %v", got, uint32(math.MaxUint32))
+	}
+	if got, want := m.DistanceSquaredEuclidean(a, b), uint64(math.MaxUint32)*math.MaxUint32; got != want {
+		t.Errorf("DistanceSquaredEuclidean = %v, want %v", got, want)
+	}
+
+	// Two such differences saturate
+	m2 := New(2, 16)
+	far := Dilate(math.MaxUint32, 2) | Dilate(math.MaxUint32, 2)<<1
+	if got := m2.DistanceSquaredEuclidean(0, far); got != math.MaxUint64 {
+		t.Errorf("DistanceSquaredEuclidean of two maximal differences = %v, want saturation", got)
+	}
+}
+
+func TestApproximateNearestNeighbor(t *testing.T) {
+	m := New(2, 1<<10)
+	r := rand.New(rand.NewSource(127))
+	datasets := map[string]func() []uint32{
+		"uniform": func() []uint32 { return []uint32{uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10))} },
+		"clustered": func() []uint32 {
+			return []uint32{uint32(500 + r.NormFloat64()*20), uint32(200 + r.NormFloat64()*20)}
+		},
+		"line": func() []uint32 { x := uint32(r.Intn(1 << 10)); return []uint32{x, x / 3} },
+	}
+	for name, point := range datasets {
+		codes := make([]uint64, 500)
+		for i := range codes {
+			codes[i] = m.MustEncode(point())
+		}
+		sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+		within := 0
+		const queries = 500
+		for n := 0; n < queries; n++ {
+			query := m.MustEncode(point())
+			got, i := m.ApproximateNearestNeighbor(query, codes)
+			if i < 0 || codes[i] != got {
+				t.Fatalf("%v: ApproximateNearestNeighbor(%v) = %v, %v", name, query, got, i)
+			}
+
+			// Exactly the nearest in code order
+			var codeDist, spaceDist uint64 = math.MaxUint64, math.MaxUint64
+			for _, c := range codes {
+				d := c - query
+				if query > c {
+					d = query - c
+				}
+				if d < codeDist {
+					codeDist = d
+				}
+				if sq := m.DistanceSquaredEuclidean(c, query); sq < spaceDist {
+					spaceDist = sq
+				}
+			}
+			d := got - query
+			if query > got {
+				d = query - got
+			}
+			if d != codeDist {
+				t.Fatalf("%v: ApproximateNearestNeighbor(%v) = %v, %v apart in code order, want %v", name, query, got, d, codeDist)
+			}
+			// Within twice the true distance, i.e. four times squared
+			if m.DistanceSquaredEuclidean(got, query) <= 4*spaceDist {
+				within++
+			}
+		}
+		// No bound holds for every query, but most land near
+		if within < queries/2 {
+			t.Errorf("%v: %v of %v approximations are within twice the nearest distance", name, within, queries)
+		}
+	}
+}
+
+func TestApproximateNearestNeighborEdges(t *testing.T) {
+	m := New(2, 16)
+	if _, i := m.ApproximateNearestNeighbor(5, nil); i != -1 {
+		t.Errorf("ApproximateNearestNeighbor of no codes gave index %v, want -1", i)
+	}
+	codes := []uint64{10, 20, 30}
+	for _, tc := range []struct {
+		query, nearest uint64
+		index          int
+	}{
+		{0, 10, 0}, {10, 10, 0}, {14, 10, 0}, {15, 20, 1}, {26, 30, 2}, {255, 30, 2},
+	} {
+		if got, i := m.ApproximateNearestNeighbor(tc.query, codes); got != tc.nearest || i != tc.index {
+			t.Errorf("ApproximateNearestNeighbor(%v) = %v, %v; want %v, %v", tc.query, got, i, tc.nearest, tc.index)
+		}
+	}
+}
+
+// Codes by squared Euclidean distance from center, then by code, by sorting them all.
+func bruteTopK(m *Morton, center []uint32, codes []uint64, k int) []uint64 {
+	dist := func(code uint64) uint64 {
+		var sum uint64
+		for i, x := range m.Decode(code) {
+			d := uint64(absDiff(x, center[i]))
+			sum += d * d
+		}
+		return sum
+	}
+	sorted := append([]uint64(nil), codes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		di, dj := dist(sorted[i]), dist(sorted[j])
+		return di < dj || di == dj && sorted[i] < sorted[j]
+	})
+	if k < len(sorted) {
+		sorted = sorted[:k]
+	}
+	return sorted
+}
+
+func TestTopK(t *testing.T) {
+	m := New(3, 32)
+	r := rand.New(rand.NewSource(140))
+	for n := 0; n < 300; n++ {
+		// Unsorted, with repeats and ties
+		codes := make([]uint64, r.Intn(60))
+		for i := range codes {
+			codes[i] = m.MustEncode([]uint32{uint32(r.Intn(32)), uint32(r.Intn(32)), uint32(r.Intn(32))})
+		}
+		center := []uint32{uint32(r.Intn(32)), uint32(r.Intn(32)), uint32(r.Intn(32))}
+		k := r.Intn(70)
+		got, err := m.TopK(center, codes, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := bruteTopK(m, center, codes, k); !equalUint64s(got, want) {
+			t.Fatalf("TopK(%v, %v, %v) = %v, want %v", center, codes, k, got, want)
+		}
+	}
+}
+
+func TestTopKLarge(t *testing.T) {
+	m := New(2, 1<<16)
+	r := rand.New(rand.NewSource(140))
+	codes := make([]uint64, 100000)
+	for i := range codes {
+		codes[i] = m.MustEncode([]uint32{uint32(r.Intn(1 << 16)), uint32(r.Intn(1 << 16))})
+	}
+	center := []uint32{30000, 40000}
+	for _, k := range []int{1, 10, 500} {
+		got, err := m.TopK(center, codes, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != k {
+			t.Fatalf("TopK of %v codes for k = %v returned %v", len(codes), k, len(got))
+		}
+		if want := bruteTopK(m, center, codes, k); !equalUint64s(got, want) {
+			t.Fatalf("TopK for k = %v differs from sorting every code", k)
+		}
+	}
+}
+
+func TestTopKEdges(t *testing.T) {
+	m := New(2, 16)
+	codes := []uint64{5, 9, 200}
+	if got, err := m.TopK([]uint32{1, 1}, codes, 0); err != nil || len(got) != 0 {
+		t.Errorf("TopK for k = 0 = %v, %v", got, err)
+	}
+	if got, err := m.TopK([]uint32{1, 1}, nil, 3); err != nil || len(got) != 0 {
+		t.Errorf("TopK of no codes = %v, %v", got, err)
+	}
+	if _, err := m.TopK([]uint32{1}, codes, 2); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("TopK about a 1D center returned %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := m.TopK([]uint32{16, 0}, codes, 2); err == nil {
+		t.Error("TopK about a center beyond the tables succeeded")
+	}
+}
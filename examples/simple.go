@@ -15,7 +15,10 @@ func main() {
 	//Create a new Morton
 	m := new(morton.Morton)
 	//Generate Tables and Magic bits
-	m.Create(4, 512)
+	if err := m.Create(4, 512); err != nil {
+		fmt.Println(err)
+		return
+	}
 
 	//Create arbitrary coordinates
 	c := []uint32{511, 472, 103, 7}
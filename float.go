@@ -0,0 +1,97 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Maps each component of vector from its [min, max] range onto [0, table length - 1], truncating, and encodes the result.
+func (m *Morton) EncodeNormalized(vector []float64, ranges [][2]float64) (uint64, error) {
+	if len(vector) != len(ranges) || len(vector) != int(m.Dimensions) {
+		return 0, ErrDimensionMismatch
+	}
+	if len(m.Tables) < len(vector) {
+		return 0, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+
+	scaled := make([]uint32, len(vector))
+	for k, v := range vector {
+		lo, hi := ranges[k][0], ranges[k][1]
+		if !(lo < hi) {
+			return 0, errors.New(fmt.Sprint("Range of component ", k, " is empty"))
+		}
+		if !(v >= lo && v <= hi) {
+			return 0, fmt.Errorf("%w.  Component %v, %v, is outside [%v, %v]", ErrComponentOverflow, k, v, lo, hi)
+		}
+		capacity := float64(m.Tables[k].Length - 1)
+		scaled[k] = uint32(math.Min((v-lo)/(hi-lo)*capacity, capacity))
+	}
+	return m.Encode(scaled)
+}
+
+// Inverse of EncodeNormalized, to within (max - min) / (table length - 1) per component.
+func (m *Morton) DecodeNormalized(code uint64, ranges [][2]float64) ([]float64, error) {
+	if len(ranges) != int(m.Dimensions) || len(m.Tables) < len(ranges) {
+		return nil, ErrDimensionMismatch
+	}
+
+	v := m.Decode(code)
+	result := make([]float64, len(v))
+	for k, c := range v {
+		lo, hi := ranges[k][0], ranges[k][1]
+		capacity := float64(m.Tables[k].Length - 1)
+		if capacity == 0 {
+			result[k] = lo
+			continue
+		}
+		result[k] = lo + float64(c)/capacity*(hi-lo)
+	}
+	return result, nil
+}
+
+// Validates a unit interval configuration of dims components of bits bits.
+func checkUnit(dims, bits uint8) error {
+	if dims == 0 || bits == 0 || bits > 32 {
+		return errors.New(fmt.Sprint("Unit codes require at least one dimension and between 1 and 32 bits, not ", bits))
+	}
+	if int(dims)*int(bits) > 64 {
+		return fmt.Errorf("%w.  %v dimensions of %v bits need %v bits", ErrCapacityExceeded, dims, bits, int(dims)*int(bits))
+	}
+	return nil
+}
+
+// Quantizes each component of values, within [0, 1], onto bits bits, rounding half to even so that 1.0 maps to the top cell, and interleaves the results in the default layout.  No tables are needed; len(values) * bits must not exceed 64.
+func EncodeUnit(values []float64, bits uint8) (uint64, error) {
+	if len(values) > math.MaxUint8 {
+		return 0, ErrDimensionMismatch
+	}
+	d := uint8(len(values))
+	if err := checkUnit(d, bits); err != nil {
+		return 0, err
+	}
+
+	top := float64(uint64(1)<<bits - 1)
+	var code uint64
+	for k, v := range values {
+		if !(v >= 0 && v <= 1) {
+			return 0, fmt.Errorf("%w.  Component %v, %v, is outside [0, 1]", ErrComponentOverflow, k, v)
+		}
+		code |= Dilate(uint32(math.RoundToEven(v*top)), d) << uint(k)
+	}
+	return code, nil
+}
+
+// Inverse of EncodeUnit, to within 2^-bits per component.  Invalid configurations decode to nil.
+func DecodeUnit(code uint64, dims uint8, bits uint8) []float64 {
+	if checkUnit(dims, bits) != nil {
+		return nil
+	}
+
+	top := float64(uint64(1)<<bits - 1)
+	result := make([]float64, dims)
+	for k := range result {
+		result[k] = float64(Undilate(code>>uint(k), dims)&uint32(uint64(1)<<bits-1)) / top
+	}
+	return result
+}
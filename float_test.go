@@ -0,0 +1,173 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNormalizedRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(123))
+	for _, tc := range []struct {
+		m      *Morton
+		ranges [][2]float64
+	}{
+		{New(3, 1<<16), [][2]float64{{-90, 90}, {-180, 180}, {0, 10000}}},
+		{New(2, 100), [][2]float64{{0, 1}, {-1e-3, 1e-3}}},
+		{New(4, 7), [][2]float64{{-5, -4}, {1e6, 2e6}, {0, 0.5}, {-1, 1}}},
+	} {
+		d := int(tc.m.Dimensions)
+		for n := 0; n < 500; n++ {
+			v := make([]float64, d)
+			for k, rg := range tc.ranges {
+				v[k] = rg[0] + r.Float64()*(rg[1]-rg[0])
+			}
+			// The range ends themselves
+			if n == 0 {
+				for k, rg := range tc.ranges {
+					v[k] = rg[0]
+				}
+			}
+			if n == 1 {
+				for k, rg := range tc.ranges {
+					v[k] = rg[1]
+				}
+			}
+
+			code, err := tc.m.EncodeNormalized(v, tc.ranges)
+			if err != nil {
+				t.Fatalf("%vD: EncodeNormalized(%v): %v", d, v, err)
+			}
+			got, err := tc.m.DecodeNormalized(code, tc.ranges)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, rg := range tc.ranges {
+				step := (rg[1] - rg[0]) / float64(tc.m.Tables[k].Length-1)
+				// Truncation rounds down, by less than one step
+				if diff := v[k] - got[k]; diff < -step*1e-9 || diff > step*(1+1e-9) {
+					t.Fatalf("%vD: DecodeNormalized(EncodeNormalized(%v)) = %v, off by %v in component %v; step %v", d, v, got, diff, k, step)
+				}
+			}
+		}
+	}
+}
+
+func TestNormalizedRejects(t *testing.T) {
+	m := New(2, 16)
+	ranges := [][2]float64{{0, 1}, {0, 1}}
+	for _, tc := range []struct {
+		v      []float64
+		ranges [][2]float64
+		err    error
+	}{
+		{[]float64{0.5}, ranges, ErrDimensionMismatch},
+		{[]float64{0.5, 0.5}, ranges[:1], ErrDimensionMismatch},
+		{[]float64{1.5, 0.5}, ranges, ErrComponentOverflow},
+		{[]float64{0.5, math.NaN()}, ranges, ErrComponentOverflow},
+		{[]float64{0.5, 0.5}, [][2]float64{{0, 1}, {1, 1}}, nil},
+	} {
+		_, err := m.EncodeNormalized(tc.v, tc.ranges)
+		if err == nil || tc.err != nil && !errors.Is(err, tc.err) {
+			t.Errorf("EncodeNormalized(%v, %v) returned %v, want %v", tc.v, tc.ranges, err, tc.err)
+		}
+	}
+	if _, err := m.DecodeNormalized(0, ranges[:1]); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("DecodeNormalized with 1 range returned %v", err)
+	}
+}
+
+func TestUnitRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(145))
+	for _, tc := range []struct{ dims, bits uint8 }{{1, 32}, {2, 1}, {2, 16}, {2, 32}, {3, 21}, {4, 16}, {8, 8}, {64, 1}} {
+		for n := 0; n < 300; n++ {
+			values := make([]float64, tc.dims)
+			for k := range values {
+				values[k] = r.Float64()
+			}
+			if n == 0 {
+				values[0] = 1
+			}
+			code, err := EncodeUnit(values, tc.bits)
+			if err != nil {
+				t.Fatalf("%v dimensions of %v bits: EncodeUnit(%v): %v", tc.dims, tc.bits, values, err)
+			}
+			got := DecodeUnit(code, tc.dims, tc.bits)
+			if len(got) != int(tc.dims) {
+				t.Fatalf("DecodeUnit returned %v components, want %v", len(got), tc.dims)
+			}
+			for k, v := range values {
+				if diff := math.Abs(got[k] - v); diff > math.Ldexp(1, -int(tc.bits)) {
+					t.Fatalf("%v dimensions of %v bits: component %v, %v, decodes to %v", tc.dims, tc.bits, k, v, got[k])
+				}
+				if got[k] < 0 || got[k] > 1 {
+					t.Fatalf("DecodeUnit gave %v, outside [0, 1]", got[k])
+				}
+			}
+			if n == 0 && got[0] != 1 {
+				t.Fatalf("1.0 decodes to %v, not the top cell", got[0])
+			}
+		}
+	}
+}
+
+// Codes are those of the rounded components in the default layout, so they order as the quantized values do.
+func TestUnitOrder(t *testing.T) {
+	m := New(2, 1<<10)
+	r := rand.New(rand.NewSource(145))
+	quantize := func(v float64) uint32 { return uint32(math.RoundToEven(v * 1023)) }
+	for n := 0; n < 2000; n++ {
+		a := []float64{r.Float64(), r.Float64()}
+		b := []float64{r.Float64(), r.Float64()}
+		ca, _ := EncodeUnit(a, 10)
+		cb, _ := EncodeUnit(b, 10)
+		qa, qb := []uint32{quantize(a[0]), quantize(a[1])}, []uint32{quantize(b[0]), quantize(b[1])}
+		if want := m.MustEncode(qa); ca != want {
+			t.Fatalf("EncodeUnit(%v) = %v, want %v, the code of %v", a, ca, want, qa)
+		}
+		if qa[0] <= qb[0] && qa[1] <= qb[1] && ca > cb {
+			t.Fatalf("%v dominates %v, yet codes %v > %v", qb, qa, ca, cb)
+		}
+	}
+
+	// Halves round to even
+	for _, tc := range []struct {
+		v    float64
+		bits uint8
+		want uint64
+	}{
+		{0.5, 1, 0},
+		{0.5, 2, 2},
+		{1.0 / 6, 2, 0},
+		{0.5 / 3, 2, 0},
+		{1, 3, 7},
+		{0, 3, 0},
+	} {
+		if got, err := EncodeUnit([]float64{tc.v}, tc.bits); err != nil || got != tc.want {
+			t.Errorf("EncodeUnit([%v], %v) = %v, %v; want %v", tc.v, tc.bits, got, err, tc.want)
+		}
+	}
+}
+
+func TestUnitRejects(t *testing.T) {
+	for _, v := range []float64{-1e-300, math.Nextafter(1, 2), math.NaN(), math.Inf(1)} {
+		if _, err := EncodeUnit([]float64{0.5, v}, 8); !errors.Is(err, ErrComponentOverflow) {
+			t.Errorf("EncodeUnit of %v returned %v, want ErrComponentOverflow", v, err)
+		}
+	}
+	if _, err := EncodeUnit(make([]float64, 3), 22); !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("EncodeUnit of 3 dimensions of 22 bits returned %v, want ErrCapacityExceeded", err)
+	}
+	for _, tc := range []struct{ dims, bits uint8 }{{0, 8}, {2, 0}, {1, 33}, {3, 22}} {
+		if _, err := EncodeUnit(make([]float64, tc.dims), tc.bits); err == nil {
+			t.Errorf("EncodeUnit of %v dimensions of %v bits succeeded", tc.dims, tc.bits)
+		}
+		if got := DecodeUnit(0, tc.dims, tc.bits); got != nil {
+			t.Errorf("DecodeUnit of %v dimensions of %v bits = %v, want nil", tc.dims, tc.bits, got)
+		}
+	}
+	if _, err := EncodeUnit(make([]float64, 256), 1); err == nil {
+		t.Error("EncodeUnit of 256 dimensions succeeded")
+	}
+}
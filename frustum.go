@@ -0,0 +1,76 @@
+package morton
+
+import (
+	"errors"
+	"math"
+)
+
+// Codes of the voxels within the view cone from origin along forward, of full angle fovRadians and reaching depth along its axis, in ascending order.  The cone's bounding box, clamped to the tables, is decomposed into code ranges first, so only voxels within the box are decoded and tested.
+func (m *Morton) FrustumQuery(origin []uint32, forward []int32, fovRadians float64, depth uint32) ([]uint64, error) {
+	d := int(m.Dimensions)
+	if len(origin) != d || len(forward) != d {
+		return nil, ErrDimensionMismatch
+	}
+	if len(m.Tables) < d {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if !(fovRadians > 0 && fovRadians < math.Pi) {
+		return nil, errors.New("Field of view must be between 0 and Pi radians")
+	}
+
+	axis := make([]float64, d)
+	var norm float64
+	for i, f := range forward {
+		axis[i] = float64(f)
+		norm += axis[i] * axis[i]
+	}
+	if norm == 0 {
+		return nil, errors.New("Forward direction must not be zero")
+	}
+	norm = math.Sqrt(norm)
+	for i := range axis {
+		axis[i] /= norm
+	}
+
+	// Box around the apex and the base, inflated by the base radius
+	cos := math.Cos(fovRadians / 2)
+	radius := float64(depth) * math.Tan(fovRadians/2)
+	min, max := make([]uint32, d), make([]uint32, d)
+	for i := range min {
+		o := float64(origin[i])
+		c := o + axis[i]*float64(depth)
+		last := float64(m.Tables[i].Length) - 1
+		lo := math.Max(0, math.Floor(math.Min(o, c)-radius))
+		hi := math.Min(last, math.Ceil(math.Max(o, c)+radius))
+		if lo > hi {
+			return nil, nil
+		}
+		min[i], max[i] = uint32(lo), uint32(hi)
+	}
+
+	ranges, err := m.RangeDecompose(min, max)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []uint64
+	v := make([]uint32, d)
+	for _, r := range ranges {
+		for code := r.Lo; ; code++ {
+			m.decode(code, v)
+			var t, dist float64
+			for i := range v {
+				x := float64(v[i]) - float64(origin[i])
+				t += x * axis[i]
+				dist += x * x
+			}
+			if t >= 0 && t <= float64(depth) && math.Sqrt(dist)*cos <= t {
+				codes = append(codes, code)
+			}
+			if code == r.Hi {
+				break
+			}
+		}
+	}
+	return codes, nil
+}
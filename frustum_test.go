@@ -0,0 +1,99 @@
+package morton
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Reports whether v is within the cone from origin along forward, checked by the angle to its axis.
+func inCone(v, origin []uint32, forward []int32, fov float64, depth uint32) bool {
+	var dot, vv, ff float64
+	for i := range v {
+		x := float64(v[i]) - float64(origin[i])
+		dot += x * float64(forward[i])
+		vv += x * x
+		ff += float64(forward[i]) * float64(forward[i])
+	}
+	if vv == 0 {
+		return true
+	}
+	along := dot / math.Sqrt(ff)
+	return along >= 0 && along <= float64(depth) && along/math.Sqrt(vv) >= math.Cos(fov/2)-1e-12
+}
+
+func TestFrustumQuery(t *testing.T) {
+	m := New(3, 16)
+	codes, vectors := allCodes(t, m)
+	r := rand.New(rand.NewSource(128))
+	for n := 0; n < 60; n++ {
+		origin := []uint32{uint32(r.Intn(16)), uint32(r.Intn(16)), uint32(r.Intn(16))}
+		forward := []int32{int32(r.Intn(7) - 3), int32(r.Intn(7) - 3), int32(r.Intn(7) - 3)}
+		if forward[0] == 0 && forward[1] == 0 && forward[2] == 0 {
+			forward[2] = 1
+		}
+		fov := 0.2 + r.Float64()*2.5
+		depth := uint32(r.Intn(12))
+
+		got, err := m.FrustumQuery(origin, forward, fov, depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inside := make(map[uint64]bool)
+		for i, c := range got {
+			if i > 0 && got[i-1] >= c {
+				t.Fatalf("FrustumQuery(%v, %v) is out of order at %v", origin, forward, i)
+			}
+			inside[c] = true
+		}
+
+		// Every voxel is tested by brute force; a voxel with an angle within rounding of the edge may go either way
+		for i, v := range vectors {
+			want := inCone(v, origin, forward, fov, depth)
+			if want != inside[codes[i]] && want == inCone(v, origin, forward, fov*(1+1e-9), depth) {
+				t.Fatalf("FrustumQuery(%v, %v, %v, %v) includes %v: %v, want %v", origin, forward, fov, depth, v, inside[codes[i]], want)
+			}
+		}
+		if !inside[m.MustEncode(origin)] {
+			t.Fatalf("FrustumQuery(%v, %v) excludes its apex", origin, forward)
+		}
+	}
+}
+
+func TestFrustumQueryAxis(t *testing.T) {
+	// A narrow cone down x is the line of voxels along it, clipped to the tables
+	m := New(3, 16)
+	got, err := m.FrustumQuery([]uint32{10, 4, 4}, []int32{1, 0, 0}, 0.01, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 6 {
+		t.Fatalf("Narrow cone from x 10 covers %v voxels, want 6", len(got))
+	}
+	for _, c := range got {
+		if v := m.Decode(c); v[1] != 4 || v[2] != 4 || v[0] < 10 {
+			t.Errorf("Narrow cone covers %v", v)
+		}
+	}
+}
+
+func TestFrustumQueryRejects(t *testing.T) {
+	m := New(3, 16)
+	origin := []uint32{1, 1, 1}
+	for _, tc := range []struct {
+		origin  []uint32
+		forward []int32
+		fov     float64
+	}{
+		{origin[:2], []int32{1, 0, 0}, 1},
+		{origin, []int32{1, 0}, 1},
+		{origin, []int32{0, 0, 0}, 1},
+		{origin, []int32{1, 0, 0}, 0},
+		{origin, []int32{1, 0, 0}, math.Pi},
+		{origin, []int32{1, 0, 0}, math.NaN()},
+	} {
+		if _, err := m.FrustumQuery(tc.origin, tc.forward, tc.fov, 4); err == nil {
+			t.Errorf("FrustumQuery(%v, %v, %v) succeeded", tc.origin, tc.forward, tc.fov)
+		}
+	}
+}
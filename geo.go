@@ -0,0 +1,126 @@
+package morton
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Projects the first two dimensions of code onto WGS84 longitude and latitude.  Dimension 0 spans [-180, 180] and dimension 1 spans [-90, 90], linearly across each table's length.
+func (m *Morton) CodeToWGS84(code uint64) (lon, lat float64, err error) {
+	if m.Dimensions < 2 || len(m.Tables) < 2 {
+		err = errors.New("WGS84 projection requires at least two dimensions")
+		return
+	}
+
+	v := m.Decode(code)
+	lon = -180 + 360*unitFraction(v[0], m.Tables[0].Length)
+	lat = -90 + 180*unitFraction(v[1], m.Tables[1].Length)
+	return
+}
+
+// Fraction of the table's span covered by value, in [0, 1].
+func unitFraction(value, length uint32) float64 {
+	if length < 2 {
+		return 0
+	}
+	return float64(value) / float64(length-1)
+}
+
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	BBox        [4]float64     `json:"bbox"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+	// Foreign member carrying the [min, max] range of dimensions beyond the first two.
+	Extra [][2]uint32 `json:"extraDimensions,omitempty"`
+}
+
+// Produces a minified GeoJSON Polygon, with bbox, whose corners are the WGS84 positions (see CodeToWGS84) of the min and max corner codes.  The box is validated as for RangeDecompose.  Dimensions beyond the first two are not projected; their ranges are annotated in the "extraDimensions" foreign member.
+func (m *Morton) GeoJSONBounds(min, max []uint32) (string, error) {
+	if err := m.checkBox(min, max); err != nil {
+		return "", err
+	}
+	d := int(m.Dimensions)
+
+	lo, err := m.Encode(min)
+	if err != nil {
+		return "", err
+	}
+	hi, err := m.Encode(max)
+	if err != nil {
+		return "", err
+	}
+
+	west, south, err := m.CodeToWGS84(lo)
+	if err != nil {
+		return "", err
+	}
+	east, north, err := m.CodeToWGS84(hi)
+	if err != nil {
+		return "", err
+	}
+
+	p := geoJSONPolygon{
+		Type: "Polygon",
+		BBox: [4]float64{west, south, east, north},
+		Coordinates: [][][2]float64{{
+			{west, south}, {east, south}, {east, north}, {west, north}, {west, south},
+		}},
+	}
+	for i := 2; i < d; i++ {
+		p.Extra = append(p.Extra, [2]uint32{min[i], max[i]})
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Mean Earth radius, in meters, for approximate cell sizes.
+const earthRadius = 6371008.8
+
+// Latitude and longitude encoding with sensible defaults: longitude, dimension 0, wraps over [-180, 180), and latitude, dimension 1, clamps to [-90, 90] at the poles.
+type Geo struct {
+	Morton    *Morton
+	Quantizer *Quantizer
+}
+
+// Geo with bits per axis, from 1 to 21, as tables of 2^bits entries, so that codes hold 2*bits bits.
+func NewGeo(bits uint8) (*Geo, error) {
+	if bits == 0 || bits > maxTableBits {
+		return nil, errors.New(fmt.Sprint("Geo requires between 1 and ", maxTableBits, " bits per axis"))
+	}
+	q, err := NewQuantizer(
+		Axis{Min: -180, Max: 180, Bits: bits, Policy: PolicyWrap},
+		Axis{Min: -90, Max: 90, Bits: bits, Policy: PolicyClamp},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Geo{New(2, 1<<bits), q}, nil
+}
+
+func (g *Geo) EncodeLatLon(lat, lon float64) (uint64, error) {
+	return g.Morton.EncodeFloat([]float64{lon, lat}, g.Quantizer)
+}
+
+// Center of the code's cell.
+func (g *Geo) DecodeLatLon(code uint64) (lat, lon float64) {
+	v, _ := g.Morton.DecodeFloat(code, g.Quantizer)
+	return v[1], v[0]
+}
+
+// Extent of a cell, in degrees.
+func (g *Geo) CellSizeDegrees() (latDegrees, lonDegrees float64) {
+	return g.Quantizer.Axes[1].edge(1) - g.Quantizer.Axes[1].edge(0), g.Quantizer.Axes[0].edge(1) - g.Quantizer.Axes[0].edge(0)
+}
+
+// Approximate extent of a cell at latitude lat, in meters, on a spherical Earth.
+func (g *Geo) CellSizeMeters(lat float64) (northSouth, eastWest float64) {
+	latDegrees, lonDegrees := g.CellSizeDegrees()
+	perDegree := earthRadius * math.Pi / 180
+	return latDegrees * perDegree, lonDegrees * perDegree * math.Cos(lat*math.Pi/180)
+}
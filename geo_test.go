@@ -0,0 +1,153 @@
+package morton
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNewGeoBitLimits(t *testing.T) {
+	g, err := NewGeo(maxTableBits)
+	if err != nil {
+		t.Fatalf("NewGeo(%v): %v", maxTableBits, err)
+	}
+	for i, tb := range g.Morton.Tables {
+		if tb.Length != 1<<maxTableBits || len(tb.Encode) != 1<<maxTableBits {
+			t.Errorf("table %v has %v entries, want %v", i, len(tb.Encode), 1<<maxTableBits)
+		}
+	}
+	for _, bits := range []uint8{0, maxTableBits + 1, 24} {
+		if _, err := NewGeo(bits); err == nil {
+			t.Errorf("NewGeo(%v) succeeded, want an error", bits)
+		}
+	}
+}
+
+func TestGeoJSONBounds(t *testing.T) {
+	m := New(3, 5)
+	s, err := m.GeoJSONBounds([]uint32{0, 1, 2}, []uint32{4, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p geoJSONPolygon
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		t.Fatalf("GeoJSONBounds wrote invalid JSON %q: %v", s, err)
+	}
+	want := [4]float64{-180, -45, 180, 45}
+	if p.Type != "Polygon" || p.BBox != want || len(p.Coordinates) != 1 || len(p.Coordinates[0]) != 5 {
+		t.Errorf("GeoJSONBounds = %q", s)
+	}
+	if len(p.Extra) != 1 || p.Extra[0] != [2]uint32{2, 4} {
+		t.Errorf("extraDimensions = %v, want [[2 4]]", p.Extra)
+	}
+}
+
+func TestGeoJSONBoundsRejects(t *testing.T) {
+	m := New(2, 16)
+	if _, err := m.GeoJSONBounds([]uint32{0}, []uint32{1, 1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("GeoJSONBounds of a short corner returned %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := m.GeoJSONBounds([]uint32{5, 0}, []uint32{4, 1}); err == nil {
+		t.Error("GeoJSONBounds with min exceeding max succeeded")
+	}
+	if _, err := m.GeoJSONBounds([]uint32{0, 0}, []uint32{16, 1}); err == nil {
+		t.Error("GeoJSONBounds beyond the tables succeeded")
+	}
+	if _, err := new(Morton).GeoJSONBounds(nil, nil); err == nil {
+		t.Error("GeoJSONBounds without tables succeeded")
+	}
+}
+
+func TestGeoRoundTrip(t *testing.T) {
+	g, err := NewGeo(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latCell, lonCell := g.CellSizeDegrees()
+	for _, city := range []struct {
+		name     string
+		lat, lon float64
+	}{
+		{"London", 51.5074, -0.1278},
+		{"Sydney", -33.8688, 151.2093},
+		{"Quito", -0.1807, -78.4678},
+		{"Reykjavik", 64.1466, -21.9426},
+		{"Suva", -18.1248, 178.4501},
+		{"Anadyr", 64.7337, 177.4968},
+		{"Null Island", 0, 0},
+	} {
+		code, err := g.EncodeLatLon(city.lat, city.lon)
+		if err != nil {
+			t.Fatalf("%v: %v", city.name, err)
+		}
+		lat, lon := g.DecodeLatLon(code)
+		if math.Abs(lat-city.lat) > latCell || math.Abs(lon-city.lon) > lonCell {
+			t.Errorf("%v at %v, %v decodes to %v, %v, beyond a cell", city.name, city.lat, city.lon, lat, lon)
+		}
+	}
+}
+
+func TestGeoAntimeridianAndPoles(t *testing.T) {
+	g, _ := NewGeo(10)
+	for _, tc := range []struct {
+		lat, lon float64
+		cells    []uint32
+	}{
+		// Longitude 180 is -180 again
+		{0, 180, []uint32{0, 512}},
+		{0, -180, []uint32{0, 512}},
+		{0, 540, []uint32{0, 512}},
+		{0, math.Nextafter(180, 0), []uint32{1023, 512}},
+		// Latitude clamps to the poles
+		{90, 0, []uint32{512, 1023}},
+		{90.5, 0, []uint32{512, 1023}},
+		{-90, 0, []uint32{512, 0}},
+		{math.Inf(-1), 0, []uint32{512, 0}},
+	} {
+		code, err := g.EncodeLatLon(tc.lat, tc.lon)
+		if err != nil {
+			t.Errorf("EncodeLatLon(%v, %v): %v", tc.lat, tc.lon, err)
+			continue
+		}
+		if got := g.Morton.Decode(code); !equalUint32s(got, tc.cells) {
+			t.Errorf("EncodeLatLon(%v, %v) has cells %v, want %v", tc.lat, tc.lon, got, tc.cells)
+		}
+	}
+
+	// Either side of the antimeridian lands in adjacent cells, first and last
+	east, _ := g.EncodeLatLon(10, 179.9)
+	west, _ := g.EncodeLatLon(10, -179.9)
+	if e, w := g.Morton.Decode(east), g.Morton.Decode(west); e[0] != 1023 || w[0] != 0 || e[1] != w[1] {
+		t.Errorf("179.9 and -179.9 have cells %v and %v", e, w)
+	}
+	if lat, _ := g.DecodeLatLon(g.Morton.MustEncode([]uint32{0, 1023})); lat > 90 || lat < 90-180.0/1024 {
+		t.Errorf("the northernmost cell decodes to latitude %v", lat)
+	}
+
+	for _, bad := range [][2]float64{{math.NaN(), 0}, {0, math.NaN()}, {0, math.Inf(1)}} {
+		if _, err := g.EncodeLatLon(bad[0], bad[1]); err == nil {
+			t.Errorf("EncodeLatLon(%v, %v) succeeded", bad[0], bad[1])
+		}
+	}
+}
+
+func TestGeoCellSize(t *testing.T) {
+	g, _ := NewGeo(16)
+	lat, lon := g.CellSizeDegrees()
+	if lat != 180.0/65536 || lon != 360.0/65536 {
+		t.Errorf("CellSizeDegrees() = %v, %v; want %v, %v", lat, lon, 180.0/65536, 360.0/65536)
+	}
+	ns, ew := g.CellSizeMeters(0)
+	// A degree is about 111.2 km on the mean sphere
+	if math.Abs(ns-305.4) > 0.1 || math.Abs(ew-610.8) > 0.1 {
+		t.Errorf("CellSizeMeters(0) = %v, %v; want about 305.4, 610.8", ns, ew)
+	}
+	ns60, ew60 := g.CellSizeMeters(60)
+	if ns60 != ns || math.Abs(ew60-ew/2) > 1e-6 {
+		t.Errorf("CellSizeMeters(60) = %v, %v; want %v, %v", ns60, ew60, ns, ew/2)
+	}
+	if _, ew90 := g.CellSizeMeters(90); ew90 > 1e-9 {
+		t.Errorf("CellSizeMeters(90) east-west = %v, want about 0", ew90)
+	}
+}
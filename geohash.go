@@ -0,0 +1,70 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash of the 2D code, of precision characters.  Dimension 0 is taken as longitude and dimension 1 as latitude, each spanning its full range over 2^Bits() cells, as with Geo.  A geohash interleaves from the most significant bit with longitude first, giving longitude the extra bit of an odd total, so the components' top bits are reordered accordingly.
+func (m *Morton) ToGeohash(code uint64, precision int) (string, error) {
+	if m.Dimensions != 2 {
+		return "", ErrNot2D
+	}
+	n := 5 * precision
+	lonBits, latBits := (n+1)/2, n/2
+	if precision <= 0 || lonBits > int(m.Bits()) {
+		return "", errors.New(fmt.Sprint("Geohash precision must be between 1 and ", 2*int(m.Bits())/5))
+	}
+
+	b := int(m.Bits())
+	lon := m.Project(code, 0) >> (b - lonBits)
+	lat := m.Project(code, 1) >> (b - latBits)
+
+	var s strings.Builder
+	var c byte
+	for i := 0; i < n; i++ {
+		var bit uint32
+		if i%2 == 0 {
+			bit = lon >> (lonBits - 1 - i/2) & 1
+		} else {
+			bit = lat >> (latBits - 1 - i/2) & 1
+		}
+		c = c<<1 | byte(bit)
+		if i%5 == 4 {
+			s.WriteByte(geohashAlphabet[c])
+			c = 0
+		}
+	}
+	return s.String(), nil
+}
+
+// Code of the geohash's cell on a 2D grid of 2^bits cells per dimension, longitude in dimension 0 and latitude in dimension 1.  With an odd number of bits in total, latitude has one bit fewer, so the code is that of the lower of the two latitude rows the geohash spans.
+func FromGeohash(s string) (code uint64, bits uint8, err error) {
+	if len(s) == 0 || len(s) > 12 {
+		return 0, 0, errors.New("Geohash must have between 1 and 12 characters")
+	}
+
+	var lon, lat uint32
+	for i := 0; i < len(s); i++ {
+		c := strings.IndexByte(geohashAlphabet, s[i])
+		if c < 0 {
+			return 0, 0, errors.New(fmt.Sprint("Invalid geohash character ", string(s[i]), " at position ", i))
+		}
+		for j := 4; j >= 0; j-- {
+			bit := uint32(c>>j) & 1
+			if (5*i+4-j)%2 == 0 {
+				lon = lon<<1 | bit
+			} else {
+				lat = lat<<1 | bit
+			}
+		}
+	}
+
+	n := 5 * len(s)
+	bits = uint8((n + 1) / 2)
+	lat <<= uint(bits) - uint(n/2)
+	return Dilate(lon, 2) | Dilate(lat, 2)<<1, bits, nil
+}
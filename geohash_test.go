@@ -0,0 +1,133 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Geohash of lat, lon by the reference algorithm, bisecting the ranges from longitude first.
+func referenceGeohash(lat, lon float64, precision int) string {
+	lonRange, latRange := [2]float64{-180, 180}, [2]float64{-90, 90}
+	var s strings.Builder
+	var c byte
+	for i := 0; i < 5*precision; i++ {
+		r, v := &lonRange, lon
+		if i%2 == 1 {
+			r, v = &latRange, lat
+		}
+		mid := (r[0] + r[1]) / 2
+		c <<= 1
+		if v >= mid {
+			c |= 1
+			r[0] = mid
+		} else {
+			r[1] = mid
+		}
+		if i%5 == 4 {
+			s.WriteByte(geohashAlphabet[c])
+			c = 0
+		}
+	}
+	return s.String()
+}
+
+var geohashVectors = []struct {
+	hash     string
+	lat, lon float64
+}{
+	{"ezs42", 42.605, -5.603},
+	{"u4pruydqqvj", 57.64911, 10.40744},
+	{"gcpvj0duq", 51.5074, -0.1278},
+	{"r3gx2f77b", -33.8688, 151.2093},
+}
+
+func TestGeohashVectors(t *testing.T) {
+	g, _ := NewGeo(maxTableBits)
+	for _, v := range geohashVectors {
+		if got := referenceGeohash(v.lat, v.lon, len(v.hash)); got != v.hash {
+			t.Fatalf("reference geohash of %v, %v = %q, want %q", v.lat, v.lon, got, v.hash)
+		}
+
+		// Through Geo's quantization, at as many characters as its codes hold
+		code, err := g.EncodeLatLon(v.lat, v.lon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for p := 1; p <= 2*maxTableBits/5 && p <= len(v.hash); p++ {
+			if got, err := g.Morton.ToGeohash(code, p); err != nil || got != v.hash[:p] {
+				t.Errorf("ToGeohash of %v, %v at precision %v = %q, %v; want %q", v.lat, v.lon, p, got, err, v.hash[:p])
+			}
+		}
+
+		short := v.hash[:5]
+		code, bits, err := FromGeohash(short)
+		if err != nil || bits != 13 {
+			t.Fatalf("FromGeohash(%q) = %v, %v, %v", short, code, bits, err)
+		}
+		coarse, _ := NewGeo(bits)
+		lat, lon := coarse.DecodeLatLon(code)
+		// The code is of the lower latitude row of the geohash's two
+		latCell, lonCell := coarse.CellSizeDegrees()
+		if math.Abs(lat-v.lat) > 2*latCell || math.Abs(lon-v.lon) > lonCell {
+			t.Errorf("FromGeohash(%q) decodes to %v, %v, beyond its cell about %v, %v", short, lat, lon, v.lat, v.lon)
+		}
+		if got, _ := coarse.Morton.ToGeohash(code, 5); got != short {
+			t.Errorf("ToGeohash(FromGeohash(%q)) = %q", short, got)
+		}
+	}
+}
+
+func TestGeohashRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(141))
+	grids := make(map[uint8]*Morton)
+	for n := 0; n < 2000; n++ {
+		b := make([]byte, 1+r.Intn(8))
+		for i := range b {
+			b[i] = geohashAlphabet[r.Intn(32)]
+		}
+		s := string(b)
+		code, bits, err := FromGeohash(s)
+		if err != nil {
+			t.Fatalf("FromGeohash(%q): %v", s, err)
+		}
+		if want := uint8((5*len(s) + 1) / 2); bits != want {
+			t.Fatalf("FromGeohash(%q) has %v bits, want %v", s, bits, want)
+		}
+		if grids[bits] == nil {
+			grids[bits] = New(2, 1<<bits)
+		}
+		if got, err := grids[bits].ToGeohash(code, len(s)); err != nil || got != s {
+			t.Fatalf("ToGeohash(FromGeohash(%q)) = %q, %v", s, got, err)
+		}
+	}
+
+	// Random points agree with the reference algorithm
+	g, _ := NewGeo(20)
+	for n := 0; n < 2000; n++ {
+		lat, lon := r.Float64()*180-90, r.Float64()*360-180
+		code, _ := g.EncodeLatLon(lat, lon)
+		if got, _ := g.Morton.ToGeohash(code, 8); got != referenceGeohash(lat, lon, 8) {
+			t.Fatalf("ToGeohash of %v, %v = %q, want %q", lat, lon, got, referenceGeohash(lat, lon, 8))
+		}
+	}
+}
+
+func TestGeohashRejects(t *testing.T) {
+	m := New(2, 1<<10)
+	for _, p := range []int{0, -1, 5} {
+		if _, err := m.ToGeohash(0, p); err == nil {
+			t.Errorf("ToGeohash at precision %v with 10 bits succeeded", p)
+		}
+	}
+	if _, err := New(3, 8).ToGeohash(0, 1); !errors.Is(err, ErrNot2D) {
+		t.Errorf("ToGeohash in 3 dimensions returned %v, want ErrNot2D", err)
+	}
+	for _, s := range []string{"", "ezs4a", "EZS42", "0123456789bcd"} {
+		if _, _, err := FromGeohash(s); err == nil {
+			t.Errorf("FromGeohash(%q) succeeded", s)
+		}
+	}
+}
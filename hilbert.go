@@ -0,0 +1,134 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Bits per dimension needed to represent the largest table index.
+func (m *Morton) tableBits() (b uint) {
+	for _, t := range m.Tables {
+		if n := t.bits(); n > b {
+			b = n
+		}
+	}
+	return
+}
+
+// Encodes vector along the Hilbert curve of the same dimensions and table sizes, using Skilling's transform.  Components are validated as in Encode, so codes from either curve can be compared for the same configuration.
+func (m *Morton) HilbertEncode(vector []uint32) (uint64, error) {
+	d := len(m.Tables)
+	if d == 0 {
+		return 0, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if len(vector) > d {
+		return 0, errors.New("Input vector slice length exceeds the number of lookup tables.  Please regenerate them via CreateTables()")
+	}
+
+	b := m.tableBits()
+	if b*uint(d) > 64 {
+		return 0, errors.New("Hilbert index exceeds 64 bits for this configuration")
+	}
+
+	x := make([]uint32, d)
+	for k, v := range vector {
+		if v > m.Tables[k].Length-1 {
+			return 0, fmt.Errorf("%w.  Component %v; please regenerate them via CreateTables() and specify the appropriate table length", ErrComponentOverflow, k)
+		}
+		x[k] = v
+	}
+	if b == 0 {
+		return 0, nil
+	}
+
+	axesToTranspose(x, b)
+
+	// Pack the transposed form, most significant bit first, dimension 0 first within a bit
+	var h uint64
+	for j := int(b) - 1; j >= 0; j-- {
+		for i := range x {
+			h = h<<1 | uint64(x[i]>>uint(j)&1)
+		}
+	}
+	return h, nil
+}
+
+// Decodes a Hilbert index produced by HilbertEncode.
+func (m *Morton) HilbertDecode(code uint64) []uint32 {
+	d := len(m.Tables)
+	b := m.tableBits()
+	if d == 0 || b == 0 || b*uint(d) > 64 {
+		return make([]uint32, d)
+	}
+
+	x := make([]uint32, d)
+	for j := int(b) - 1; j >= 0; j-- {
+		for i := range x {
+			s := uint(j)*uint(d) + uint(d-1-i)
+			x[i] |= uint32(code>>s&1) << uint(j)
+		}
+	}
+
+	transposeToAxes(x, b)
+	return x
+}
+
+// From J. Skilling, "Programming the Hilbert curve", AIP Conf. Proc. 707 (2004).
+func axesToTranspose(x []uint32, b uint) {
+	n := len(x)
+	m := uint32(1) << (b - 1)
+
+	// Inverse undo
+	for q := m; q > 1; q >>= 1 {
+		p := q - 1
+		for i := 0; i < n; i++ {
+			if x[i]&q != 0 {
+				x[0] ^= p
+			} else {
+				t := (x[0] ^ x[i]) & p
+				x[0] ^= t
+				x[i] ^= t
+			}
+		}
+	}
+
+	// Gray encode
+	for i := 1; i < n; i++ {
+		x[i] ^= x[i-1]
+	}
+	t := uint32(0)
+	for q := m; q > 1; q >>= 1 {
+		if x[n-1]&q != 0 {
+			t ^= q - 1
+		}
+	}
+	for i := range x {
+		x[i] ^= t
+	}
+}
+
+func transposeToAxes(x []uint32, b uint) {
+	n := len(x)
+	top := uint64(2) << (b - 1)
+
+	// Gray decode
+	t := x[n-1] >> 1
+	for i := n - 1; i > 0; i-- {
+		x[i] ^= x[i-1]
+	}
+	x[0] ^= t
+
+	// Undo excess work
+	for q := uint64(2); q != top; q <<= 1 {
+		p := uint32(q - 1)
+		for i := n - 1; i >= 0; i-- {
+			if x[i]&uint32(q) != 0 {
+				x[0] ^= p
+			} else {
+				t := (x[0] ^ x[i]) & p
+				x[0] ^= t
+				x[i] ^= t
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package morton
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestHilbertRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(104))
+	for d := uint8(2); d <= 6; d++ {
+		m := New(d, 1<<(60/uint(d)/2))
+		for n := 0; n < 500; n++ {
+			v := make([]uint32, d)
+			for i := range v {
+				v[i] = uint32(r.Intn(int(m.Tables[i].Length)))
+			}
+			code, err := m.HilbertEncode(v)
+			if err != nil {
+				t.Fatalf("%vD: HilbertEncode(%v): %v", d, v, err)
+			}
+			if got := m.HilbertDecode(code); !equalUint32s(got, v) {
+				t.Fatalf("%vD: HilbertDecode(HilbertEncode(%v)) = %v", d, v, got)
+			}
+		}
+	}
+}
+
+// Consecutive Hilbert indices are face neighbours, and the curve visits every cell once.
+func TestHilbertAdjacency(t *testing.T) {
+	for _, m := range []*Morton{New(2, 16), New(3, 8), New(4, 4)} {
+		d := int(m.Dimensions)
+		total := uint64(1)
+		for _, tb := range m.Tables {
+			total *= uint64(tb.Length)
+		}
+		seen := make(map[uint64]bool)
+		prev := m.HilbertDecode(0)
+		for code := uint64(0); code < total; code++ {
+			v := m.HilbertDecode(code)
+			if back, err := m.HilbertEncode(v); err != nil || back != code {
+				t.Fatalf("%vD: HilbertEncode(HilbertDecode(%v)) = %v, %v", d, code, back, err)
+			}
+			key, _ := m.Encode(v)
+			if seen[key] {
+				t.Fatalf("%vD: %v is visited twice", d, v)
+			}
+			seen[key] = true
+			if code > 0 {
+				steps := 0
+				for i := range v {
+					steps += int(absDiff(v[i], prev[i]))
+				}
+				if steps != 1 {
+					t.Fatalf("%vD: indices %v and %v, at %v and %v, are not neighbours", d, code-1, code, prev, v)
+				}
+			}
+			prev = v
+		}
+	}
+}
+
+// Median code distance per unit of Chebyshev distance, over random pairs up to 4 cells apart.  The mean is dominated by the few pairs straddling the largest cell boundaries, on either curve.
+func codeStretch(t *testing.T, r *rand.Rand, m *Morton, encode func([]uint32) (uint64, error)) float64 {
+	const pairs = 4001
+	ratios := make([]float64, pairs)
+	for n := 0; n < pairs; n++ {
+		a, b := make([]uint32, 2), make([]uint32, 2)
+		var cheb uint32
+		for i := range a {
+			a[i] = uint32(r.Intn(1020))
+			b[i] = a[i] + uint32(r.Intn(5))
+			if diff := b[i] - a[i]; diff > cheb {
+				cheb = diff
+			}
+		}
+		if cheb == 0 {
+			b[0]++
+			cheb = 1
+		}
+		ca, err := encode(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb, _ := encode(b)
+		diff := ca - cb
+		if cb > ca {
+			diff = cb - ca
+		}
+		ratios[n] = float64(diff) / float64(cheb)
+	}
+	sort.Float64s(ratios)
+	return ratios[pairs/2]
+}
+
+func TestHilbertLocality(t *testing.T) {
+	m := New(2, 1024)
+	hilbert := codeStretch(t, rand.New(rand.NewSource(1)), m, m.HilbertEncode)
+	morton := codeStretch(t, rand.New(rand.NewSource(1)), m, m.Encode)
+	if hilbert > morton {
+		t.Errorf("Hilbert stretch %v exceeds Morton's %v", hilbert, morton)
+	}
+}
+
+func TestHilbertRejects(t *testing.T) {
+	m := New(2, 10)
+	if _, err := m.HilbertEncode([]uint32{10, 0}); err == nil {
+		t.Error("HilbertEncode beyond the tables succeeded")
+	}
+	if _, err := m.HilbertEncode([]uint32{1, 2, 3}); err == nil {
+		t.Error("HilbertEncode of 3 components in 2 dimensions succeeded")
+	}
+	if _, err := new(Morton).HilbertEncode([]uint32{1}); err == nil {
+		t.Error("HilbertEncode without tables succeeded")
+	}
+}
@@ -0,0 +1,59 @@
+package morton
+
+import (
+	"errors"
+	"image"
+)
+
+var ErrNot2D = errors.New("Operation requires a 2 dimension Morton")
+
+// Encodes p, with X as dimension 0 and Y as dimension 1.  Negative coordinates are rejected.
+func (m *Morton) EncodePoint(p image.Point) (uint64, error) {
+	if m.Dimensions != 2 {
+		return 0, ErrNot2D
+	}
+	if p.X < 0 || p.Y < 0 {
+		return 0, errors.New("Point coordinates must not be negative")
+	}
+	if uint64(p.X) > uint64(^uint32(0)) || uint64(p.Y) > uint64(^uint32(0)) {
+		return 0, ErrComponentOverflow
+	}
+	return m.Encode([]uint32{uint32(p.X), uint32(p.Y)})
+}
+
+// Decodes code into a point, with X as dimension 0 and Y as dimension 1.
+func (m *Morton) DecodePoint(code uint64) image.Point {
+	v := m.Decode(code)
+	if len(v) < 2 {
+		return image.Point{}
+	}
+	return image.Point{int(v[0]), int(v[1])}
+}
+
+// Iterates, in ascending Z order, the codes of the pixels of r within the tables' domain.  Quadtree cells wholly inside r are yielded as runs of consecutive codes without further subdivision.  The iterator has the shape of iter.Seq[uint64].  Layouts other than the default yield nothing.
+func (m *Morton) RectCells(r image.Rectangle) func(yield func(uint64) bool) {
+	return func(yield func(uint64) bool) {
+		if m.Dimensions != 2 || len(m.Tables) < 2 {
+			return
+		}
+
+		r = r.Intersect(image.Rect(0, 0, int(m.Tables[0].Length), int(m.Tables[1].Length)))
+		if r.Empty() {
+			return
+		}
+
+		min := []uint32{uint32(r.Min.X), uint32(r.Min.Y)}
+		max := []uint32{uint32(r.Max.X - 1), uint32(r.Max.Y - 1)}
+		m.walk(m.Bits(), boxTest(min, max), func(code uint64, level uint8, c Containment) bool {
+			s, _ := m.cellShift(level)
+			for end := code + lowMask(s); ; code++ {
+				if !yield(code) {
+					return false
+				}
+				if code == end {
+					return true
+				}
+			}
+		})
+	}
+}
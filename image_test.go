@@ -0,0 +1,87 @@
+package morton
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestPointRoundTrip(t *testing.T) {
+	m := New(2, 1<<10)
+	for _, p := range []image.Point{{0, 0}, {1, 0}, {0, 1}, {640, 480}, {1023, 1023}} {
+		code, err := m.EncodePoint(p)
+		if err != nil {
+			t.Fatalf("EncodePoint(%v): %v", p, err)
+		}
+		if want, _ := m.Encode([]uint32{uint32(p.X), uint32(p.Y)}); code != want {
+			t.Errorf("EncodePoint(%v) = %v, want %v", p, code, want)
+		}
+		if got := m.DecodePoint(code); got != p {
+			t.Errorf("DecodePoint(EncodePoint(%v)) = %v", p, got)
+		}
+	}
+
+	for _, p := range []image.Point{{-1, 0}, {0, -5}, {1024, 0}} {
+		if _, err := m.EncodePoint(p); err == nil {
+			t.Errorf("EncodePoint(%v) succeeded", p)
+		}
+	}
+	if _, err := New(3, 8).EncodePoint(image.Point{}); !errors.Is(err, ErrNot2D) {
+		t.Errorf("EncodePoint in 3 dimensions returned %v, want ErrNot2D", err)
+	}
+}
+
+func TestRectCells(t *testing.T) {
+	m := New(2, 16)
+	codes, vectors := allCodes(t, m)
+	for _, r := range []image.Rectangle{
+		image.Rect(0, 0, 16, 16),
+		image.Rect(4, 8, 8, 12),
+		image.Rect(1, 3, 6, 14), // Not aligned to any power of two
+		image.Rect(5, 5, 6, 6),
+		image.Rect(-3, 10, 20, 40), // Clipped to the domain
+		image.Rect(3, 3, 3, 9),     // Empty
+		image.Rect(20, 20, 30, 30), // Outside the domain
+	} {
+		var want []uint64
+		for n, v := range vectors {
+			if (image.Point{int(v[0]), int(v[1])}).In(r) {
+				want = append(want, codes[n])
+			}
+		}
+		var got []uint64
+		m.RectCells(r)(func(code uint64) bool {
+			got = append(got, code)
+			return true
+		})
+		if len(got) != len(want) {
+			t.Fatalf("RectCells(%v) yielded %v codes, want %v", r, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("RectCells(%v) yielded %v at %v, want %v", r, got[i], i, want[i])
+			}
+		}
+	}
+}
+
+func TestRectCellsStops(t *testing.T) {
+	m := New(2, 16)
+	n := 0
+	m.RectCells(image.Rect(0, 0, 16, 16))(func(uint64) bool {
+		n++
+		return n < 5
+	})
+	if n != 5 {
+		t.Errorf("RectCells yielded %v codes after being stopped at 5", n)
+	}
+
+	New(3, 8).RectCells(image.Rect(0, 0, 4, 4))(func(uint64) bool {
+		t.Error("RectCells in 3 dimensions yielded a code")
+		return false
+	})
+	New(2, 16, WithGrayCode()).RectCells(image.Rect(0, 0, 4, 4))(func(uint64) bool {
+		t.Error("RectCells of a Gray coded Morton yielded a code")
+		return false
+	})
+}
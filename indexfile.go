@@ -0,0 +1,144 @@
+package morton
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+Index files are little-endian: "MRTI", a format version byte and three reserved zero bytes, then the Morton configuration (see
+MarshalBinary) and the metadata, as length prefixed blocks, 4 byte lengths throughout.  Metadata is a count of pairs, each a key and a
+value, sorted by key so that identical indexes produce identical files.  Last come the number of codes, as 8 bytes, and the codes as
+packed 8 byte words.
+*/
+
+const (
+	indexFileVersion = 1
+	// Bounds on length prefixed blocks, to fail fast on corrupt headers
+	maxIndexBlock = 1 << 24
+)
+
+var indexFileMagic = [4]byte{'M', 'R', 'T', 'I'}
+
+// Writes codes, with m's configuration and the given metadata, as an index file that ReadIndex can verify and read back sequentially.
+func (m *Morton) WriteIndex(w io.Writer, codes []uint64, meta map[string]string) error {
+	config, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(indexFileMagic[:])
+	bw.Write([]byte{indexFileVersion, 0, 0, 0})
+	writeIndexBlock(bw, config)
+
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	binary.Write(bw, binary.LittleEndian, uint32(len(keys)))
+	for _, k := range keys {
+		writeIndexBlock(bw, []byte(k))
+		writeIndexBlock(bw, []byte(meta[k]))
+	}
+
+	binary.Write(bw, binary.LittleEndian, uint64(len(codes)))
+	var rec [codeRecordSize]byte
+	for _, c := range codes {
+		binary.LittleEndian.PutUint64(rec[:], c)
+		bw.Write(rec[:])
+	}
+	// bufio.Writer retains the first error, which Flush returns
+	return bw.Flush()
+}
+
+func writeIndexBlock(w io.Writer, b []byte) {
+	binary.Write(w, binary.LittleEndian, uint32(len(b)))
+	w.Write(b)
+}
+
+// Reads an index file written by WriteIndex with the same configuration as m, MapCoords tables included.  A file that ends early yields an error wrapping ErrTruncatedRecord, naming the part cut short.
+func (m *Morton) ReadIndex(r io.Reader) (codes []uint64, meta map[string]string, err error) {
+	br := bufio.NewReader(r)
+	read := func(part string, dst []byte) error {
+		switch _, err := io.ReadFull(br, dst); err {
+		case nil:
+			return nil
+		case io.EOF, io.ErrUnexpectedEOF:
+			return fmt.Errorf("%w.  Index ends within its %v", ErrTruncatedRecord, part)
+		default:
+			return err
+		}
+	}
+	var word [codeRecordSize]byte
+	readBlock := func(part string) ([]byte, error) {
+		if err := read(part, word[:4]); err != nil {
+			return nil, err
+		}
+		n := binary.LittleEndian.Uint32(word[:4])
+		if n > maxIndexBlock {
+			return nil, errors.New(fmt.Sprint("Index ", part, " of ", n, " bytes exceeds the limit"))
+		}
+		b := make([]byte, n)
+		return b, read(part, b)
+	}
+
+	if err = read("header", word[:]); err != nil {
+		return
+	}
+	if [4]byte(word[:4]) != indexFileMagic {
+		return nil, nil, errors.New("Not a Morton index file")
+	}
+	if word[4] != indexFileVersion {
+		return nil, nil, errors.New(fmt.Sprint("Unsupported index file version, ", word[4]))
+	}
+
+	config, err := readBlock("configuration")
+	if err != nil {
+		return
+	}
+	own, err := m.MarshalBinary()
+	if err != nil {
+		return
+	}
+	if !bytes.Equal(config, own) {
+		return nil, nil, errors.New("Index was written with a different configuration")
+	}
+
+	if err = read("metadata", word[:4]); err != nil {
+		return
+	}
+	pairs := binary.LittleEndian.Uint32(word[:4])
+	meta = make(map[string]string)
+	for i := uint32(0); i < pairs; i++ {
+		var k, v []byte
+		if k, err = readBlock("metadata"); err != nil {
+			return nil, nil, err
+		}
+		if v, err = readBlock("metadata"); err != nil {
+			return nil, nil, err
+		}
+		meta[string(k)] = string(v)
+	}
+
+	if err = read("code count", word[:]); err != nil {
+		return nil, nil, err
+	}
+	n := binary.LittleEndian.Uint64(word[:])
+	for i := uint64(0); i < n; i++ {
+		if err = read("codes", word[:]); err != nil {
+			if errors.Is(err, ErrTruncatedRecord) {
+				err = fmt.Errorf("%w, after %v of %v", err, i, n)
+			}
+			return nil, nil, err
+		}
+		codes = append(codes, binary.LittleEndian.Uint64(word[:]))
+	}
+	return codes, meta, nil
+}
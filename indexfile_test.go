@@ -0,0 +1,132 @@
+package morton
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// Writer failing every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestIndexFileRoundTrip(t *testing.T) {
+	m := New(3, 1<<10, WithGrayCode())
+	for _, tc := range []struct {
+		codes []uint64
+		meta  map[string]string
+	}{
+		{[]uint64{0, 5, 5, 0x123456789, math.MaxUint64}, map[string]string{"name": "voxels", "crs": "EPSG:4978", "": "empty key"}},
+		{nil, nil},
+		{[]uint64{42}, map[string]string{"note": strings.Repeat("x", 5000)}},
+	} {
+		var buf bytes.Buffer
+		if err := m.WriteIndex(&buf, tc.codes, tc.meta); err != nil {
+			t.Fatal(err)
+		}
+		codes, meta, err := m.ReadIndex(&buf)
+		if err != nil {
+			t.Fatalf("ReadIndex: %v", err)
+		}
+		if !equalUint64s(codes, tc.codes) {
+			t.Errorf("ReadIndex codes = %v, want %v", codes, tc.codes)
+		}
+		if len(meta) != len(tc.meta) {
+			t.Errorf("ReadIndex metadata = %v, want %v", meta, tc.meta)
+		}
+		for k, v := range tc.meta {
+			if meta[k] != v {
+				t.Errorf("ReadIndex metadata %q = %q, want %q", k, meta[k], v)
+			}
+		}
+	}
+
+	// Identical indexes give identical files, whatever the map order
+	var a, b bytes.Buffer
+	meta := map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"}
+	m.WriteIndex(&a, []uint64{1, 2}, meta)
+	m.WriteIndex(&b, []uint64{1, 2}, map[string]string{"d": "4", "c": "3", "b": "2", "a": "1"})
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("identical indexes wrote different files")
+	}
+	if !bytes.HasPrefix(a.Bytes(), []byte("MRTI\x01\x00\x00\x00")) {
+		t.Errorf("index file starts with %q", a.Bytes()[:8])
+	}
+}
+
+func TestIndexFileTruncated(t *testing.T) {
+	m := New(2, 16)
+	var buf bytes.Buffer
+	if err := m.WriteIndex(&buf, []uint64{3, 1, 4, 1, 5}, map[string]string{"k": "value"}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Every proper prefix is truncated, and names its part
+	for n := 0; n < len(data); n++ {
+		_, _, err := m.ReadIndex(bytes.NewReader(data[:n]))
+		if !errors.Is(err, ErrTruncatedRecord) {
+			t.Fatalf("ReadIndex of %v of %v bytes returned %v, want ErrTruncatedRecord", n, len(data), err)
+		}
+		if !strings.Contains(err.Error(), "Index ends within its") {
+			t.Fatalf("ReadIndex of %v bytes returned %q, naming no part", n, err)
+		}
+	}
+	if _, _, err := m.ReadIndex(bytes.NewReader(data[:len(data)-9])); err == nil || !strings.Contains(err.Error(), "after 3 of 5") {
+		t.Errorf("ReadIndex missing two codes returned %v", err)
+	}
+}
+
+func TestIndexFileRejects(t *testing.T) {
+	m := New(2, 16)
+	var buf bytes.Buffer
+	m.WriteIndex(&buf, []uint64{1}, nil)
+	data := buf.Bytes()
+
+	if _, _, err := New(2, 32).ReadIndex(bytes.NewReader(data)); err == nil {
+		t.Error("ReadIndex with a different configuration succeeded")
+	}
+	// Mapped tables share the plain tables' header, but not their codes
+	double := func(in []uint32) []uint32 { return []uint32{2 * in[0], 2 * in[1]} }
+	mapped, _ := m.MapCoords(double)
+	var mbuf bytes.Buffer
+	if err := mapped.WriteIndex(&mbuf, []uint64{1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.ReadIndex(bytes.NewReader(mbuf.Bytes())); err == nil {
+		t.Error("ReadIndex of a MapCoords index into a plain configuration succeeded")
+	}
+	if _, _, err := mapped.ReadIndex(bytes.NewReader(data)); err == nil {
+		t.Error("ReadIndex of a plain index into a MapCoords configuration succeeded")
+	}
+	again, _ := New(2, 16).MapCoords(double)
+	if codes, _, err := again.ReadIndex(bytes.NewReader(mbuf.Bytes())); err != nil || !equalUint64s(codes, []uint64{1}) {
+		t.Errorf("ReadIndex into an identically mapped configuration = %v, %v", codes, err)
+	}
+	magic := append([]byte("MRTN"), data[4:]...)
+	if _, _, err := m.ReadIndex(bytes.NewReader(magic)); err == nil {
+		t.Error("ReadIndex with the wrong magic succeeded")
+	}
+	version := append([]byte(nil), data...)
+	version[4] = indexFileVersion + 1
+	if _, _, err := m.ReadIndex(bytes.NewReader(version)); err == nil {
+		t.Error("ReadIndex of a newer version succeeded")
+	}
+	// A corrupt block length fails before allocating it
+	huge := append([]byte(nil), data...)
+	huge[8], huge[9], huge[10], huge[11] = 0xff, 0xff, 0xff, 0x7f
+	if _, _, err := m.ReadIndex(bytes.NewReader(huge)); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("ReadIndex of a huge configuration block returned %v", err)
+	}
+
+	if err := New(2, 16, WithInterleaver(reversedInterleaver{2})).WriteIndex(new(bytes.Buffer), nil, nil); err == nil {
+		t.Error("WriteIndex with a custom Interleaver succeeded")
+	}
+	if err := m.WriteIndex(failingWriter{}, []uint64{1, 2}, nil); err == nil {
+		t.Error("WriteIndex to a failing writer succeeded")
+	}
+}
@@ -0,0 +1,76 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Spreads the bits of a single dimension's value into its code positions, and compacts them back out.  Compact(Spread(v, dim), dim) must return v, and the spreads of distinct dimensions must not overlap.
+type Interleaver interface {
+	Spread(value uint32, dim uint8) uint64
+	Compact(code uint64, dim uint8) uint32
+}
+
+// Routes table construction and decoding through i, in place of the built-in Z-order pattern.  Tables, batching and the other table driven helpers work unchanged; helpers that manipulate codes directly in the interleaved (dilated) domain return ErrUnsupportedLayout.
+func WithInterleaver(i Interleaver) Option {
+	return func(m *Morton) {
+		m.interleaver = i
+	}
+}
+
+var _ Interleaver = ZOrderInterleaver{}
+
+// The built-in Z-order pattern: bit j of dimension dim's value is placed at j*Dimensions + dim.
+type ZOrderInterleaver struct {
+	Dimensions uint8
+}
+
+func (z ZOrderInterleaver) Spread(value uint32, dim uint8) uint64 {
+	return Dilate(value, z.Dimensions) << dim
+}
+
+func (z ZOrderInterleaver) Compact(code uint64, dim uint8) uint32 {
+	return Undilate(code>>dim, z.Dimensions)
+}
+
+// Checks that i round trips over a sample of values below size, the extremes, every power of two and a spread of values between, and that no two bits of any dimensions' values below size collide.
+func ValidateInterleaver(i Interleaver, dimensions uint8, size uint32) error {
+	if size == 0 {
+		return nil
+	}
+
+	var sample []uint32
+	for v := uint32(1); v != 0 && v < size; v <<= 1 {
+		sample = append(sample, v, v-1)
+	}
+	step := size/64 + 1
+	for v := uint32(0); v < size-step; v += step {
+		sample = append(sample, v)
+	}
+	sample = append(sample, size-1)
+
+	var used uint64
+	for dim := uint8(0); dim < dimensions; dim++ {
+		for _, v := range sample {
+			if c := i.Compact(i.Spread(v, dim), dim); c != v {
+				return errors.New(fmt.Sprint("Interleaver does not round trip value ", v, " in dimension ", dim, ", got ", c))
+			}
+		}
+
+		// Each bit of every value below size on its own, so bits clear in size - 1 are checked too
+		var lane uint64
+		for b := 0; b < bits.Len32(size-1); b++ {
+			s := i.Spread(1<<b, dim)
+			if lane&s != 0 {
+				return errors.New(fmt.Sprint("Interleaver spread of bit ", b, " of dimension ", dim, " overlaps another of its bits"))
+			}
+			if used&s != 0 {
+				return errors.New(fmt.Sprint("Interleaver spread of dimension ", dim, " overlaps another dimension"))
+			}
+			lane |= s
+		}
+		used |= lane
+	}
+	return nil
+}
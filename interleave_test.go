@@ -0,0 +1,137 @@
+package morton
+
+import "testing"
+
+// Irregular swizzle of 2 dimensions of 6 bits: bit j of dimension dim moves to swizzle[dim][j].
+type swizzle [2][6]uint8
+
+var testSwizzle = swizzle{{5, 0, 11, 2, 8, 7}, {1, 10, 3, 9, 4, 6}}
+
+func (s swizzle) Spread(value uint32, dim uint8) (code uint64) {
+	for j, pos := range s[dim] {
+		code |= uint64(value>>uint(j)&1) << pos
+	}
+	return
+}
+
+func (s swizzle) Compact(code uint64, dim uint8) (value uint32) {
+	for j, pos := range s[dim] {
+		value |= uint32(code>>pos&1) << uint(j)
+	}
+	return
+}
+
+// Drops the top bit, so that Compact does not invert Spread for large values.
+type lossyInterleaver struct{}
+
+func (lossyInterleaver) Spread(value uint32, dim uint8) uint64 {
+	return Dilate(value&0x7, 2) << dim
+}
+
+func (lossyInterleaver) Compact(code uint64, dim uint8) uint32 {
+	return Undilate(code>>dim, 2)
+}
+
+// Every dimension onto the same bits.
+type overlappingInterleaver struct{}
+
+func (overlappingInterleaver) Spread(value uint32, dim uint8) uint64 {
+	return uint64(value)
+}
+
+func (overlappingInterleaver) Compact(code uint64, dim uint8) uint32 {
+	return uint32(code)
+}
+
+// Z-order, but for dimension 1's lowest bit, which shares dimension 0's.  Each dimension round trips on its own.
+type collidingInterleaver struct{}
+
+func (collidingInterleaver) Spread(value uint32, dim uint8) uint64 {
+	if dim == 0 {
+		return Dilate(value, 2)
+	}
+	return Dilate(value&^1, 2)<<1 | uint64(value&1)
+}
+
+func (collidingInterleaver) Compact(code uint64, dim uint8) uint32 {
+	if dim == 0 {
+		return Undilate(code, 2)
+	}
+	return Undilate(code>>1, 2)&^1 | uint32(code&1)
+}
+
+func TestCustomInterleaver(t *testing.T) {
+	m := new(Morton)
+	if err := m.Create(2, 64, WithInterleaver(testSwizzle)); err != nil {
+		t.Fatal(err)
+	}
+	codes, vectors := allCodes(t, m)
+	seen := make(map[uint64]bool)
+	for n, code := range codes {
+		if want := testSwizzle.Spread(vectors[n][0], 0) | testSwizzle.Spread(vectors[n][1], 1); code != want {
+			t.Fatalf("Encode(%v) = %#x, want %#x", vectors[n], code, want)
+		}
+		if got := m.Decode(code); !equalUint32s(got, vectors[n]) {
+			t.Fatalf("Decode(Encode(%v)) = %v", vectors[n], got)
+		}
+		if seen[code] {
+			t.Fatalf("code %#x is repeated", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) != 64*64 {
+		t.Errorf("%v distinct codes, want %v", len(seen), 64*64)
+	}
+
+	// Gray coding composes with the interleaver
+	g := New(2, 64, WithInterleaver(testSwizzle), WithGrayCode())
+	for _, v := range vectors {
+		if got := g.Decode(g.MustEncode(v)); !equalUint32s(got, v) {
+			t.Fatalf("Gray coded Decode(Encode(%v)) = %v", v, got)
+		}
+	}
+}
+
+func TestZOrderInterleaver(t *testing.T) {
+	m, z := New(3, 32), New(3, 32, WithInterleaver(ZOrderInterleaver{3}))
+	codes, vectors := allCodes(t, m)
+	for n, code := range codes {
+		if got := z.MustEncode(vectors[n]); got != code {
+			t.Fatalf("ZOrderInterleaver encodes %v to %v, the default to %v", vectors[n], got, code)
+		}
+		if got := z.Decode(code); !equalUint32s(got, vectors[n]) {
+			t.Fatalf("ZOrderInterleaver decodes %v to %v, want %v", code, got, vectors[n])
+		}
+	}
+}
+
+func TestInterleaverValidation(t *testing.T) {
+	for name, i := range map[string]Interleaver{"lossy": lossyInterleaver{}, "overlapping": overlappingInterleaver{}} {
+		if err := ValidateInterleaver(i, 2, 16); err == nil {
+			t.Errorf("ValidateInterleaver of the %v interleaver succeeded", name)
+		}
+		if err := new(Morton).Create(2, 16, WithInterleaver(i)); err == nil {
+			t.Errorf("Create with the %v interleaver succeeded", name)
+		}
+	}
+	// Within its 3 bits the lossy interleaver is fine
+	if err := ValidateInterleaver(lossyInterleaver{}, 2, 8); err != nil {
+		t.Errorf("ValidateInterleaver of 3 bits: %v", err)
+	}
+	if err := ValidateInterleaver(testSwizzle, 2, 64); err != nil {
+		t.Errorf("ValidateInterleaver of the swizzle: %v", err)
+	}
+
+	// The largest value of 5, 4, has its lowest bit clear, yet smaller values collide on it
+	for _, size := range []uint32{5, 12, 2} {
+		if err := ValidateInterleaver(collidingInterleaver{}, 2, size); err == nil {
+			t.Errorf("ValidateInterleaver of the colliding interleaver for size %v succeeded", size)
+		}
+	}
+	if err := new(Morton).Create(2, 5, WithInterleaver(collidingInterleaver{})); err == nil {
+		t.Error("Create with the colliding interleaver succeeded")
+	}
+	if err := ValidateInterleaver(collidingInterleaver{}, 2, 1); err != nil {
+		t.Errorf("ValidateInterleaver of a single value: %v", err)
+	}
+}
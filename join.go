@@ -0,0 +1,78 @@
+package morton
+
+import "sort"
+
+// Calls fn(ai, bi) for every pair of codes from a and b, both sorted ascending, lying in the same cell at level.  The pairs are found by a linear merge over the cell codes, without being collected.  Layouts other than the default have no cells, so fn is never called.
+func (m *Morton) Join(a, b []uint64, level uint8, fn func(ai, bi int)) {
+	shift, err := m.cellShift(level)
+	if err != nil {
+		return
+	}
+	cell := func(code uint64) uint64 { return code &^ lowMask(shift) }
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		ca, cb := cell(a[i]), cell(b[j])
+		switch {
+		case ca < cb:
+			i++
+		case cb < ca:
+			j++
+		default:
+			ei, ej := i, j
+			for ei < len(a) && cell(a[ei]) == ca {
+				ei++
+			}
+			for ej < len(b) && cell(b[ej]) == ca {
+				ej++
+			}
+			for ; i < ei; i++ {
+				for k := j; k < ej; k++ {
+					fn(i, k)
+				}
+			}
+			j = ej
+		}
+	}
+}
+
+// Like Join, but also pairs codes in adjacent cells at level, including diagonally adjacent ones, for proximity joins within one cell.  For each run of a in one cell, the neighbouring cells' runs of b are found by binary search.
+func (m *Morton) NeighborJoin(a, b []uint64, level uint8, fn func(ai, bi int)) {
+	shift, err := m.cellShift(level)
+	if err != nil {
+		return
+	}
+	size := uint32(1) << (m.Bits() - level)
+
+	for i := 0; i < len(a); {
+		c := a[i] &^ lowMask(shift)
+		e := i
+		for e < len(a) && a[e]&^lowMask(shift) == c {
+			e++
+		}
+
+		// The cell and its neighbours, one dimension at a time
+		cells := []uint64{c}
+		for dim := uint8(0); dim < m.Dimensions; dim++ {
+			for _, n := range cells {
+				v := m.Project(n, dim)
+				if v >= size {
+					cells = append(cells, m.Inject(n, dim, v-size))
+				}
+				if uint64(v)+uint64(size) < uint64(m.Tables[dim].Length) {
+					cells = append(cells, m.Inject(n, dim, v+size))
+				}
+			}
+		}
+		sort.Slice(cells, func(x, y int) bool { return cells[x] < cells[y] })
+
+		for _, n := range cells {
+			lo := sort.Search(len(b), func(k int) bool { return b[k] >= n })
+			for k := lo; k < len(b) && b[k]&^lowMask(shift) == n; k++ {
+				for x := i; x < e; x++ {
+					fn(x, k)
+				}
+			}
+		}
+		i = e
+	}
+}
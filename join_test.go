@@ -0,0 +1,92 @@
+package morton
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func sortedCodes(r *rand.Rand, m *Morton, n int) []uint64 {
+	codes := make([]uint64, n)
+	v := make([]uint32, m.Dimensions)
+	for i := range codes {
+		for k := range v {
+			v[k] = uint32(r.Intn(int(m.Tables[k].Length)))
+		}
+		codes[i] = m.MustEncode(v)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// Brute force pairs whose cells at level are at most reach apart in every dimension, as a len(a) x len(b) matrix.
+func bruteJoin(m *Morton, a, b []uint64, level uint8, reach uint32) []bool {
+	h := m.Bits() - level
+	cells := func(codes []uint64) [][]uint32 {
+		vs := make([][]uint32, len(codes))
+		for i, c := range codes {
+			vs[i] = m.Decode(c)
+			for k := range vs[i] {
+				vs[i][k] >>= h
+			}
+		}
+		return vs
+	}
+	pairs := make([]bool, len(a)*len(b))
+	bs := cells(b)
+	for i, va := range cells(a) {
+		for j, vb := range bs {
+			near := true
+			for k := range va {
+				near = near && absDiff(va[k], vb[k]) <= reach
+			}
+			pairs[i*len(b)+j] = near
+		}
+	}
+	return pairs
+}
+
+func TestJoin(t *testing.T) {
+	r := rand.New(rand.NewSource(131))
+	for _, m := range []*Morton{New(2, 256), New(3, 64)} {
+		a, b := sortedCodes(r, m, 2000), sortedCodes(r, m, 1500)
+		for _, level := range []uint8{1, 3, 5} {
+			for _, reach := range []uint32{0, 1} {
+				got := make([]int, len(a)*len(b))
+				join := m.Join
+				if reach == 1 {
+					join = m.NeighborJoin
+				}
+				join(a, b, level, func(ai, bi int) {
+					got[ai*len(b)+bi]++
+				})
+
+				want := bruteJoin(m, a, b, level, reach)
+				for p, n := range got {
+					if want[p] && n != 1 || !want[p] && n != 0 {
+						t.Fatalf("%vD: join at level %v within %v found pair %v, %v %v times; brute force %v", m.Dimensions, level, reach, p/len(b), p%len(b), n, want[p])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestJoinEdges(t *testing.T) {
+	m := New(2, 16)
+	calls := 0
+	count := func(int, int) { calls++ }
+	m.Join(nil, []uint64{1, 2}, 2, count)
+	m.NeighborJoin([]uint64{1, 2}, nil, 2, count)
+	m.Join([]uint64{1}, []uint64{1}, 5, count)
+	New(2, 16, WithGrayCode()).Join([]uint64{1}, []uint64{1}, 2, count)
+	if calls != 0 {
+		t.Errorf("Empty, invalid and Gray coded joins made %v calls", calls)
+	}
+
+	// Level 0 is one cell holding everything
+	m.Join([]uint64{0, 7, 255}, []uint64{3, 200}, 0, count)
+	if calls != 6 {
+		t.Errorf("Join at level 0 made %v calls, want 6", calls)
+	}
+}
@@ -0,0 +1,50 @@
+package morton
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrKeyMismatch = errors.New("Key does not match the codec's prefix and suffix length")
+
+// Builds composite keys: a fixed prefix, the big-endian code, then a suffix, e.g. {table}{code}{entity id}.  A SuffixLen of 0 allows suffixes of any length.
+type KeyCodec struct {
+	Prefix    []byte
+	SuffixLen int
+}
+
+func NewKeyCodec(prefix []byte, suffixLen int) *KeyCodec {
+	return &KeyCodec{append([]byte(nil), prefix...), suffixLen}
+}
+
+// Key of code and suffix.  If a fixed suffix length is configured and suffix doesn't have it, the error wraps ErrKeyMismatch.
+func (k *KeyCodec) EncodeKey(code uint64, suffix []byte) ([]byte, error) {
+	if k.SuffixLen > 0 && len(suffix) != k.SuffixLen {
+		return nil, fmt.Errorf("%w.  Suffix of %v bytes, not %v", ErrKeyMismatch, len(suffix), k.SuffixLen)
+	}
+
+	key := make([]byte, len(k.Prefix)+8+len(suffix))
+	copy(key, k.Prefix)
+	binary.BigEndian.PutUint64(key[len(k.Prefix):], code)
+	copy(key[len(k.Prefix)+8:], suffix)
+	return key, nil
+}
+
+// Splits key into its code and suffix.  The suffix aliases key.
+func (k *KeyCodec) DecodeKey(key []byte) (code uint64, suffix []byte, err error) {
+	n := len(k.Prefix) + 8
+	if len(key) < n || !bytes.HasPrefix(key, k.Prefix) {
+		return 0, nil, ErrKeyMismatch
+	}
+	if k.SuffixLen > 0 && len(key) != n+k.SuffixLen {
+		return 0, nil, ErrKeyMismatch
+	}
+	return binary.BigEndian.Uint64(key[len(k.Prefix):]), key[n:], nil
+}
+
+// Iterator bounds covering every key, with any suffix, of the codes within the inclusive range [lo, hi]: start is inclusive and end exclusive.  When hi is the largest code, end carries into the prefix, and is nil if the prefix can't be incremented either.
+func (k *KeyCodec) RangeKeys(lo, hi uint64) (start, end []byte) {
+	return codeKey(k.Prefix, lo), codeKeyEnd(k.Prefix, hi)
+}
@@ -0,0 +1,100 @@
+package morton
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+// Key of code and suffix, for codecs accepting any suffix.
+func mustKey(t *testing.T, k *KeyCodec, code uint64, suffix []byte) []byte {
+	t.Helper()
+	key, err := k.EncodeKey(code, suffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeyCodecRoundTrip(t *testing.T) {
+	for _, k := range []*KeyCodec{NewKeyCodec([]byte{'t'}, 4), NewKeyCodec(nil, 0), NewKeyCodec([]byte("users/"), 0)} {
+		for _, code := range []uint64{0, 1, 0x0123456789abcdef, math.MaxUint64} {
+			for _, suffix := range [][]byte{{1, 2, 3, 4}, {0, 0, 0, 0}} {
+				key, err := k.EncodeKey(code, suffix)
+				if err != nil || !bytes.HasPrefix(key, k.Prefix) || len(key) != len(k.Prefix)+8+len(suffix) {
+					t.Fatalf("EncodeKey(%x, % x) = % x, %v", code, suffix, key, err)
+				}
+				got, gotSuffix, err := k.DecodeKey(key)
+				if err != nil || got != code || !bytes.Equal(gotSuffix, suffix) {
+					t.Fatalf("DecodeKey(EncodeKey(%x, % x)) = %x, % x, %v", code, suffix, got, gotSuffix, err)
+				}
+			}
+		}
+	}
+
+	// Keys sort by code, whatever the suffix
+	k := NewKeyCodec([]byte{'t'}, 0)
+	if bytes.Compare(mustKey(t, k, 1, []byte{0xff, 0xff}), mustKey(t, k, 2, nil)) >= 0 {
+		t.Error("Keys of codes 1 and 2 are out of order")
+	}
+}
+
+func TestKeyCodecRejects(t *testing.T) {
+	k := NewKeyCodec([]byte{'t'}, 2)
+	for _, key := range [][]byte{
+		nil,
+		{'t', 0, 0, 0},
+		{'u', 0, 0, 0, 0, 0, 0, 0, 0, 1, 2},
+		{'t', 0, 0, 0, 0, 0, 0, 0, 0, 1},
+		{'t', 0, 0, 0, 0, 0, 0, 0, 0, 1, 2, 3},
+	} {
+		if _, _, err := k.DecodeKey(key); !errors.Is(err, ErrKeyMismatch) {
+			t.Errorf("DecodeKey(% x) returned %v, want ErrKeyMismatch", key, err)
+		}
+	}
+
+	for _, suffix := range [][]byte{nil, {1}, {1, 2, 3}} {
+		if key, err := k.EncodeKey(0, suffix); !errors.Is(err, ErrKeyMismatch) || key != nil {
+			t.Errorf("EncodeKey with a %v byte suffix = % x, %v; want ErrKeyMismatch", len(suffix), key, err)
+		}
+	}
+}
+
+func TestRangeKeys(t *testing.T) {
+	k := NewKeyCodec([]byte{'t'}, 0)
+	start, end := k.RangeKeys(5, 9)
+	for _, tc := range []struct {
+		key []byte
+		in  bool
+	}{
+		{mustKey(t, k, 4, []byte{0xff}), false},
+		{mustKey(t, k, 5, nil), true},
+		{mustKey(t, k, 9, []byte{0xff, 0xff}), true},
+		{mustKey(t, k, 10, nil), false},
+	} {
+		if in := bytes.Compare(tc.key, start) >= 0 && bytes.Compare(tc.key, end) < 0; in != tc.in {
+			t.Errorf("Key % x is in [% x, % x): %v", tc.key, start, end, in)
+		}
+	}
+
+	// The largest code carries into the prefix, rather than wrapping to code 0
+	_, end = k.RangeKeys(0, math.MaxUint64)
+	if !bytes.Equal(end, []byte{'u'}) {
+		t.Errorf("End of the range to the largest code = % x, want 75", end)
+	}
+	if last := mustKey(t, k, math.MaxUint64, []byte{0xff}); bytes.Compare(last, end) >= 0 {
+		t.Error("The largest code's key is beyond the end")
+	}
+
+	// Carries ripple through trailing 0xff prefix bytes, and beyond every byte, end is unbounded
+	_, end = NewKeyCodec([]byte{1, 0xff}, 0).RangeKeys(0, math.MaxUint64)
+	if !bytes.Equal(end, []byte{2}) {
+		t.Errorf("End under prefix 01 ff = % x, want 02", end)
+	}
+	for _, prefix := range [][]byte{nil, {0xff, 0xff}} {
+		if _, end := NewKeyCodec(prefix, 0).RangeKeys(0, math.MaxUint64); end != nil {
+			t.Errorf("End under prefix % x = % x, want nil", prefix, end)
+		}
+	}
+}
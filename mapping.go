@@ -0,0 +1,55 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Creates a Morton whose tables accept transformed coordinates: the entry for original index i of dimension k moves to index fn(v)[k], where v is the zero vector with v[k] = i, keeping its original code.  Encoding fn(x) then yields the code of x, and decoding yields x, e.g. for log-scale or quantile-normalized inputs.  The mapping must be strictly increasing per dimension, hence injective, and below MaxUint32.  Indices fn never produces are holes, which Encode rejects; the tables hold only the mapped entries, sorted by Index, with Length one past the largest.
+func (m *Morton) MapCoords(fn func(in []uint32) []uint32) (*Morton, error) {
+	d := int(m.Dimensions)
+	if len(m.Tables) == 0 || len(m.Tables) < d {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+
+	n := *m
+	n.Magic = append([]uint64(nil), m.Magic...)
+	n.Tables = make([]Table, len(m.Tables))
+
+	v := make([]uint32, d)
+	for k, t := range m.Tables {
+		mapped := make([]uint32, len(t.Encode))
+		for i, b := range t.Encode {
+			for j := range v {
+				v[j] = 0
+			}
+			v[k] = b.Index
+			out := fn(v)
+			if len(out) != d {
+				return nil, ErrDimensionMismatch
+			}
+			if i > 0 && out[k] <= mapped[i-1] {
+				return nil, errors.New(fmt.Sprint("Mapped indices of dimension ", k, " are duplicated or out of order at index ", b.Index))
+			}
+			mapped[i] = out[k]
+		}
+
+		length := uint32(0)
+		if len(mapped) > 0 {
+			if mapped[len(mapped)-1] == math.MaxUint32 {
+				return nil, errors.New(fmt.Sprint("Mapped indices of dimension ", k, " must be less than ", uint32(math.MaxUint32)))
+			}
+			length = mapped[len(mapped)-1] + 1
+		}
+
+		// Entries stay sorted by Index, without placeholders for the holes; Length is one past the largest
+		e := make([]Bit, len(t.Encode))
+		for i, b := range t.Encode {
+			e[i] = Bit{mapped[i], b.Value}
+		}
+		n.Tables[k] = Table{Index: t.Index, Length: length, Encode: e}
+	}
+
+	return &n, nil
+}
@@ -0,0 +1,111 @@
+package morton
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMapCoordsShift(t *testing.T) {
+	m := New(2, 16)
+	shifted, err := m.MapCoords(func(in []uint32) []uint32 {
+		out := make([]uint32, len(in))
+		for i, v := range in {
+			out[i] = v + 1
+		}
+		return out
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for x := uint32(0); x < 16; x++ {
+		for y := uint32(0); y < 16; y++ {
+			want, _ := m.Encode([]uint32{x, y})
+			got, err := shifted.Encode([]uint32{x + 1, y + 1})
+			if err != nil {
+				t.Fatalf("Encode(%v, %v): %v", x+1, y+1, err)
+			}
+			if got != want {
+				t.Errorf("Encode(%v, %v) = %v, want %v", x+1, y+1, got, want)
+			}
+		}
+	}
+	// Index 0 is a hole, and 17 is beyond the table
+	for _, v := range [][]uint32{{0, 1}, {1, 0}, {17, 1}} {
+		if _, err := shifted.Encode(v); err == nil {
+			t.Errorf("Encode(%v) succeeded, want an error", v)
+		}
+	}
+}
+
+func TestMapCoordsTableInvariants(t *testing.T) {
+	m := New(2, 8)
+	// Even indices only: 0, 2, ..., 14
+	mapped, err := m.MapCoords(func(in []uint32) []uint32 {
+		return []uint32{in[0] * 2, in[1] * 2}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, tb := range mapped.Tables {
+		if len(tb.Encode) != 8 || tb.Length != 15 {
+			t.Errorf("table %v has %v entries and length %v, want 8 and 15", k, len(tb.Encode), tb.Length)
+		}
+		for i, b := range tb.Encode {
+			if b.Index != uint32(2*i) {
+				t.Errorf("table %v entry %v has index %v, want %v", k, i, b.Index, 2*i)
+			}
+		}
+		for i := uint32(0); i < 16; i++ {
+			_, ok := tb.Search(i)
+			if want := i%2 == 0 && i < 16; ok != want {
+				t.Errorf("table %v: Search(%v) found %v, want %v", k, i, ok, want)
+			}
+		}
+	}
+	if c := mapped.Capacity(); c != 14 {
+		t.Errorf("Capacity() = %v, want 14", c)
+	}
+	if mapped.MaxCode() != m.MaxCode() {
+		t.Errorf("MaxCode() = %#x, want %#x", mapped.MaxCode(), m.MaxCode())
+	}
+	compact, err := mapped.Compact()
+	if err != nil {
+		t.Fatalf("Compact(): %v", err)
+	}
+	if compact.Tables[0].Length != 15 {
+		t.Errorf("compacted length %v, want 15", compact.Tables[0].Length)
+	}
+
+	code, err := mapped.Encode([]uint32{6, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := mapped.Decode(code); v[0] != 3 || v[1] != 5 {
+		t.Errorf("Decode gave %v, want the original coordinates [3 5]", v)
+	}
+	if c := mapped.EncodeClamp([]uint32{7, 100}); c != mapped.MustEncode([]uint32{6, 14}) {
+		t.Errorf("EncodeClamp fell to %v, want the code of [6 14]", mapped.Decode(c))
+	}
+	if _, err := mapped.Encode([]uint32{3, 0}); err == nil {
+		t.Error("encoding a hole succeeded, want an error")
+	}
+}
+
+func TestMapCoordsRejects(t *testing.T) {
+	m := New(2, 8)
+	for name, fn := range map[string]func([]uint32) []uint32{
+		"duplicate":    func(in []uint32) []uint32 { return []uint32{in[0] / 2, in[1]} },
+		"decreasing":   func(in []uint32) []uint32 { return []uint32{7 - in[0], in[1]} },
+		"out of range": func(in []uint32) []uint32 { return []uint32{in[0] + math.MaxUint32 - 7, in[1]} },
+		"length":       func(in []uint32) []uint32 { return in[:1] },
+	} {
+		if _, err := m.MapCoords(fn); err == nil {
+			t.Errorf("%v mapping succeeded, want an error", name)
+		}
+	}
+	if _, err := new(Morton).MapCoords(func(in []uint32) []uint32 { return in }); err == nil {
+		t.Error("mapping without tables succeeded, want an error")
+	}
+}
@@ -0,0 +1,108 @@
+package morton
+
+import (
+	"errors"
+	"math/bits"
+)
+
+/*
+Row-major to Morton remapping for width x height arrays.  The array is padded to the next power of two square, with col as dimension 0 and
+row as dimension 1, and Morton indices are compacted: a cell's index is the number of in-array cells preceding it in Z order.  Indices are
+therefore dense over [0, width*height), and the mapping is a bijection for any shape, e.g., 3x5 or 1xN.
+*/
+
+var ErrOutOfShape = errors.New("Coordinate or index lies outside the array shape")
+
+// Number of in-array cells in the padded quadrant of side size at (r0, c0).
+func shapeCount(r0, c0, size, width, height uint64) uint64 {
+	if r0 >= height || c0 >= width {
+		return 0
+	}
+	rows, cols := size, size
+	if r0+rows > height {
+		rows = height - r0
+	}
+	if c0+cols > width {
+		cols = width - c0
+	}
+	return rows * cols
+}
+
+// Levels of the padded square.
+func shapeBits(width, height uint32) int {
+	n := width
+	if height > n {
+		n = height
+	}
+	return bits.Len32(n - 1)
+}
+
+// Compacted Morton index of (row, col) in a width x height array.
+func ToMortonIndex(row, col, width, height uint32) (uint64, error) {
+	if row >= height || col >= width {
+		return 0, ErrOutOfShape
+	}
+
+	w, h := uint64(width), uint64(height)
+	var idx, r0, c0 uint64
+	for level := shapeBits(width, height) - 1; level >= 0; level-- {
+		half := uint64(1) << uint(level)
+		q := uint64(row>>uint(level)&1)<<1 | uint64(col>>uint(level)&1)
+		for p := uint64(0); p < q; p++ {
+			idx += shapeCount(r0+(p>>1)*half, c0+(p&1)*half, half, w, h)
+		}
+		r0 += (q >> 1) * half
+		c0 += (q & 1) * half
+	}
+	return idx, nil
+}
+
+// Inverse of ToMortonIndex.
+func FromMortonIndex(idx uint64, width, height uint32) (row, col uint32, err error) {
+	w, h := uint64(width), uint64(height)
+	if idx >= w*h {
+		err = ErrOutOfShape
+		return
+	}
+
+	var r0, c0 uint64
+	for level := shapeBits(width, height) - 1; level >= 0; level-- {
+		half := uint64(1) << uint(level)
+		for q := uint64(0); q < 4; q++ {
+			r, c := r0+(q>>1)*half, c0+(q&1)*half
+			n := shapeCount(r, c, half, w, h)
+			if idx < n {
+				r0, c0 = r, c
+				break
+			}
+			idx -= n
+		}
+	}
+	return uint32(r0), uint32(c0), nil
+}
+
+// Row-major index (row*width + col) of each cell, in Morton order, i.e., perm[ToMortonIndex(row, col, ...)] == row*width + col.
+func MortonPermutation(width, height uint32) []uint64 {
+	w, h := uint64(width), uint64(height)
+	perm := make([]uint64, 0, w*h)
+	if w == 0 || h == 0 {
+		return perm
+	}
+
+	var walk func(r0, c0, size uint64)
+	walk = func(r0, c0, size uint64) {
+		if shapeCount(r0, c0, size, w, h) == 0 {
+			return
+		}
+		if size == 1 {
+			perm = append(perm, r0*w+c0)
+			return
+		}
+		half := size >> 1
+		for q := uint64(0); q < 4; q++ {
+			walk(r0+(q>>1)*half, c0+(q&1)*half, half)
+		}
+	}
+	walk(0, 0, uint64(1)<<uint(shapeBits(width, height)))
+	return perm
+}
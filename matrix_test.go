@@ -0,0 +1,72 @@
+package morton
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+var testShapes = [][2]uint32{{1, 1}, {3, 5}, {5, 3}, {1, 17}, {17, 1}, {4, 4}, {7, 9}, {16, 2}}
+
+func TestMortonIndexBijection(t *testing.T) {
+	for _, shape := range testShapes {
+		width, height := shape[0], shape[1]
+		n := uint64(width) * uint64(height)
+
+		// Cells in padded Z order, with col as dimension 0
+		type cell struct {
+			row, col uint32
+			z        uint64
+		}
+		var cells []cell
+		for row := uint32(0); row < height; row++ {
+			for col := uint32(0); col < width; col++ {
+				cells = append(cells, cell{row, col, Dilate(col, 2) | Dilate(row, 2)<<1})
+			}
+		}
+		sort.Slice(cells, func(i, j int) bool { return cells[i].z < cells[j].z })
+
+		seen := make([]bool, n)
+		for want, c := range cells {
+			idx, err := ToMortonIndex(c.row, c.col, width, height)
+			if err != nil {
+				t.Fatalf("%vx%v: ToMortonIndex(%v, %v): %v", width, height, c.row, c.col, err)
+			}
+			if idx != uint64(want) {
+				t.Fatalf("%vx%v: ToMortonIndex(%v, %v) = %v, want %v", width, height, c.row, c.col, idx, want)
+			}
+			if seen[idx] {
+				t.Fatalf("%vx%v: index %v is repeated", width, height, idx)
+			}
+			seen[idx] = true
+			if row, col, err := FromMortonIndex(idx, width, height); err != nil || row != c.row || col != c.col {
+				t.Fatalf("%vx%v: FromMortonIndex(%v) = %v, %v, %v; want %v, %v", width, height, idx, row, col, err, c.row, c.col)
+			}
+		}
+
+		perm := MortonPermutation(width, height)
+		if uint64(len(perm)) != n {
+			t.Fatalf("%vx%v: MortonPermutation has %v entries, want %v", width, height, len(perm), n)
+		}
+		for idx, p := range perm {
+			if c := cells[idx]; p != uint64(c.row)*uint64(width)+uint64(c.col) {
+				t.Fatalf("%vx%v: MortonPermutation gave %v at %v, want %v", width, height, p, idx, uint64(c.row)*uint64(width)+uint64(c.col))
+			}
+		}
+	}
+}
+
+func TestMortonIndexRejects(t *testing.T) {
+	if _, err := ToMortonIndex(5, 0, 3, 5); !errors.Is(err, ErrOutOfShape) {
+		t.Errorf("ToMortonIndex of row 5 of 5 returned %v", err)
+	}
+	if _, err := ToMortonIndex(0, 3, 3, 5); !errors.Is(err, ErrOutOfShape) {
+		t.Errorf("ToMortonIndex of col 3 of 3 returned %v", err)
+	}
+	if _, _, err := FromMortonIndex(15, 3, 5); !errors.Is(err, ErrOutOfShape) {
+		t.Errorf("FromMortonIndex(15) of 3x5 returned %v", err)
+	}
+	if perm := MortonPermutation(0, 4); len(perm) != 0 {
+		t.Errorf("MortonPermutation of 0x4 = %v", perm)
+	}
+}
@@ -8,9 +8,23 @@
 package morton
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
 	"sort"
+	"time"
+	"unsafe"
+)
+
+var (
+	ErrDimensionMismatch = errors.New("Vector length does not match the number of dimensions")
+	ErrComponentOverflow = errors.New("Input vector component exceeds the corresponding lookup table's size")
+	ErrInvalidDimensions = errors.New("Number of dimensions must be at least 1")
+	ErrInvalidSize       = errors.New("Table size must be at least 1")
+	ErrCapacityExceeded  = errors.New("Interleaved codes exceed 64 bits")
 )
 
 type Table struct {
@@ -27,6 +41,96 @@ func (t Table) String() string {
 	return fmt.Sprintf("Index: %v\nLength: %v\n%v", t.Index, t.Length, bits)
 }
 
+// Reports whether both tables have the same Index, Length and entries.
+func (t Table) Equal(other Table) bool {
+	if t.Index != other.Index || t.Length != other.Length || len(t.Encode) != len(other.Encode) {
+		return false
+	}
+	for i, b := range t.Encode {
+		if b != other.Encode[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// New table with fn applied to every entry, preserving Index and Length.
+func (t Table) Map(fn func(Bit) Bit) Table {
+	n := Table{Index: t.Index, Length: t.Length, Encode: make([]Bit, len(t.Encode))}
+	for i, b := range t.Encode {
+		n.Encode[i] = fn(b)
+	}
+	return n
+}
+
+// New table with only the entries satisfying fn, its Length updated accordingly.
+func (t Table) Filter(fn func(Bit) bool) Table {
+	n := Table{Index: t.Index}
+	for _, b := range t.Encode {
+		if fn(b) {
+			n.Encode = append(n.Encode, b)
+		}
+	}
+	n.Length = uint32(len(n.Encode))
+	return n
+}
+
+// New table mapping each entry's Value back to its Index, sorted by the new Index, for table based decoding (see Search).  Values must be unique and fit a uint32 Index.
+func (t Table) Invert() (Table, error) {
+	n := Table{Index: t.Index, Length: t.Length, Encode: make([]Bit, len(t.Encode))}
+	for i, b := range t.Encode {
+		if b.Value > math.MaxUint32 {
+			return Table{}, errors.New(fmt.Sprint("Table value ", b.Value, " exceeds the range of an inverted index"))
+		}
+		n.Encode[i] = Bit{uint32(b.Value), uint64(b.Index)}
+	}
+
+	sort.Sort(BitSlice(n.Encode))
+	for i := 1; i < len(n.Encode); i++ {
+		if n.Encode[i].Index == n.Encode[i-1].Index {
+			return Table{}, errors.New(fmt.Sprint("Table value ", n.Encode[i].Index, " is duplicated"))
+		}
+	}
+	return n, nil
+}
+
+// Finds the entry with the given Index in a table sorted by Index, by binary search.
+func (t Table) Search(index uint32) (Bit, bool) {
+	i := sort.Search(len(t.Encode), func(i int) bool { return t.Encode[i].Index >= index })
+	if i < len(t.Encode) && t.Encode[i].Index == index {
+		return t.Encode[i], true
+	}
+	return Bit{}, false
+}
+
+// Entry for index, found by position in a table as created, or by binary search in one whose indices are sparse, such as a mapped table (see MapCoords).
+func (t Table) lookup(index uint32) (Bit, bool) {
+	if index < uint32(len(t.Encode)) && t.Encode[index].Index == index {
+		return t.Encode[index], true
+	}
+	return t.Search(index)
+}
+
+// Entry with the largest index not exceeding index, or the first entry if there is none.  The table must not be empty.
+func (t Table) floor(index uint32) Bit {
+	if b, ok := t.lookup(index); ok {
+		return b
+	}
+	i := sort.Search(len(t.Encode), func(i int) bool { return t.Encode[i].Index > index })
+	if i == 0 {
+		return t.Encode[0]
+	}
+	return t.Encode[i-1]
+}
+
+// Number of bits needed to represent the table's last index.
+func (t Table) bits() uint {
+	if t.Length == 0 {
+		return 0
+	}
+	return uint(bits.Len32(t.Length - 1))
+}
+
 // Sortable Table slice type to satisfy the sort package interface
 type ByTable []Table
 
@@ -51,6 +155,38 @@ func (b Bit) String() string {
 	return fmt.Sprintf("[%v]%08b", b.Index, b.Value)
 }
 
+// Reports whether both Index and Value are equal.
+func (b Bit) Equals(other Bit) bool {
+	return b == other
+}
+
+// Orders by Index, then by Value.
+func (b Bit) Less(other Bit) bool {
+	if b.Index != other.Index {
+		return b.Index < other.Index
+	}
+	return b.Value < other.Value
+}
+
+func (b Bit) IsZero() bool {
+	return b == Bit{}
+}
+
+// Sortable Bit slice type, ordered by Bit.Less
+type BitSlice []Bit
+
+func (b BitSlice) Len() int {
+	return len(b)
+}
+
+func (b BitSlice) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
+
+func (b BitSlice) Less(i, j int) bool {
+	return b[i].Less(b[j])
+}
+
 // Sortable Table slice type to satisfy the sort package interface
 type ByBit []Bit
 
@@ -69,17 +205,64 @@ func (b ByBit) Less(i, j int) bool {
 type Morton struct {
 	Dimensions uint8
 	Tables     []Table
-	Magic      []uint64
+	// Retained for compatibility; Decode uses Undilate.
+	Magic []uint64
+
+	// Set via Options
+	gray    bool
+	color   bool
+	scatter bool
+
+	interleaver Interleaver
+	timeUnit    time.Duration
+	transform   *Transform
 }
 
-// Convenience function
-func New(dimensions uint8, size uint32) *Morton {
+// Convenience function.  If Create fails, its error is discarded and the Morton has no tables, so that Encode reports their absence; call Create directly to check it.
+func New(dimensions uint8, size uint32, opts ...Option) *Morton {
 	m := new(Morton)
-	m.Create(dimensions, size)
+	m.Create(dimensions, size, opts...)
 	return m
 }
 
-func (m *Morton) Create(dimensions uint8, size uint32) {
+// Validates a configuration before any tables are created: at least one dimension and one table entry, and codes of at most 64 bits.
+func staticCheck(dimensions uint8, size uint32) error {
+	switch {
+	case dimensions == 0:
+		return ErrInvalidDimensions
+	case size == 0:
+		return ErrInvalidSize
+	}
+	if b := bits.Len32(size-1) * int(dimensions); b > 64 {
+		return fmt.Errorf("%w.  %v dimensions of %v bits need %v bits", ErrCapacityExceeded, dimensions, bits.Len32(size-1), b)
+	}
+	return nil
+}
+
+// Checks the current configuration, its dimensions and longest table, as Create does before creating tables.  Configurations that fail never produce useful codes.
+func (m *Morton) StaticCheck() error {
+	return staticCheck(m.Dimensions, m.CurrentTableSize())
+}
+
+// Creates the lookup tables and magic bits for the given dimensions and table size, applying opts first.  Invalid configurations (see StaticCheck), and custom Interleavers failing validation (see ValidateInterleaver), are errors, and no tables are created.
+func (m *Morton) Create(dimensions uint8, size uint32, opts ...Option) error {
+	for _, opt := range opts {
+		opt(m)
+	}
+	if err := staticCheck(dimensions, size); err != nil {
+		return err
+	}
+	if m.transform != nil {
+		if err := m.transform.check(dimensions); err != nil {
+			return err
+		}
+	}
+	if m.interleaver != nil {
+		if err := ValidateInterleaver(m.interleaver, dimensions, size); err != nil {
+			return err
+		}
+	}
+
 	done := make(chan struct{})
 	mch := make(chan []uint64)
 	go func() {
@@ -94,15 +277,220 @@ func (m *Morton) Create(dimensions uint8, size uint32) {
 	close(mch)
 	<-done
 	close(done)
+	return nil
+}
+
+// Widest table, in bits, that the helpers creating tables on a caller's behalf allow: 2^21 entries, 32 MiB, per dimension, e.g. 3 dimensions filling 63 bits.
+const maxTableBits = 21
+
+// Upper bound on the per-dimension table length chosen by AutoCreate.
+const MaxAutoTableSize = 1 << maxTableBits
+
+// Creates tables sized to hold the given maximum coordinates, one dimension per element, i.e., a table length of max(maxCoords) + 1.
+func (m *Morton) AutoCreate(maxCoords []uint32, opts ...Option) error {
+	if len(maxCoords) == 0 || len(maxCoords) > math.MaxUint8 {
+		return errors.New(fmt.Sprint("AutoCreate requires between 1 and ", math.MaxUint8, " dimensions"))
+	}
+
+	var max uint32
+	for k, c := range maxCoords {
+		if c == math.MaxUint32 {
+			return errors.New(fmt.Sprint("Maximum coordinate, ", k, " must be less than ", uint32(math.MaxUint32)))
+		}
+		if c > max {
+			max = c
+		}
+	}
+	if max+1 > MaxAutoTableSize {
+		return errors.New(fmt.Sprint("Table size ", max+1, " exceeds the limit of ", MaxAutoTableSize, " entries per dimension"))
+	}
+
+	return m.Create(uint8(len(maxCoords)), max+1, opts...)
+}
+
+// Creates a new Morton, with the same dimensions and options, whose tables are just long enough for the largest index present in any of this Morton's tables.
+func (m *Morton) Compact() (*Morton, error) {
+	if len(m.Tables) == 0 {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+
+	var max uint32
+	for _, t := range m.Tables {
+		for _, b := range t.Encode {
+			if b.Index > max {
+				max = b.Index
+			}
+		}
+	}
+
+	c := new(Morton)
+	if err := c.Create(m.Dimensions, max+1, m.options()...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// The largest coordinate every dimension's table can encode, i.e., the shortest table's length - 1.
+func (m *Morton) Capacity() uint32 {
+	if len(m.Tables) == 0 {
+		return 0
+	}
+	c := m.Tables[0].Length
+	for _, t := range m.Tables[1:] {
+		if t.Length < c {
+			c = t.Length
+		}
+	}
+	if c == 0 {
+		return 0
+	}
+	return c - 1
+}
+
+// Smallest power of two table size holding coordinates up to expectedMax, for passing to Create.  A power of two fills every bit of each lane, so codes are spread evenly over the whole interleaved range, with no gaps of unused codes, and helpers working on the full bit budget, such as Mirror, stay within the tables.  Beyond 2^31 no uint32 power of two suffices, and it returns 2^31, the largest.
+func (m *Morton) RecommendTableSize(expectedMax uint32) uint32 {
+	if expectedMax >= 1<<31 {
+		return 1 << 31
+	}
+	return 1 << bits.Len32(expectedMax)
+}
+
+// Table size in use, i.e., the longest table's length, or 0 without tables.
+func (m *Morton) CurrentTableSize() (size uint32) {
+	for _, t := range m.Tables {
+		if t.Length > size {
+			size = t.Length
+		}
+	}
+	return
+}
+
+// Approximate number of bytes held by the lookup tables and magic bits.
+func (m *Morton) MemoryEstimate() (n uint64) {
+	for _, t := range m.Tables {
+		n += uint64(len(t.Encode)) * uint64(unsafe.Sizeof(Bit{}))
+	}
+	return n + uint64(len(m.Magic))*8
+}
+
+// Estimates, before creating anything, the bytes held by the lookup tables of a Morton covering coordinates up to maxCoord, as MemoryEstimate would report them, and by an index of points codes.
+func SpaceEstimate(points int, dimensions uint8, maxCoord uint32) (tableBytes, indexBytes uint64) {
+	entries := uint64(dimensions) * (uint64(maxCoord) + 1)
+	tableBytes = entries*uint64(unsafe.Sizeof(Bit{})) + uint64(len(MakeMagic(dimensions)))*8
+	if points > 0 {
+		indexBytes = uint64(points) * 8
+	}
+	return
+}
+
+// One line description of the configuration, in the stable format:
+//
+//	Morton{dims=3, size=512, capacity=511, maxCode=0x7ffffff, memBytes=24624}
+//
+// where size is the first table's length and memBytes is MemoryEstimate().  Unlike String, table contents are never printed.
+func (m *Morton) Summary() string {
+	var size uint32
+	if len(m.Tables) > 0 {
+		size = m.Tables[0].Length
+	}
+	return fmt.Sprintf("Morton{dims=%d, size=%d, capacity=%d, maxCode=0x%x, memBytes=%d}", m.Dimensions, size, m.Capacity(), m.MaxCode(), m.MemoryEstimate())
+}
+
+// Stable FNV-1a fingerprint of the configuration: dimensions, table lengths, magic bits, and the options and anything else that change codes, but not display options such as WithColor.  Custom Interleavers are hashed by where they spread each bit of each dimension, and mapped tables (see MapCoords) by their indices.  Mortons created alike hash alike; table entries are not otherwise hashed.
+func (m *Morton) HashCode() uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	write64 := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf, v)
+		h.Write(buf)
+	}
+
+	h.Write([]byte{m.Dimensions})
+	for _, t := range m.Tables {
+		binary.LittleEndian.PutUint32(buf, t.Length)
+		h.Write(buf[:4])
+	}
+	for _, magic := range m.Magic {
+		write64(magic)
+	}
+	// WithColor only changes how codes are displayed, so it is not hashed; its bit, 4, stays unused to keep hashes stable
+	var layout byte
+	if m.gray {
+		layout |= 1
+	}
+	if m.scatter {
+		layout |= 2
+	}
+	if m.interleaver != nil {
+		layout |= 8
+	}
+	if m.transform != nil {
+		layout |= 16
+	}
+	h.Write([]byte{layout})
+	write64(uint64(m.timeUnit))
+
+	if m.interleaver != nil {
+		for i, t := range m.Tables {
+			for b := uint(0); b < t.bits(); b++ {
+				write64(m.interleaver.Spread(1<<b, uint8(i)))
+			}
+		}
+	}
+	if t := m.transform; t != nil {
+		for _, f := range append(append(append([]float64(nil), t.Offset...), t.Scale...), t.Rotation) {
+			write64(math.Float64bits(f))
+		}
+	}
+	// Dense tables hold indices 0 through Length-1; mapped ones have holes
+	for _, t := range m.Tables {
+		if uint32(len(t.Encode)) == t.Length {
+			continue
+		}
+		for _, b := range t.Encode {
+			binary.LittleEndian.PutUint32(buf, b.Index)
+			h.Write(buf[:4])
+		}
+	}
+
+	return h.Sum64()
+}
+
+// The largest code produced by encoding in-table vectors, i.e., the union of each table's largest entry.  With WithShardScatter, codes are not monotonic, and this is instead bounded by the full bit budget.
+func (m *Morton) MaxCode() (code uint64) {
+	if m.scatter && len(m.Tables) > 0 {
+		return lowMask(m.codeBits())
+	}
+
+	for _, t := range m.Tables {
+		n := len(t.Encode)
+		if n == 0 {
+			continue
+		}
+		if !m.gray && m.interleaver == nil {
+			code |= t.Encode[n-1].Value
+			continue
+		}
+		// Gray coded and custom tables may not be monotonic
+		for _, b := range t.Encode {
+			code |= b.Value
+		}
+	}
+	return
 }
 
-func (m *Morton) CreateTables(dimensions uint8, length uint32) {
+// Creates the lookup tables, one per dimension, of the given length.  Invalid configurations are errors; see StaticCheck.
+func (m *Morton) CreateTables(dimensions uint8, length uint32) error {
+	if err := staticCheck(dimensions, length); err != nil {
+		return err
+	}
+
 	ch := make(chan Table)
 
 	m.Dimensions = dimensions
 	for i := uint8(0); i < dimensions; i++ {
 		go func(i uint8) {
-			ch <- CreateTable(i, dimensions, length)
+			ch <- createTable(i, dimensions, length, m.spread(i))
 		}(i)
 	}
 	for i := uint8(0); i < dimensions; i++ {
@@ -112,9 +500,14 @@ func (m *Morton) CreateTables(dimensions uint8, length uint32) {
 	close(ch)
 
 	sort.Sort(ByTable(m.Tables))
+	return nil
 }
 
 func MakeMagic(dimensions uint8) []uint64 {
+	if dimensions == 0 {
+		return nil
+	}
+
 	// Generate nth and ith bits variables
 	d := uint64(dimensions)
 	limit := 64/d + 1
@@ -156,22 +549,65 @@ func (m *Morton) Decode(code uint64) (result []uint32) {
 		return
 	}
 
-	d := uint64(m.Dimensions)
-	r := make([]uint64, d)
+	result = make([]uint32, m.Dimensions)
+	m.decode(code, result)
+	return
+}
+
+// Decodes code into dst, which must hold exactly Dimensions components, without allocating.
+func (m *Morton) DecodeInto(code uint64, dst []uint32) error {
+	if len(dst) != int(m.Dimensions) {
+		return ErrDimensionMismatch
+	}
+	m.decode(code, dst)
+	return nil
+}
+
+func (m *Morton) decode(code uint64, result []uint32) {
+	if m.scatter {
+		code = ReverseBits(code, m.codeBits())
+	}
 
 	// Process each dimension
-	for i := uint64(0); i < d; i++ {
-		r[i] = (code >> i) & m.Magic[0]
-		for j := uint64(0); int(j) < len(m.Magic)-1; j++ {
-			r[i] = (r[i] ^ (r[i] >> ((d - 1) * (1 << j)))) & m.Magic[j+1]
-		}
+	for i := range result {
+		result[i] = m.component(code, uint8(i))
+	}
+}
 
-		result = append(result, uint32(r[i]))
+// Component i of an unscattered code.
+func (m *Morton) component(code uint64, i uint8) (v uint32) {
+	if m.interleaver != nil {
+		v = m.interleaver.Compact(code, i)
+	} else {
+		v = Undilate(code>>i, m.Dimensions)
 	}
 
+	if m.gray {
+		v = fromGray(v)
+	}
 	return
 }
 
+// Decodes only the components named by dims, in that order, into dst, which must be as long as dims.  Extracting a minority of the dimensions, e.g. the spatial part of a spatio-temporal key, costs only as many compactions.
+func (m *Morton) DecodeDims(code uint64, dims []uint8, dst []uint32) error {
+	if len(dst) != len(dims) {
+		return ErrDimensionMismatch
+	}
+	for _, d := range dims {
+		if d >= m.Dimensions {
+			return errors.New(fmt.Sprint("Dimension ", d, " exceeds the number of dimensions"))
+		}
+	}
+
+	if m.scatter {
+		code = ReverseBits(code, m.codeBits())
+	}
+	for i, d := range dims {
+		dst[i] = m.component(code, d)
+	}
+	return nil
+}
+
 func (m *Morton) Encode(vector []uint32) (result uint64, err error) {
 	length := len(m.Tables)
 	if length == 0 {
@@ -187,57 +623,172 @@ func (m *Morton) Encode(vector []uint32) (result uint64, err error) {
 	//sort.Sort(sort.Reverse(ByUint32Index(vector)))
 
 	for k, v := range vector {
-		if v > uint32(len(m.Tables[k].Encode)-1) {
-			err = errors.New(fmt.Sprint("Input vector component, ", k, " length exceeds the corresponding lookup table's size.  Please regenerate them via CreateTables() and specify the appropriate table length"))
+		// Mapped tables may have holes, see MapCoords
+		b, ok := m.Tables[k].lookup(v)
+		switch {
+		case !ok && v >= m.Tables[k].Length:
+			err = fmt.Errorf("%w.  Component %v; please regenerate them via CreateTables() and specify the appropriate table length", ErrComponentOverflow, k)
+			return
+		case !ok:
+			err = fmt.Errorf("%w.  Component %v value %v is not in the mapped table", ErrComponentOverflow, k, v)
 			return
 		}
 
-		result |= m.Tables[k].Encode[v].Value
+		result |= b.Value
+	}
+
+	if m.scatter {
+		result = ReverseBits(result, m.codeBits())
 	}
 
 	return
 }
 
-func CreateTable(index, dimensions uint8, length uint32) Table {
-	t := Table{Index: index, Length: length}
-	bch := make(chan Bit)
+// Encode, panicking on error.  For init() and test code, whose coordinates are known to be in range.
+func (m *Morton) MustEncode(vector []uint32) uint64 {
+	code, err := m.Encode(vector)
+	if err != nil {
+		panic(err)
+	}
+	return code
+}
 
-	// Build interleave queue
-	for i := uint32(0); i < length; i++ {
-		go func(i uint32) {
-			bch <- InterleaveBits(i, uint32(index), uint32(dimensions-1))
-		}(i)
+// Encodes each vector, panicking on the first error.  For init() and test code only, as MustEncode.
+func (m *Morton) MustEncodeMany(vectors [][]uint32) []uint64 {
+	codes := make([]uint64, len(vectors))
+	for i, v := range vectors {
+		code, err := m.Encode(v)
+		if err != nil {
+			panic(fmt.Errorf("Vector %v: %w", i, err))
+		}
+		codes[i] = code
 	}
-	// Pull from interleave queue
-	for i := uint32(0); i < length; i++ {
-		ib := <-bch
-		t.Encode = append(t.Encode, ib)
+	return codes
+}
+
+// Encode, for codes fitting in 32 bits, e.g. 2D grids of up to 4096 x 4096 as compact keys.
+func (m *Morton) Encode32(vector []uint32) (uint32, error) {
+	code, err := m.Encode(vector)
+	if err != nil {
+		return 0, err
 	}
-	close(bch)
+	if code > math.MaxUint32 {
+		return 0, fmt.Errorf("%w.  Code 0x%x does not fit in 32 bits", ErrComponentOverflow, code)
+	}
+	return uint32(code), nil
+}
 
-	sort.Sort(ByBit(t.Encode))
+// Decodes a code produced by Encode32.
+func (m *Morton) Decode32(code uint32) []uint32 {
+	return m.Decode(uint64(code))
+}
+
+// Encodes vector, saturating each component to its dimension's maximum, e.g. for culling margins beyond the grid.  Lossy: every out of range component encodes as the maximum.  Components beyond the number of tables are ignored, and there is no error; strict callers should use Encode.
+func (m *Morton) EncodeClamp(vector []uint32) uint64 {
+	return m.encodeTotal(vector, func(v, length uint32) uint32 {
+		if v >= length {
+			return length - 1
+		}
+		return v
+	})
+}
+
+// Encodes vector, wrapping each component modulo its table length, i.e., masking it to the bit budget for power of two lengths, e.g. for periodic simulations.  Lossy, as EncodeClamp.
+func (m *Morton) EncodeWrap(vector []uint32) uint64 {
+	return m.encodeTotal(vector, func(v, length uint32) uint32 {
+		return v % length
+	})
+}
+
+// Encodes vector after fitting each component into its table via fit, without failing.
+func (m *Morton) encodeTotal(vector []uint32, fit func(v, length uint32) uint32) (result uint64) {
+	if len(vector) > len(m.Tables) {
+		vector = vector[:len(m.Tables)]
+	}
+	for k, v := range vector {
+		t := m.Tables[k]
+		if len(t.Encode) == 0 || t.Length == 0 {
+			continue
+		}
+		// Holes of mapped tables fall to the nearest index below
+		result |= t.floor(fit(v, t.Length)).Value
+	}
+	if m.scatter {
+		result = ReverseBits(result, m.codeBits())
+	}
+	return
+}
+
+// Encodes only the dimensions selected by dimMask (bit i set includes dimension i); excluded components are neither validated nor encoded, contributing zero bits.  Codes that agree on the selected dimensions are therefore equal.
+func (m *Morton) EncodeWithMask(vector []uint32, dimMask uint64) (uint64, error) {
+	if m.Dimensions < 64 && dimMask>>m.Dimensions != 0 {
+		return 0, errors.New("Dimension mask selects dimensions beyond the number of dimensions")
+	}
+
+	masked := make([]uint32, len(vector))
+	for k, v := range vector {
+		if k < 64 && dimMask&(1<<uint(k)) != 0 {
+			masked[k] = v
+		}
+	}
+	return m.Encode(masked)
+}
+
+// Encodes coords, which maps dimension index to value; missing dimensions are 0.
+func (m *Morton) SparseEncode(coords map[int]uint32) (uint64, error) {
+	vector := make([]uint32, m.Dimensions)
+	for k, v := range coords {
+		if k < 0 || k >= len(vector) {
+			return 0, errors.New(fmt.Sprint("Sparse dimension ", k, " exceeds the number of dimensions"))
+		}
+		vector[k] = v
+	}
+	return m.Encode(vector)
+}
+
+func CreateTable(index, dimensions uint8, length uint32) Table {
+	return createTable(index, dimensions, length, nil)
+}
+
+// Creates a table whose entries are spread(i), rather than the interleaved bits of i, when spread is non-nil.  Entries are computed in a single loop, already in index order; a table of 2^21 entries takes milliseconds and 32 MiB.
+func createTable(index, dimensions uint8, length uint32, spread func(uint32) uint64) Table {
+	t := Table{Index: index, Length: length, Encode: make([]Bit, length)}
+	for i := range t.Encode {
+		v := uint32(i)
+		if spread == nil {
+			t.Encode[i] = InterleaveBits64(uint64(v), uint32(index), uint32(dimensions-1))
+			continue
+		}
+		t.Encode[i] = Bit{v, spread(v)}
+	}
 	return t
 }
 
-// Interleave bits of a uint32.
+// Interleave bits of a uint32, spread bits apart, then shifted by offset.  See Dilate.
 func InterleaveBits(value, offset, spread uint32) Bit {
-	ib := Bit{value, 0}
+	return InterleaveBits64(uint64(value), offset, spread)
+}
 
-	// Determine the minimum number of single shifts required. There's likely a better, and more efficient, way to do this.
-	n := value
-	limit := uint64(0)
-	for i := uint32(0); n != 0; i++ {
-		n = n >> 1
-		limit++
+// Interleave bits of a uint64, as InterleaveBits: bit k of value moves to bit k*(spread+1) + offset.  The caller is responsible for the spread value fitting, i.e., bits.Len64(value) * (spread+1) + offset <= 64; bits landing beyond bit 63 are dropped, never wrapped.  Since any spread leaves room for at most 32 bits, values wider than 32 bits only survive whole with a spread of 0.  The resulting Bit's Index holds the low 32 bits of value, as tables are indexed by uint32.  Encode's tables are built with it.
+func InterleaveBits64(value uint64, offset, spread uint32) Bit {
+	ib := Bit{uint32(value), 0}
+	if offset >= 64 {
+		return ib
 	}
 
-	// Offset value for interleaving and reconcile types
-	v, o, s := uint64(value), uint64(offset), uint64(spread)
-	for i := uint64(0); i < limit; i++ {
-		// Interleave bits, bit by bit.
-		ib.Value |= (v & (1 << i)) << (i * s)
+	switch {
+	case spread == 0:
+		ib.Value = value
+	case spread < 64 && value>>32 == 0:
+		ib.Value = Dilate(uint32(value), uint8(spread+1))
+	default:
+		// Bit by bit, up to the last that fits
+		step := uint64(spread) + 1
+		for k := uint64(0); k*step < 64 && value>>k != 0; k++ {
+			ib.Value |= (value >> k & 1) << (k * step)
+		}
 	}
-	ib.Value = ib.Value << o
+	ib.Value = ib.Value << uint64(offset)
 
 	return ib
 }
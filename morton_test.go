@@ -0,0 +1,746 @@
+package morton
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAutoCreate(t *testing.T) {
+	m := new(Morton)
+	if err := m.AutoCreate([]uint32{3, 200, 17}); err != nil {
+		t.Fatal(err)
+	}
+	if m.Dimensions != 3 {
+		t.Fatalf("got %v dimensions, want 3", m.Dimensions)
+	}
+	for i, tb := range m.Tables {
+		if tb.Length != 201 {
+			t.Errorf("table %v has length %v, want 201", i, tb.Length)
+		}
+	}
+	code, err := m.Encode([]uint32{3, 200, 17})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := m.Decode(code); v[0] != 3 || v[1] != 200 || v[2] != 17 {
+		t.Errorf("round trip gave %v", v)
+	}
+}
+
+func TestAutoCreateLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		maxCoords []uint32
+	}{
+		{"no dimensions", nil},
+		{"MaxUint32", []uint32{1, math.MaxUint32}},
+		{"beyond the table limit", []uint32{MaxAutoTableSize}},
+	} {
+		if err := new(Morton).AutoCreate(tc.maxCoords); err == nil {
+			t.Errorf("%v: AutoCreate succeeded, want an error", tc.name)
+		}
+	}
+
+	m := new(Morton)
+	if err := m.AutoCreate([]uint32{MaxAutoTableSize - 1}); err != nil {
+		t.Fatalf("largest table: %v", err)
+	}
+	if m.Tables[0].Length != MaxAutoTableSize {
+		t.Errorf("got length %v, want %v", m.Tables[0].Length, MaxAutoTableSize)
+	}
+	if err := new(Morton).AutoCreate([]uint32{1 << 20, 1 << 20, 1 << 20, 1 << 20}); !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("4 dimensions of 21 bits: got %v, want ErrCapacityExceeded", err)
+	}
+}
+
+func TestHashCode(t *testing.T) {
+	// Pinned, so that hashes persisted by one build match the next
+	for _, tc := range []struct {
+		m    *Morton
+		want uint64
+	}{
+		{New(2, 16), 0xefcf7dac5fe092c8},
+		{New(3, 8, WithGrayCode()), 0xed8aa84cf6fb1c82},
+	} {
+		if got := tc.m.HashCode(); got != tc.want {
+			t.Errorf("HashCode of %v dimensions = %#x, want %#x", tc.m.Dimensions, got, tc.want)
+		}
+	}
+
+	if a, b := New(3, 64, WithShardScatter()).HashCode(), New(3, 64, WithShardScatter()).HashCode(); a != b {
+		t.Errorf("Mortons created alike hash to %#x and %#x", a, b)
+	}
+	// Color only changes the display, not the codes
+	if a, b := New(2, 16).HashCode(), New(2, 16, WithColor(true)).HashCode(); a != b {
+		t.Errorf("Mortons differing only in WithColor hash to %#x and %#x", a, b)
+	}
+	if a, b := New(3, 8, WithGrayCode()).HashCode(), New(3, 8, WithGrayCode(), WithColor(true)).HashCode(); a != b {
+		t.Errorf("Gray coded Mortons differing only in WithColor hash to %#x and %#x", a, b)
+	}
+}
+
+func TestHashCodeDiffers(t *testing.T) {
+	mapped, err := New(2, 16).MapCoords(func(in []uint32) []uint32 {
+		return []uint32{2 * in[0], 2 * in[1]}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bpd, err := New(2, 16).WithBitsPerDimension([]uint8{3, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scales := []float64{2, 2}
+	configs := map[string]*Morton{
+		"default":      New(2, 16),
+		"3D":           New(3, 16),
+		"length":       New(2, 32),
+		"gray":         New(2, 16, WithGrayCode()),
+		"scatter":      New(2, 16, WithShardScatter()),
+		"interleaver":  New(2, 16, WithInterleaver(reversedInterleaver{2})),
+		"time unit":    New(2, 16, WithTimeUnit(time.Second)),
+		"transform":    New(2, 16, WithTransform(nil, scales)),
+		"rotation":     New(2, 16, WithTransform(nil, scales), WithRotation(1)),
+		"mapped":       mapped,
+		"bits 3 and 5": bpd,
+	}
+	seen := make(map[uint64]string)
+	for name, m := range configs {
+		h := m.HashCode()
+		if other, ok := seen[h]; ok {
+			t.Errorf("%v and %v both hash to %#x", name, other, h)
+		}
+		seen[h] = name
+	}
+}
+
+// Bit by bit reference for InterleaveBits64.
+func referenceInterleave(value uint64, offset, spread uint32) (code uint64) {
+	for k := uint64(0); k < 64; k++ {
+		if pos := k*(uint64(spread)+1) + uint64(offset); pos < 64 {
+			code |= (value >> k & 1) << pos
+		}
+	}
+	return
+}
+
+func TestInterleaveBits64(t *testing.T) {
+	r := rand.New(rand.NewSource(111))
+	for n := 0; n < 2000; n++ {
+		value := r.Uint64() >> uint(r.Intn(64))
+		offset, spread := uint32(r.Intn(8)), uint32(r.Intn(66))
+		got := InterleaveBits64(value, offset, spread)
+		if want := referenceInterleave(value, offset, spread); got.Value != want || got.Index != uint32(value) {
+			t.Fatalf("InterleaveBits64(%#x, %v, %v) = %#x, want %#x", value, offset, spread, got.Value, want)
+		}
+		if v32 := uint32(value); InterleaveBits(v32, offset, spread) != InterleaveBits64(uint64(v32), offset, spread) {
+			t.Fatalf("InterleaveBits and InterleaveBits64 differ for %#x, %v, %v", v32, offset, spread)
+		}
+	}
+
+	// Wide values survive whole only without a spread
+	if got := InterleaveBits64(math.MaxUint64, 0, 0).Value; got != math.MaxUint64 {
+		t.Errorf("InterleaveBits64 of all ones without spread = %#x", got)
+	}
+	if got := InterleaveBits64(1<<40|1, 0, 1).Value; got != 1 {
+		t.Errorf("InterleaveBits64 kept bit 40 spread beyond the code: %#x", got)
+	}
+	if got := InterleaveBits64(1, 64, 0).Value; got != 0 {
+		t.Errorf("InterleaveBits64 with offset 64 = %#x, want 0", got)
+	}
+}
+
+func TestEncodeInterleaves(t *testing.T) {
+	m := New(3, 1<<10)
+	r := rand.New(rand.NewSource(7))
+	for n := 0; n < 500; n++ {
+		v := []uint32{uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10))}
+		var want uint64
+		for i, c := range v {
+			want |= InterleaveBits64(uint64(c), uint32(i), 2).Value
+		}
+		if got, err := m.Encode(v); err != nil || got != want {
+			t.Fatalf("Encode(%v) = %#x, %v; want %#x", v, got, err, want)
+		}
+	}
+}
+
+func TestDecodeDims(t *testing.T) {
+	for _, m := range []*Morton{New(5, 16), New(5, 16, WithGrayCode(), WithShardScatter())} {
+		code, err := m.Encode([]uint32{3, 14, 0, 9, 15})
+		if err != nil {
+			t.Fatal(err)
+		}
+		full := m.Decode(code)
+		// Every subset of the 5 dimensions, in ascending and descending order
+		for set := 1; set < 1<<5; set++ {
+			var dims []uint8
+			for d := uint8(0); d < 5; d++ {
+				if set&(1<<d) != 0 {
+					dims = append(dims, d)
+				}
+			}
+			for _, order := range [][]uint8{dims, reversed(dims)} {
+				dst := make([]uint32, len(order))
+				if err := m.DecodeDims(code, order, dst); err != nil {
+					t.Fatal(err)
+				}
+				for i, d := range order {
+					if dst[i] != full[d] {
+						t.Fatalf("DecodeDims(%v) = %v, but Decode gives %v", order, dst, full)
+					}
+				}
+			}
+		}
+	}
+}
+
+func reversed(s []uint8) []uint8 {
+	r := make([]uint8, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
+
+func TestDecodeDimsRejects(t *testing.T) {
+	m := New(5, 16)
+	if err := m.DecodeDims(0, []uint8{0, 1}, make([]uint32, 1)); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("DecodeDims into a short dst returned %v, want ErrDimensionMismatch", err)
+	}
+	if err := m.DecodeDims(0, []uint8{5}, make([]uint32, 1)); err == nil {
+		t.Error("DecodeDims of dimension 5 succeeded")
+	}
+}
+
+func BenchmarkDecodeDims(b *testing.B) {
+	m := New(5, 1024)
+	code, _ := m.Encode([]uint32{100, 200, 300, 400, 500})
+	dims, dst := []uint8{1, 3}, make([]uint32, 2)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.DecodeDims(code, dims, dst)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	m := New(5, 1024)
+	code, _ := m.Encode([]uint32{100, 200, 300, 400, 500})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Decode(code)
+	}
+}
+
+// Golden vectors from libmorton's 64 bit encoders; see testdata/libmorton/gen.c.
+func TestLibmortonVectors(t *testing.T) {
+	f, err := os.Open("testdata/libmorton_vectors.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// Tables of 16 bits, for the vectors that fit them
+	mortons := map[int]*Morton{2: New(2, 1<<16), 3: New(3, 1<<16)}
+	counts := make(map[int]int)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		var numbers []uint64
+		for _, field := range fields {
+			n, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				t.Fatalf("line %v: %v", line, err)
+			}
+			numbers = append(numbers, n)
+		}
+		d := int(numbers[0])
+		if len(numbers) != d+2 || mortons[d] == nil {
+			t.Fatalf("line %v: malformed vector %q", line, scanner.Text())
+		}
+		counts[d]++
+
+		vector, want := make([]uint32, d), numbers[d+1]
+		var code uint64
+		fits := true
+		for i := range vector {
+			vector[i] = uint32(numbers[i+1])
+			code |= Dilate(vector[i], uint8(d)) << uint(i)
+			fits = fits && vector[i] < 1<<16
+		}
+		if code != want {
+			t.Errorf("line %v: dilating %v gives %v, libmorton %v", line, vector, code, want)
+		}
+		for i, v := range vector {
+			if got := Undilate(want>>uint(i), uint8(d)); got != v {
+				t.Errorf("line %v: undilating component %v of %v gives %v, want %v", line, i, want, got, v)
+			}
+		}
+		if !fits {
+			continue
+		}
+		m := mortons[d]
+		if code, err := m.Encode(vector); err != nil || code != want {
+			t.Errorf("line %v: Encode(%v) = %v, %v; libmorton %v", line, vector, code, err, want)
+		}
+		if got := m.Decode(want); !equalUint32s(got, vector) {
+			t.Errorf("line %v: Decode(%v) = %v, want %v", line, want, got, vector)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if counts[2] < 100 || counts[3] < 100 {
+		t.Errorf("read %v 2D and %v 3D vectors, want at least 100 of each", counts[2], counts[3])
+	}
+}
+
+func TestEncodeWithMask(t *testing.T) {
+	m := New(3, 16)
+	// Dimension 1 masked out
+	got, err := m.EncodeWithMask([]uint32{3, 9, 5}, 0b101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := m.Encode([]uint32{3, 0, 5}); got != want {
+		t.Errorf("EncodeWithMask of {3, 9, 5} without y = %v, want %v", got, want)
+	}
+	if got&MaskForDimension(3, 1) != 0 {
+		t.Errorf("EncodeWithMask left bits %#x in the masked lane", got&MaskForDimension(3, 1))
+	}
+	// Excluded components are not validated
+	if other, err := m.EncodeWithMask([]uint32{3, 100, 5}, 0b101); err != nil || other != got {
+		t.Errorf("EncodeWithMask with an overflowing masked component = %v, %v; want %v", other, err, got)
+	}
+	if all, _ := m.EncodeWithMask([]uint32{3, 9, 5}, 0b111); all != m.MustEncode([]uint32{3, 9, 5}) {
+		t.Errorf("EncodeWithMask of every dimension = %v, want the full code", all)
+	}
+	if none, err := m.EncodeWithMask([]uint32{3, 9, 5}, 0); err != nil || none != 0 {
+		t.Errorf("EncodeWithMask of no dimensions = %v, %v; want 0", none, err)
+	}
+
+	// Spatial codes agree across times
+	st := New(4, 16)
+	a, _ := st.EncodeWithMask([]uint32{1, 2, 3, 4}, 0b0111)
+	b, _ := st.EncodeWithMask([]uint32{1, 2, 3, 11}, 0b0111)
+	if a != b {
+		t.Errorf("codes masked to space differ: %v and %v", a, b)
+	}
+
+	if _, err := m.EncodeWithMask([]uint32{1, 2, 3}, 0b1001); err == nil {
+		t.Error("EncodeWithMask selecting dimension 3 of 3 succeeded")
+	}
+	if _, err := m.EncodeWithMask([]uint32{16, 2, 3}, 0b1); err == nil {
+		t.Error("EncodeWithMask of an overflowing selected component succeeded")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	m := new(Morton)
+	if err := m.AutoCreate([]uint32{1000, 1000}); err != nil {
+		t.Fatal(err)
+	}
+	// Drop the entries beyond 40, as if loaded from data that never used them
+	for i := range m.Tables {
+		m.Tables[i].Encode = m.Tables[i].Encode[:41]
+	}
+	c, err := m.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tb := range c.Tables {
+		if tb.Length != 41 {
+			t.Errorf("compacted table %v has length %v, want 41", i, tb.Length)
+		}
+	}
+	for x := uint32(0); x <= 40; x++ {
+		for y := uint32(0); y <= 40; y++ {
+			v := []uint32{x, y}
+			if got, want := c.MustEncode(v), m.MustEncode(v); got != want {
+				t.Fatalf("compacted Encode(%v) = %v, want %v", v, got, want)
+			}
+		}
+	}
+	if _, err := c.Encode([]uint32{41, 0}); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("compacted Encode beyond the new size returned %v, want ErrComponentOverflow", err)
+	}
+
+	// Options carry over
+	g, err := New(2, 64, WithGrayCode()).Compact()
+	if err != nil || !g.gray {
+		t.Errorf("Compact of a Gray coded Morton = %+v, %v", g, err)
+	}
+	if _, err := new(Morton).Compact(); err == nil {
+		t.Error("Compact without tables succeeded")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	for _, m := range []*Morton{New(3, 512), New(2, 100), New(6, 4), new(Morton)} {
+		s := m.Summary()
+		var dims uint8
+		var size, capacity uint32
+		var maxCode, memBytes uint64
+		n, err := fmt.Sscanf(s, "Morton{dims=%d, size=%d, capacity=%d, maxCode=0x%x, memBytes=%d}", &dims, &size, &capacity, &maxCode, &memBytes)
+		if err != nil || n != 5 {
+			t.Fatalf("Sscanf(%q) parsed %v fields: %v", s, n, err)
+		}
+		if dims != m.Dimensions || capacity != m.Capacity() || maxCode != m.MaxCode() || memBytes != m.MemoryEstimate() {
+			t.Errorf("Summary() = %q, want dims %v, capacity %v, maxCode %v, memBytes %v", s, m.Dimensions, m.Capacity(), m.MaxCode(), m.MemoryEstimate())
+		}
+		if len(m.Tables) > 0 && size != m.Tables[0].Length {
+			t.Errorf("Summary() = %q, want size %v", s, m.Tables[0].Length)
+		}
+	}
+}
+
+func TestBitComparisons(t *testing.T) {
+	for _, tc := range []struct {
+		a, b         Bit
+		equals, less bool
+	}{
+		{Bit{3, 5}, Bit{3, 5}, true, false},
+		{Bit{3, 5}, Bit{3, 6}, false, true},
+		{Bit{3, 6}, Bit{3, 5}, false, false},
+		{Bit{2, 9}, Bit{3, 0}, false, true},
+		{Bit{4, 0}, Bit{3, 9}, false, false},
+		{Bit{}, Bit{}, true, false},
+	} {
+		if got := tc.a.Equals(tc.b); got != tc.equals {
+			t.Errorf("%v.Equals(%v) = %v", tc.a, tc.b, got)
+		}
+		if got := tc.a.Less(tc.b); got != tc.less {
+			t.Errorf("%v.Less(%v) = %v", tc.a, tc.b, got)
+		}
+	}
+
+	if !(Bit{}).IsZero() {
+		t.Error("The zero Bit is not IsZero")
+	}
+	for _, b := range []Bit{{1, 0}, {0, 1}} {
+		if b.IsZero() {
+			t.Errorf("%v is IsZero", b)
+		}
+	}
+}
+
+func TestBitSlice(t *testing.T) {
+	bits := BitSlice{{2, 1}, {1, 7}, {2, 0}, {0, 3}, {1, 2}}
+	sort.Sort(bits)
+	want := BitSlice{{0, 3}, {1, 2}, {1, 7}, {2, 0}, {2, 1}}
+	for i := range want {
+		if !bits[i].Equals(want[i]) {
+			t.Fatalf("sorted BitSlice = %v, want %v", bits, want)
+		}
+	}
+}
+
+func TestTableMap(t *testing.T) {
+	m := New(3, 100)
+	tb := m.Tables[1]
+	if got := tb.Map(func(b Bit) Bit { return b }); !got.Equal(tb) || got.Index != tb.Index || got.Length != tb.Length {
+		t.Errorf("Map(identity) is not Equal to the table")
+	}
+
+	shifted := tb.Map(func(b Bit) Bit { b.Value <<= 1; return b })
+	if shifted.Length != tb.Length || shifted.Index != tb.Index {
+		t.Errorf("Map changed the metadata to %v, %v", shifted.Index, shifted.Length)
+	}
+	for i, b := range shifted.Encode {
+		if b.Value != tb.Encode[i].Value<<1 || b.Index != tb.Encode[i].Index {
+			t.Fatalf("Map of entry %v gave %v, want the value of %v shifted", i, b, tb.Encode[i])
+		}
+	}
+	if &shifted.Encode[0] == &tb.Encode[0] {
+		t.Error("Map shares its entries with the original")
+	}
+}
+
+func TestTableFilter(t *testing.T) {
+	tb := New(2, 10).Tables[0]
+	if got := tb.Filter(func(Bit) bool { return false }); got.Length != 0 || len(got.Encode) != 0 {
+		t.Errorf("Filter(always false) has Length %v and %v entries", got.Length, len(got.Encode))
+	}
+	if got := tb.Filter(func(Bit) bool { return true }); !got.Equal(tb) {
+		t.Error("Filter(always true) is not Equal to the table")
+	}
+	even := tb.Filter(func(b Bit) bool { return b.Index%2 == 0 })
+	if even.Length != 5 || len(even.Encode) != 5 || even.Index != tb.Index {
+		t.Fatalf("Filter of even indices has Length %v and %v entries", even.Length, len(even.Encode))
+	}
+	for _, b := range even.Encode {
+		if b.Index%2 != 0 {
+			t.Errorf("Filter of even indices kept %v", b)
+		}
+	}
+}
+
+func TestTableInvert(t *testing.T) {
+	m := New(3, 64)
+	for _, tb := range m.Tables {
+		inv, err := tb.Invert()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range tb.Encode {
+			found, ok := inv.Search(uint32(b.Value))
+			if !ok || found.Value != uint64(b.Index) {
+				t.Fatalf("table %v: inverted entry for %v = %v, %v", tb.Index, b, found, ok)
+			}
+		}
+	}
+
+	// Decoding via the inverted tables matches Decode
+	inverted := make([]Table, len(m.Tables))
+	for i, tb := range m.Tables {
+		inverted[i], _ = tb.Invert()
+	}
+	codes, vectors := allCodes(t, m)
+	for n, code := range codes {
+		for i, tb := range inverted {
+			b, ok := tb.Search(uint32(code & MaskForDimension(3, uint8(i))))
+			if !ok || uint32(b.Value) != vectors[n][i] || m.Decode(code)[i] != vectors[n][i] {
+				t.Fatalf("inverted table %v decodes %v to %v, %v; want %v", i, code, b.Value, ok, vectors[n][i])
+			}
+		}
+	}
+
+	dup := Table{Length: 2, Encode: []Bit{{0, 5}, {1, 5}}}
+	if _, err := dup.Invert(); err == nil {
+		t.Error("Invert of duplicated values succeeded")
+	}
+	wide := Table{Length: 1, Encode: []Bit{{0, 1 << 33}}}
+	if _, err := wide.Invert(); err == nil {
+		t.Error("Invert of a value beyond 32 bits succeeded")
+	}
+}
+
+func TestSparseEncode(t *testing.T) {
+	m := New(8, 256)
+	r := rand.New(rand.NewSource(121))
+	for n := 0; n < 300; n++ {
+		coords := make(map[int]uint32)
+		dense := make([]uint32, 8)
+		for k := r.Intn(4); k > 0; k-- {
+			dim, v := r.Intn(8), uint32(r.Intn(256))
+			coords[dim], dense[dim] = v, v
+		}
+		got, err := m.SparseEncode(coords)
+		if err != nil {
+			t.Fatalf("SparseEncode(%v): %v", coords, err)
+		}
+		if want, _ := m.Encode(dense); got != want {
+			t.Fatalf("SparseEncode(%v) = %v, want Encode(%v) = %v", coords, got, dense, want)
+		}
+	}
+	if code, err := m.SparseEncode(nil); err != nil || code != 0 {
+		t.Errorf("SparseEncode(nil) = %v, %v", code, err)
+	}
+
+	for _, coords := range []map[int]uint32{{8: 1}, {-1: 1}, {3: 256}} {
+		if _, err := m.SparseEncode(coords); err == nil {
+			t.Errorf("SparseEncode(%v) succeeded", coords)
+		}
+	}
+}
+
+func TestEncode32(t *testing.T) {
+	m := New(2, 4096)
+	v := make([]uint32, 2)
+	for v[0] = 0; v[0] < 4096; v[0]++ {
+		for v[1] = 0; v[1] < 4096; v[1]++ {
+			code, err := m.Encode32(v)
+			if err != nil {
+				t.Fatalf("Encode32(%v): %v", v, err)
+			}
+			if want, _ := m.Encode(v); uint64(code) != want {
+				t.Fatalf("Encode32(%v) = %v, want %v", v, code, want)
+			}
+			if got := m.Decode32(code); got[0] != v[0] || got[1] != v[1] {
+				t.Fatalf("Decode32(Encode32(%v)) = %v", v, got)
+			}
+		}
+	}
+}
+
+func TestEncode32Rejects(t *testing.T) {
+	// 17 bits per dimension need up to 34 code bits
+	m := New(2, 1<<17)
+	if code, err := m.Encode32([]uint32{1<<16 - 1, 1<<16 - 1}); err != nil || code != math.MaxUint32 {
+		t.Errorf("Encode32 of the largest 32 bit code = %v, %v", code, err)
+	}
+	for _, v := range [][]uint32{{1 << 16, 0}, {0, 1 << 16}, {1<<17 - 1, 1<<17 - 1}} {
+		if _, err := m.Encode32(v); !errors.Is(err, ErrComponentOverflow) {
+			t.Errorf("Encode32(%v) returned %v, want ErrComponentOverflow", v, err)
+		}
+	}
+	if _, err := New(2, 4096).Encode32([]uint32{4096, 0}); err == nil {
+		t.Error("Encode32 beyond the tables succeeded")
+	}
+}
+
+func TestSpaceEstimate(t *testing.T) {
+	for _, tc := range []struct {
+		dims uint8
+		size uint32
+	}{{2, 256}, {3, 512}, {4, 100}, {8, 16}, {1, 1}} {
+		m := New(tc.dims, tc.size)
+		tables, index := SpaceEstimate(1000, tc.dims, tc.size-1)
+		if want := m.MemoryEstimate(); tables != want {
+			t.Errorf("SpaceEstimate tables for %v dimensions of %v = %v, want MemoryEstimate() = %v", tc.dims, tc.size, tables, want)
+		}
+		if index != 8000 {
+			t.Errorf("SpaceEstimate index of 1000 points = %v, want 8000", index)
+		}
+	}
+	if _, index := SpaceEstimate(-5, 2, 10); index != 0 {
+		t.Errorf("SpaceEstimate index of -5 points = %v, want 0", index)
+	}
+}
+
+// Calls fn, returning what it panicked with, if anything.
+func panicked(fn func()) (p any) {
+	defer func() { p = recover() }()
+	fn()
+	return
+}
+
+func TestMustEncode(t *testing.T) {
+	m := New(3, 16)
+	if got, want := m.MustEncode([]uint32{1, 2, 3}), uint64(53); got != want {
+		t.Errorf("MustEncode([1 2 3]) = %v, want %v", got, want)
+	}
+	for _, v := range [][]uint32{{16, 0, 0}, {1, 2, 3, 4}} {
+		p := panicked(func() { m.MustEncode(v) })
+		if err, ok := p.(error); !ok {
+			t.Errorf("MustEncode(%v) panicked with %v, want an error", v, p)
+		} else if _, want := m.Encode(v); err.Error() != want.Error() {
+			t.Errorf("MustEncode(%v) panicked with %q, want Encode's %q", v, err, want)
+		}
+	}
+}
+
+func TestMustEncodeMany(t *testing.T) {
+	m := New(3, 16)
+	vectors := [][]uint32{{1, 2, 3}, {0, 0, 0}, {15, 15, 15}}
+	codes := m.MustEncodeMany(vectors)
+	for i, v := range vectors {
+		if want, _ := m.Encode(v); codes[i] != want {
+			t.Errorf("MustEncodeMany gave %v at %v, want %v", codes[i], i, want)
+		}
+	}
+	if got := m.MustEncodeMany(nil); len(got) != 0 {
+		t.Errorf("MustEncodeMany(nil) = %v", got)
+	}
+
+	p := panicked(func() { m.MustEncodeMany([][]uint32{{1, 2, 3}, {1, 16, 0}, {99, 0, 0}}) })
+	err, ok := p.(error)
+	if !ok || !errors.Is(err, ErrComponentOverflow) || !strings.HasPrefix(err.Error(), "Vector 1") {
+		t.Errorf("MustEncodeMany panicked with %v, want the error of vector 1", p)
+	}
+}
+
+func TestEncodeClampWrap(t *testing.T) {
+	for _, m := range []*Morton{New(2, 16), New(2, 10), New(2, 10, WithGrayCode())} {
+		length := m.Tables[0].Length
+		max := length - 1
+		for _, tc := range []struct {
+			name         string
+			x            uint32
+			clamp, wraps uint32
+		}{
+			{"zero", 0, 0, 0},
+			{"at max", max, max, max},
+			{"one past max", length, max, 0},
+			{"two past max", length + 1, max, 1},
+			{"far past max", 7*length + 3, max, 3},
+			{"the largest component", math.MaxUint32, max, math.MaxUint32 % length},
+		} {
+			v := []uint32{tc.x, 5}
+			if got, want := m.EncodeClamp(v), m.MustEncode([]uint32{tc.clamp, 5}); got != want {
+				t.Errorf("length %v: EncodeClamp %v = %v, want %v", length, tc.name, got, want)
+			}
+			if got, want := m.EncodeWrap(v), m.MustEncode([]uint32{tc.wraps, 5}); got != want {
+				t.Errorf("length %v: EncodeWrap %v = %v, want %v", length, tc.name, got, want)
+			}
+		}
+
+		// In range vectors encode as Encode does
+		r := rand.New(rand.NewSource(147))
+		for n := 0; n < 200; n++ {
+			v := []uint32{uint32(r.Intn(int(length))), uint32(r.Intn(int(length)))}
+			if want := m.MustEncode(v); m.EncodeClamp(v) != want || m.EncodeWrap(v) != want {
+				t.Fatalf("length %v: EncodeClamp and EncodeWrap of %v differ from Encode", length, v)
+			}
+		}
+	}
+
+	// Total functions: extra components are ignored
+	m := New(2, 16)
+	if got := m.EncodeClamp([]uint32{1, 2, 99}); got != m.MustEncode([]uint32{1, 2}) {
+		t.Errorf("EncodeClamp of 3 components = %v", got)
+	}
+	if got := new(Morton).EncodeWrap([]uint32{1, 2}); got != 0 {
+		t.Errorf("EncodeWrap without tables = %v, want 0", got)
+	}
+}
+
+func TestRecommendTableSize(t *testing.T) {
+	m := new(Morton)
+	for _, tc := range []struct{ max, want uint32 }{
+		{0, 1},
+		{1, 2},
+		{2, 4},
+		{3, 4},
+		{255, 256},
+		{256, 512},
+		{1<<20 - 1, 1 << 20},
+		{1<<31 - 1, 1 << 31},
+		// No larger power of two fits in a uint32
+		{1 << 31, 1 << 31},
+		{math.MaxUint32, 1 << 31},
+	} {
+		if got := m.RecommendTableSize(tc.max); got != tc.want {
+			t.Errorf("RecommendTableSize(%v) = %v, want %v", tc.max, got, tc.want)
+		}
+	}
+
+	// Below 2^31, the recommendation is the smallest power of two encoding expectedMax
+	for max := uint32(0); max < 1<<12; max++ {
+		size := m.RecommendTableSize(max)
+		if size&(size-1) != 0 || size <= max || size > 1 && size/2 > max {
+			t.Fatalf("RecommendTableSize(%v) = %v", max, size)
+		}
+	}
+	for _, max := range []uint32{0, 5, 100} {
+		g := New(2, m.RecommendTableSize(max))
+		if _, err := g.Encode([]uint32{max, max}); err != nil {
+			t.Errorf("a grid of the recommended size fails to encode %v: %v", max, err)
+		}
+	}
+}
+
+func TestCurrentTableSize(t *testing.T) {
+	if got := new(Morton).CurrentTableSize(); got != 0 {
+		t.Errorf("CurrentTableSize without tables = %v, want 0", got)
+	}
+	for _, size := range []uint32{1, 10, 256} {
+		if got := New(3, size).CurrentTableSize(); got != size {
+			t.Errorf("CurrentTableSize of New(3, %v) = %v", size, got)
+		}
+	}
+	m := New(2, 16)
+	m.Tables[0].Length = 8
+	if got := m.CurrentTableSize(); got != 16 {
+		t.Errorf("CurrentTableSize of tables of 8 and 16 = %v, want the longest", got)
+	}
+}
@@ -0,0 +1,71 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code with component dim incremented, or false if it is already at its table's last index.  Only the one lane is extracted and replaced.
+func (m *Morton) NextInDim(code uint64, dim int) (uint64, bool) {
+	if dim < 0 || dim >= int(m.Dimensions) || dim >= len(m.Tables) {
+		return 0, false
+	}
+	d := uint8(dim)
+	v := m.Project(code, d)
+	if v+1 >= m.Tables[dim].Length {
+		return 0, false
+	}
+	return m.Inject(code, d, v+1), true
+}
+
+// Code with component dim decremented, or false if it is already 0.
+func (m *Morton) PrevInDim(code uint64, dim int) (uint64, bool) {
+	if dim < 0 || dim >= int(m.Dimensions) {
+		return 0, false
+	}
+	d := uint8(dim)
+	v := m.Project(code, d)
+	if v == 0 {
+		return 0, false
+	}
+	return m.Inject(code, d, v-1), true
+}
+
+// Calls fn for each face neighbour of code, dimension by dimension, first in the -1 direction and then in the +1, skipping steps off the tables' edges, until fn returns false.
+func (m *Morton) ForEachNeighbor(code uint64, fn func(dim int, dir int, neighbor uint64) bool) error {
+	d := int(m.Dimensions)
+	if d == 0 || len(m.Tables) < d {
+		return errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	for i := 0; i < d; i++ {
+		if v := m.Project(code, uint8(i)); v >= m.Tables[i].Length {
+			return fmt.Errorf("%w.  Component %v of code 0x%x", ErrComponentOverflow, i, code)
+		}
+	}
+
+	for i := 0; i < d; i++ {
+		if n, ok := m.PrevInDim(code, i); ok && !fn(i, -1, n) {
+			return nil
+		}
+		if n, ok := m.NextInDim(code, i); ok && !fn(i, 1, n) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Code preceding code in Z-order, or false at 0.
+func (m *Morton) Predecessor(code uint64) (uint64, bool) {
+	if code == 0 {
+		return 0, false
+	}
+	return code - 1, true
+}
+
+// Code following code in Z-order, or false at MaxCode() and beyond.  Codes whose vectors exceed a table that isn't a power of two long are not skipped.
+func (m *Morton) Successor(code uint64) (uint64, bool) {
+	if code >= m.MaxCode() {
+		return 0, false
+	}
+	return code + 1, true
+}
@@ -0,0 +1,179 @@
+package morton
+
+import (
+	"errors"
+	"testing"
+)
+
+// Moves component dim of code by delta through a full decode and encode, the baseline NextInDim and PrevInDim avoid.
+func stepByDecode(m *Morton, code uint64, dim int, delta int) (uint64, bool) {
+	v := m.Decode(code)
+	c := int64(v[dim]) + int64(delta)
+	if c < 0 || c >= int64(m.Tables[dim].Length) {
+		return 0, false
+	}
+	v[dim] = uint32(c)
+	code, err := m.Encode(v)
+	return code, err == nil
+}
+
+func TestNextPrevInDim(t *testing.T) {
+	for _, tc := range append([]struct {
+		name string
+		m    *Morton
+	}{{"default 2D", New(2, 8)}, {"default 3D of 5", New(3, 5)}}, otherLayouts(3, 4)...) {
+		codes, _ := allCodes(t, tc.m)
+		for _, code := range codes {
+			for dim := 0; dim < int(tc.m.Dimensions); dim++ {
+				want, wantOK := stepByDecode(tc.m, code, dim, 1)
+				if got, ok := tc.m.NextInDim(code, dim); got != want || ok != wantOK {
+					t.Fatalf("%v: NextInDim(%v, %v) = %v, %v; want %v, %v", tc.name, code, dim, got, ok, want, wantOK)
+				}
+				want, wantOK = stepByDecode(tc.m, code, dim, -1)
+				if got, ok := tc.m.PrevInDim(code, dim); got != want || ok != wantOK {
+					t.Fatalf("%v: PrevInDim(%v, %v) = %v, %v; want %v, %v", tc.name, code, dim, got, ok, want, wantOK)
+				}
+			}
+		}
+	}
+}
+
+func TestNextPrevInDimRejects(t *testing.T) {
+	m := New(2, 8)
+	for _, dim := range []int{-1, 2} {
+		if _, ok := m.NextInDim(0, dim); ok {
+			t.Errorf("NextInDim in dimension %v succeeded", dim)
+		}
+		if _, ok := m.PrevInDim(3, dim); ok {
+			t.Errorf("PrevInDim in dimension %v succeeded", dim)
+		}
+	}
+}
+
+func BenchmarkNextInDim(b *testing.B) {
+	m := New(3, 1024)
+	code, _ := m.Encode([]uint32{100, 200, 300})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.NextInDim(code, 1)
+	}
+}
+
+func BenchmarkStepByDecode(b *testing.B) {
+	m := New(3, 1024)
+	code, _ := m.Encode([]uint32{100, 200, 300})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stepByDecode(m, code, 1, 1)
+	}
+}
+
+func TestForEachNeighbor(t *testing.T) {
+	m := New(3, 3)
+	codes, _ := allCodes(t, m)
+	for _, code := range codes {
+		type step struct {
+			dim, dir int
+			neighbor uint64
+		}
+		var want []step
+		for dim := 0; dim < 3; dim++ {
+			for _, dir := range []int{-1, 1} {
+				if n, ok := stepByDecode(m, code, dim, dir); ok {
+					want = append(want, step{dim, dir, n})
+				}
+			}
+		}
+		var got []step
+		if err := m.ForEachNeighbor(code, func(dim, dir int, neighbor uint64) bool {
+			got = append(got, step{dim, dir, neighbor})
+			return true
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("ForEachNeighbor(%v) gave %v, want %v", code, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("ForEachNeighbor(%v) gave %v, want %v", code, got, want)
+			}
+		}
+	}
+
+	// The center of the 3x3x3 grid has all 6 face neighbours, and a corner 3
+	for _, tc := range []struct {
+		v []uint32
+		n int
+	}{{[]uint32{1, 1, 1}, 6}, {[]uint32{0, 0, 0}, 3}, {[]uint32{2, 2, 2}, 3}, {[]uint32{0, 1, 2}, 4}} {
+		n := 0
+		m.ForEachNeighbor(m.MustEncode(tc.v), func(int, int, uint64) bool {
+			n++
+			return true
+		})
+		if n != tc.n {
+			t.Errorf("%v has %v neighbours, want %v", tc.v, n, tc.n)
+		}
+	}
+}
+
+func TestForEachNeighborStops(t *testing.T) {
+	m := New(3, 3)
+	n := 0
+	if err := m.ForEachNeighbor(m.MustEncode([]uint32{1, 1, 1}), func(dim, dir int, neighbor uint64) bool {
+		n++
+		return n < 2
+	}); err != nil || n != 2 {
+		t.Errorf("ForEachNeighbor stopped after %v calls, %v; want 2", n, err)
+	}
+
+	// Code 9 has x = 3, beyond the tables; then no tables at all
+	if err := m.ForEachNeighbor(9, func(int, int, uint64) bool { return true }); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("ForEachNeighbor of a code beyond the tables returned %v", err)
+	}
+	if err := new(Morton).ForEachNeighbor(0, func(int, int, uint64) bool { return true }); err == nil {
+		t.Error("ForEachNeighbor without tables succeeded")
+	}
+}
+
+func TestPredecessorSuccessor(t *testing.T) {
+	for _, m := range []*Morton{New(2, 16), New(3, 5), New(2, 1<<16)} {
+		max := m.MaxCode()
+		for _, tc := range []struct {
+			code       uint64
+			pred, succ uint64
+			hasPred    bool
+			hasSucc    bool
+		}{
+			{0, 0, 1, false, true},
+			{1, 0, 2, true, true},
+			{max - 1, max - 2, max, true, true},
+			{max, max - 1, 0, true, false},
+			{max + 1, max, 0, true, false},
+		} {
+			if p, ok := m.Predecessor(tc.code); p != tc.pred || ok != tc.hasPred {
+				t.Errorf("MaxCode %v: Predecessor(%v) = %v, %v; want %v, %v", max, tc.code, p, ok, tc.pred, tc.hasPred)
+			}
+			if s, ok := m.Successor(tc.code); s != tc.succ || ok != tc.hasSucc {
+				t.Errorf("MaxCode %v: Successor(%v) = %v, %v; want %v, %v", max, tc.code, s, ok, tc.succ, tc.hasSucc)
+			}
+		}
+
+		step := max/1000 + 1
+		for code := uint64(0); code < max; code += step {
+			s, ok := m.Successor(code)
+			if !ok {
+				t.Fatalf("MaxCode %v: Successor(%v) failed", max, code)
+			}
+			if p, ok := m.Predecessor(s); !ok || p != code {
+				t.Fatalf("MaxCode %v: Predecessor(Successor(%v)) = %v, %v", max, code, p, ok)
+			}
+			if code > 0 {
+				p, _ := m.Predecessor(code)
+				if s, ok := m.Successor(p); !ok || s != code {
+					t.Fatalf("MaxCode %v: Successor(Predecessor(%v)) = %v, %v", max, code, s, ok)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+	Octree navigation treats a 3D code as a node path, three bits per level with the root's children in the highest bits.  The parent drops the node's last octant, and a child appends one, so these codes grow with depth rather than staying at a fixed width; see AtLevel for fixed width cell codes.
+*/
+
+// Parent of an octree node, without its last octant.
+func (m *Morton) OctreeParent(code uint64) (uint64, error) {
+	if m.Dimensions != 3 {
+		return 0, fmt.Errorf("%w.  Octree navigation requires 3 dimensions", ErrDimensionMismatch)
+	}
+	return code >> 3, nil
+}
+
+// Child of an octree node in octant childIndex, in [0, 7], whose bits select the half in x, y and z, from least significant to most.
+func (m *Morton) OctreeChild(code uint64, childIndex int) (uint64, error) {
+	if m.Dimensions != 3 {
+		return 0, fmt.Errorf("%w.  Octree navigation requires 3 dimensions", ErrDimensionMismatch)
+	}
+	if childIndex < 0 || childIndex > 7 {
+		return 0, errors.New(fmt.Sprint("Octree child index ", childIndex, " is outside [0, 7]"))
+	}
+	if code>>61 != 0 {
+		return 0, errors.New("Octree node has no room for another level in 64 bits")
+	}
+	return code<<3 | uint64(childIndex), nil
+}
+
+// Octal path of the node at level containing code, one digit per level from the root's octant, each digit an interleaved group of x, y and z bits.  Parent paths are prefixes of their children's.
+func (m *Morton) ToOctreePath(code uint64, level uint8) (string, error) {
+	if m.Dimensions != 3 {
+		return "", fmt.Errorf("%w.  Octree paths require 3 dimensions", ErrDimensionMismatch)
+	}
+	if level > m.Bits() {
+		return "", ErrInvalidLevel
+	}
+	if err := m.checkLayout(); err != nil {
+		return "", err
+	}
+
+	var s strings.Builder
+	for i := uint8(0); i < level; i++ {
+		s.WriteByte('0' + byte(code>>(3*uint(m.Bits()-1-i))&7))
+	}
+	return s.String(), nil
+}
+
+// Parses an octal octree path into its node, the code of the node's cell on a grid of 2^level cells per dimension, level being the path's length.  This is also the node path used by OctreeParent and OctreeChild.
+func FromOctreePath(path string) (code uint64, level uint8, err error) {
+	if len(path) > 21 {
+		return 0, 0, errors.New("Octree path must have at most 21 digits")
+	}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c < '0' || c > '7' {
+			return 0, 0, errors.New(fmt.Sprint("Invalid octree path digit ", string(c), " at position ", i))
+		}
+		code = code<<3 | uint64(c-'0')
+	}
+	return code, uint8(len(path)), nil
+}
@@ -0,0 +1,148 @@
+package morton
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Nodes at depth k of the octree over New(3, 8) hold the top 3k bits of the 9 bit codes, full codes at depth 3.
+func TestOctreeChildren(t *testing.T) {
+	m := New(3, 8)
+	codes, vectors := allCodes(t, m)
+	for depth := uint(0); depth < 3; depth++ {
+		for parent := uint64(0); parent < 1<<(3*depth); parent++ {
+			below := 3 * (2 - depth)
+			covered := make(map[uint64]int)
+			for i := 0; i < 8; i++ {
+				child, err := m.OctreeChild(parent, i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if back, _ := m.OctreeParent(child); back != parent {
+					t.Fatalf("OctreeParent(OctreeChild(%v, %v)) = %v", parent, i, back)
+				}
+				for n, code := range codes {
+					if code>>below != child {
+						continue
+					}
+					covered[code]++
+					// The octant's bits select the upper half of x, y and z
+					for dim := 0; dim < 3; dim++ {
+						if half := vectors[n][dim] >> (2 - depth) & 1; half != uint32(i>>dim&1) {
+							t.Fatalf("Code %v of child %v of %v has %v in dimension %v", code, i, parent, vectors[n], dim)
+						}
+					}
+				}
+			}
+
+			// The children partition the parent
+			for _, code := range codes {
+				inParent := code>>(below+3) == parent
+				if n := covered[code]; inParent && n != 1 || !inParent && n != 0 {
+					t.Fatalf("Code %v is covered by %v children of depth %v node %v", code, n, depth, parent)
+				}
+			}
+		}
+	}
+}
+
+func TestOctreeChildIndex(t *testing.T) {
+	m := New(3, 1<<20)
+	codes := []uint64{0, 7, 8, 0x123456789, 1<<60 - 1}
+	for _, code := range codes {
+		parent, err := m.OctreeParent(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if child, err := m.OctreeChild(parent, int(code&7)); err != nil || child != code {
+			t.Errorf("OctreeChild(OctreeParent(%v), %v) = %v, %v", code, code&7, child, err)
+		}
+	}
+}
+
+func TestOctreeRejects(t *testing.T) {
+	m := New(3, 8)
+	for _, i := range []int{-1, 8} {
+		if _, err := m.OctreeChild(0, i); err == nil {
+			t.Errorf("OctreeChild(0, %v) succeeded", i)
+		}
+	}
+	if _, err := m.OctreeChild(1<<61, 0); err == nil {
+		t.Error("OctreeChild beyond 64 bits succeeded")
+	}
+	two := New(2, 8)
+	if _, err := two.OctreeParent(0); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("OctreeParent in 2 dimensions returned %v", err)
+	}
+	if _, err := two.OctreeChild(0, 1); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("OctreeChild in 2 dimensions returned %v", err)
+	}
+}
+
+func TestOctreePath(t *testing.T) {
+	m := New(3, 1<<10)
+	// The first digit is the root octant: the top bits of x, y and z
+	for octant := 0; octant < 8; octant++ {
+		v := []uint32{uint32(octant&1) << 9, uint32(octant>>1&1) << 9, uint32(octant>>2) << 9}
+		path, err := m.ToOctreePath(m.MustEncode(v), 1)
+		if err != nil || path != string(rune('0'+octant)) {
+			t.Errorf("ToOctreePath of %v at level 1 = %q, %v; want %q", v, path, err, string(rune('0'+octant)))
+		}
+	}
+	if path, _ := m.ToOctreePath(m.MustEncode([]uint32{1023, 0, 512}), 3); path != "511" {
+		t.Errorf("ToOctreePath of [1023 0 512] at level 3 = %q, want \"511\"", path)
+	}
+
+	r := rand.New(rand.NewSource(143))
+	for n := 0; n < 500; n++ {
+		code := m.MustEncode([]uint32{uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10))})
+		full, _ := m.ToOctreePath(code, m.Bits())
+		if len(full) != int(m.Bits()) {
+			t.Fatalf("ToOctreePath at level %v has %v digits", m.Bits(), len(full))
+		}
+		for level := uint8(0); level <= m.Bits(); level++ {
+			path, err := m.ToOctreePath(code, level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Parents' paths prefix their children's
+			if !strings.HasPrefix(full, path) {
+				t.Fatalf("path %q at level %v does not prefix %q", path, level, full)
+			}
+			node, l, err := FromOctreePath(path)
+			if err != nil || l != level {
+				t.Fatalf("FromOctreePath(%q) = %v, %v, %v", path, node, l, err)
+			}
+			// The node is the cell code shifted down, the path used by OctreeParent and OctreeChild
+			cell, _ := m.AtLevel(code, level)
+			if want := cell >> (3 * uint(m.Bits()-level)); node != want {
+				t.Fatalf("FromOctreePath(%q) = %v, want %v", path, node, want)
+			}
+			if level > 0 {
+				parent, _ := m.OctreeParent(node)
+				if want, _, _ := FromOctreePath(path[:level-1]); parent != want {
+					t.Fatalf("OctreeParent of %q is %v, want %v", path, parent, want)
+				}
+			}
+		}
+	}
+}
+
+func TestOctreePathRejects(t *testing.T) {
+	if _, err := New(2, 8).ToOctreePath(0, 1); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("ToOctreePath in 2 dimensions returned %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := New(3, 8).ToOctreePath(0, 4); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("ToOctreePath beyond the bit budget returned %v, want ErrInvalidLevel", err)
+	}
+	for _, path := range []string{"018", "7a", strings.Repeat("1", 22)} {
+		if _, _, err := FromOctreePath(path); err == nil {
+			t.Errorf("FromOctreePath(%q) succeeded", path)
+		}
+	}
+	if code, level, err := FromOctreePath(""); err != nil || code != 0 || level != 0 {
+		t.Errorf("FromOctreePath of the root = %v, %v, %v", code, level, err)
+	}
+}
@@ -0,0 +1,129 @@
+package morton
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// Returned by helpers that work on the interleaved bits of codes directly, e.g. on cells and ranges, which are only meaningful in the default layout.
+var ErrUnsupportedLayout = errors.New("Codes are not in the default Z-order layout.  Helpers working on interleaved bits do not support WithGrayCode, WithShardScatter or WithInterleaver")
+
+// Configures a Morton at creation.  See Create.
+type Option func(*Morton)
+
+// Gray codes each component before interleaving, so that consecutive values along an axis differ in a single code bit.  The transform is baked into the lookup tables and reversed by Decode, so round trips return the original coordinates.  Helpers that manipulate codes directly in the interleaved (dilated) domain, such as the cell and range helpers, return ErrUnsupportedLayout.
+func WithGrayCode() Option {
+	return func(m *Morton) {
+		m.gray = true
+	}
+}
+
+// Table entry function for dimension index, or nil for the plain interleaved pattern.
+func (m *Morton) spread(index uint8) func(uint32) uint64 {
+	switch {
+	case m.interleaver != nil && m.gray:
+		return func(v uint32) uint64 { return m.interleaver.Spread(toGray(v), index) }
+	case m.interleaver != nil:
+		return func(v uint32) uint64 { return m.interleaver.Spread(v, index) }
+	case m.gray:
+		d := uint32(m.Dimensions)
+		return func(v uint32) uint64 { return InterleaveBits(toGray(v), uint32(index), d-1).Value }
+	}
+	return nil
+}
+
+func toGray(v uint32) uint32 {
+	return v ^ (v >> 1)
+}
+
+func fromGray(g uint32) uint32 {
+	g ^= g >> 1
+	g ^= g >> 2
+	g ^= g >> 4
+	g ^= g >> 8
+	g ^= g >> 16
+	return g
+}
+
+// Bit reverses the meaningful portion of each code (see ReverseBits), so that sequential inserts spread across the keyspace, e.g., across shards keyed by the top bits, while the mapping stays bijective.  Decode reverses it.  Locality between consecutive codes is deliberately lost; range and cell helpers return ErrUnsupportedLayout.
+func WithShardScatter() Option {
+	return func(m *Morton) {
+		m.scatter = true
+	}
+}
+
+// Reverses the order of the low n bits of code, leaving any higher bits unchanged.  It is its own inverse.
+func ReverseBits(code uint64, n uint8) uint64 {
+	if n == 0 {
+		return code
+	}
+	if n >= 64 {
+		return bits.Reverse64(code)
+	}
+	low := lowMask(n)
+	return code&^low | bits.Reverse64(code&low)>>(64-n)
+}
+
+// Mask of the low n bits.
+func lowMask(n uint8) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<n - 1
+}
+
+// Number of meaningful code bits: the tables' bit budget across all dimensions.
+func (m *Morton) codeBits() uint8 {
+	if mi, ok := m.interleaver.(maskInterleaver); ok {
+		return mi.bits()
+	}
+	b := m.tableBits() * uint(m.Dimensions)
+	if b > 64 {
+		return 64
+	}
+	return uint8(b)
+}
+
+// Reports whether codes are plain Z-order, without Gray coding, scattering or a custom Interleaver.
+func (m *Morton) defaultLayout() bool {
+	return !m.gray && !m.scatter && m.interleaver == nil
+}
+
+// ErrUnsupportedLayout unless codes are in the default layout.
+func (m *Morton) checkLayout() error {
+	if !m.defaultLayout() {
+		return ErrUnsupportedLayout
+	}
+	return nil
+}
+
+// Bits contributed to an unscattered code by value in dimension dim, without range checks.
+func (m *Morton) contribution(value uint32, dim uint8) uint64 {
+	if spread := m.spread(dim); spread != nil {
+		return spread(value)
+	}
+	return Dilate(value, m.Dimensions) << dim
+}
+
+// Options reproducing this Morton's configuration.
+func (m *Morton) options() (opts []Option) {
+	if m.gray {
+		opts = append(opts, WithGrayCode())
+	}
+	if m.color {
+		opts = append(opts, WithColor(true))
+	}
+	if m.scatter {
+		opts = append(opts, WithShardScatter())
+	}
+	if m.interleaver != nil {
+		opts = append(opts, WithInterleaver(m.interleaver))
+	}
+	if m.timeUnit != 0 {
+		opts = append(opts, WithTimeUnit(m.timeUnit))
+	}
+	if t := m.transform; t != nil {
+		opts = append(opts, WithTransform(t.Offset, t.Scale), WithRotation(t.Rotation))
+	}
+	return
+}
@@ -0,0 +1,183 @@
+package morton
+
+import (
+	"errors"
+	"math/bits"
+	"testing"
+)
+
+// Mortons of each layout other than the default.
+func otherLayouts(d uint8, length uint32) []struct {
+	name string
+	m    *Morton
+} {
+	return []struct {
+		name string
+		m    *Morton
+	}{
+		{"gray", New(d, length, WithGrayCode())},
+		{"scatter", New(d, length, WithShardScatter())},
+		{"reversed", New(d, length, WithInterleaver(reversedInterleaver{d}))},
+	}
+}
+
+func TestProjectInjectLayouts(t *testing.T) {
+	for _, tc := range append(otherLayouts(2, 8), otherLayouts(3, 4)...) {
+		codes, vectors := allCodes(t, tc.m)
+		for n, code := range codes {
+			for i := range vectors[n] {
+				dim := uint8(i)
+				if got := tc.m.Project(code, dim); got != vectors[n][i] {
+					t.Fatalf("%v: Project(%v, %v) = %v, want %v", tc.name, code, dim, got, vectors[n][i])
+				}
+				v := append([]uint32(nil), vectors[n]...)
+				v[i] = tc.m.Tables[i].Length - 1 - v[i]
+				want, _ := tc.m.Encode(v)
+				if got := tc.m.Inject(code, dim, v[i]); got != want {
+					t.Fatalf("%v: Inject(%v, %v, %v) = %v, want %v", tc.name, code, dim, v[i], got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestInBoxLayouts(t *testing.T) {
+	min, max := []uint32{1, 2}, []uint32{5, 3}
+	for _, tc := range otherLayouts(2, 8) {
+		codes, vectors := allCodes(t, tc.m)
+		for n, code := range codes {
+			v := vectors[n]
+			want := v[0] >= min[0] && v[0] <= max[0] && v[1] >= min[1] && v[1] <= max[1]
+			if got := tc.m.InBox(code, min, max); got != want {
+				t.Errorf("%v: InBox(%v) of %v = %v, want %v", tc.name, code, v, got, want)
+			}
+		}
+	}
+}
+
+func TestUnsupportedLayout(t *testing.T) {
+	min, max := []uint32{1, 1}, []uint32{5, 6}
+	for _, tc := range otherLayouts(2, 8) {
+		m := tc.m
+		for name, call := range map[string]func() error{
+			"RangeDecompose": func() error { _, err := m.RangeDecompose(min, max); return err },
+			"CellCover":      func() error { _, err := m.CellCover(min, max, 0); return err },
+			"AtLevel":        func() error { _, err := m.AtLevel(5, 1); return err },
+			"EncodeLevels":   func() error { _, err := m.EncodeLevels([]uint32{5, 3}, []uint8{1, 2}); return err },
+			"CellBounds":     func() error { _, _, err := m.CellBounds(5, 1); return err },
+			"Permute":        func() error { _, err := m.Permute(5, []uint8{1, 0}); return err },
+			"Mirror":         func() error { _, err := m.Mirror(5, []uint8{0}); return err },
+			"Rebase":         func() error { return m.Rebase([]uint64{5}, []int32{1, 0}) },
+			"LiftCode":       func() error { _, err := m.LiftCode(5, 0, 2); return err },
+			"DropDim":        func() error { _, err := m.DropDim(5, 1); return err },
+			"FrustumQuery":   func() error { _, err := m.FrustumQuery([]uint32{4, 4}, []int32{1, 0}, 1, 3); return err },
+			"ToQuadkey":      func() error { _, err := m.ToQuadkey(5, 2); return err },
+			"FromTileXYZ":    func() error { _, err := m.FromTileXYZ(1, 1, 2); return err },
+			"PlanScan":       func() error { _, err := m.PlanScan(nil, min, max); return err },
+			"NewCodeIndex":   func() error { _, err := NewCodeIndex(m, [][]uint32{{1, 2}}); return err },
+		} {
+			if err := call(); !errors.Is(err, ErrUnsupportedLayout) {
+				t.Errorf("%v: %v returned %v, want ErrUnsupportedLayout", tc.name, name, err)
+			}
+		}
+
+		if _, ok := m.BigMin(0, min, max); ok {
+			t.Errorf("%v: BigMin succeeded", tc.name)
+		}
+		if _, ok := m.EstimateCount([]uint64{1, 2, 3}, min, max, 4); ok {
+			t.Errorf("%v: EstimateCount was exact", tc.name)
+		}
+		m.VisibleCells(1, boxTest(min, max))(func(c Cell) bool {
+			t.Errorf("%v: VisibleCells yielded %v", tc.name, c)
+			return true
+		})
+		m.Join([]uint64{1, 2}, []uint64{1, 2}, 1, func(ai, bi int) {
+			t.Errorf("%v: Join paired %v and %v", tc.name, ai, bi)
+		})
+	}
+}
+
+func TestDefaultLayoutSupported(t *testing.T) {
+	m := New(2, 8)
+	if _, err := m.RangeDecompose([]uint32{1, 1}, []uint32{5, 6}); err != nil {
+		t.Errorf("RangeDecompose: %v", err)
+	}
+	if _, err := m.Permute(5, []uint8{1, 0}); err != nil {
+		t.Errorf("Permute: %v", err)
+	}
+}
+
+func TestGrayCode(t *testing.T) {
+	for _, m := range []*Morton{New(2, 16, WithGrayCode()), New(3, 10, WithGrayCode())} {
+		d := int(m.Dimensions)
+		codes, vectors := allCodes(t, m)
+		for n, code := range codes {
+			if got := m.Decode(code); !equalUint32s(got, vectors[n]) {
+				t.Fatalf("Decode(Encode(%v)) = %v", vectors[n], got)
+			}
+			// A step along one axis flips the one bit of that lane the Gray code of the value changes
+			for dim := 0; dim < d; dim++ {
+				v := append([]uint32(nil), vectors[n]...)
+				if v[dim]++; v[dim] >= m.Tables[dim].Length {
+					continue
+				}
+				next, _ := m.Encode(v)
+				want := uint64(1) << (uint(bits.TrailingZeros32(v[dim]))*uint(d) + uint(dim))
+				if code^next != want {
+					t.Fatalf("codes of %v and %v differ by %#x, want %#x", vectors[n], v, code^next, want)
+				}
+			}
+		}
+	}
+}
+
+func TestReverseBits(t *testing.T) {
+	for _, tc := range []struct {
+		code uint64
+		n    uint8
+		want uint64
+	}{
+		{0b0001, 4, 0b1000},
+		{0b0110, 4, 0b0110},
+		{0xf0_01, 8, 0xf0_80}, // bits above n stay put
+		{1, 64, 1 << 63},
+		{0x1234, 0, 0x1234},
+		{1, 1, 1},
+	} {
+		if got := ReverseBits(tc.code, tc.n); got != tc.want {
+			t.Errorf("ReverseBits(%#x, %v) = %#x, want %#x", tc.code, tc.n, got, tc.want)
+		}
+		if back := ReverseBits(ReverseBits(tc.code, tc.n), tc.n); back != tc.code {
+			t.Errorf("ReverseBits(%#x, %v) twice = %#x", tc.code, tc.n, back)
+		}
+	}
+}
+
+func TestShardScatter(t *testing.T) {
+	m := New(2, 256, WithShardScatter())
+	plain := New(2, 256)
+	codes, vectors := allCodes(t, m)
+	for n, code := range codes {
+		if got := m.Decode(code); !equalUint32s(got, vectors[n]) {
+			t.Fatalf("Decode(Encode(%v)) = %v", vectors[n], got)
+		}
+		// Only the 16 bits of the code take part
+		want, _ := plain.Encode(vectors[n])
+		if code != ReverseBits(want, 16) || code>>16 != 0 {
+			t.Fatalf("Encode(%v) = %#x, want %#x reversed in 16 bits", vectors[n], code, want)
+		}
+	}
+
+	// Sequential inserts spread across the top byte
+	var counts [256]int
+	for c := uint64(0); c < 1<<12; c++ {
+		v := plain.Decode(c)
+		code, _ := m.Encode(v)
+		counts[code>>8]++
+	}
+	for b, n := range counts {
+		if n != 16 {
+			t.Errorf("top byte %#x holds %v of 4096 sequential codes, want 16", b, n)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Enables ANSI colored output in BitPattern.
+func WithColor(color bool) Option {
+	return func(m *Morton) {
+		m.color = color
+	}
+}
+
+// ANSI foreground colors, cycled by dimension.
+var patternColors = []string{"31", "32", "34", "33", "35", "36"}
+
+// Label for dimension dim: X, Y, Z and W up to 4 dimensions, otherwise the base 36 dimension index.
+func (m *Morton) dimLabel(dim uint8) byte {
+	if m.Dimensions <= 4 {
+		return "XYZW"[dim]
+	}
+	return strconv.FormatUint(uint64(dim), 36)[0]
+}
+
+// Describes the bit layout of code, most significant bit first.  The plain format is two lines of 64 characters: the first labels the dimension owning each bit position (see dimLabel), the second holds the bits, and positions outside the tables' bit budget are '_' in both.  For example, in 2 dimensions the labels end in "...YXYX", dimension 0 owning the even positions.  With WithColor(true), a single line of bits is returned, each colored by its dimension.
+func (m *Morton) BitPattern(code uint64) string {
+	d := uint(m.Dimensions)
+	used := m.tableBits() * d
+
+	var labels, digits strings.Builder
+	for i := 63; i >= 0; i-- {
+		p := uint(i)
+		if d == 0 || p >= used {
+			labels.WriteByte('_')
+			digits.WriteByte('_')
+			continue
+		}
+
+		bit := byte('0' + code>>p&1)
+		dim := uint8(p % d)
+		if m.color {
+			digits.WriteString("\x1b[" + patternColors[int(dim)%len(patternColors)] + "m")
+			digits.WriteByte(bit)
+			digits.WriteString("\x1b[0m")
+			continue
+		}
+		labels.WriteByte(m.dimLabel(dim))
+		digits.WriteByte(bit)
+	}
+
+	if m.color {
+		return digits.String()
+	}
+	return labels.String() + "\n" + digits.String()
+}
+
+// Renders the Z-curve traversal of a gridSize x gridSize grid, indexed [y][x], each cell holding its position in the traversal as a base 36 digit (0-9, then a-z), or '*' for positions beyond 35.
+func (m *Morton) Visualize2D(gridSize int) ([][]rune, error) {
+	if m.Dimensions != 2 {
+		return nil, ErrNot2D
+	}
+	if gridSize <= 0 || uint64(gridSize) > uint64(m.Capacity())+1 {
+		return nil, errors.New(fmt.Sprint("Grid size must be between 1 and ", uint64(m.Capacity())+1))
+	}
+
+	type cell struct {
+		code uint64
+		x, y int
+	}
+	cells := make([]cell, 0, gridSize*gridSize)
+	for y := 0; y < gridSize; y++ {
+		for x := 0; x < gridSize; x++ {
+			code, err := m.Encode([]uint32{uint32(x), uint32(y)})
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, cell{code, x, y})
+		}
+	}
+	sort.Slice(cells, func(i, j int) bool { return cells[i].code < cells[j].code })
+
+	grid := make([][]rune, gridSize)
+	for y := range grid {
+		grid[y] = make([]rune, gridSize)
+	}
+	for i, c := range cells {
+		r := '*'
+		if i < 36 {
+			r = rune(strconv.FormatInt(int64(i), 36)[0])
+		}
+		grid[c.y][c.x] = r
+	}
+	return grid, nil
+}
+
+// Visualize2D as one line per row, cells separated by spaces.
+func (m *Morton) SprintGrid(gridSize int) (string, error) {
+	grid, err := m.Visualize2D(gridSize)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, row := range grid {
+		for x, r := range row {
+			if x > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,114 @@
+package morton
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBitPattern(t *testing.T) {
+	m := New(2, 16)
+	code, _ := m.Encode([]uint32{0xf, 0}) // x in every even position
+	lines := strings.Split(m.BitPattern(code), "\n")
+	if len(lines) != 2 || len(lines[0]) != 64 || len(lines[1]) != 64 {
+		t.Fatalf("BitPattern = %q, want two lines of 64 characters", lines)
+	}
+	if want := strings.Repeat("_", 56) + "YXYXYXYX"; lines[0] != want {
+		t.Errorf("labels = %q, want %q", lines[0], want)
+	}
+	if want := strings.Repeat("_", 56) + "01010101"; lines[1] != want {
+		t.Errorf("bits = %q, want %q", lines[1], want)
+	}
+	for p := 0; p < 8; p++ {
+		i := 63 - p
+		if dim := lines[0][i]; p%2 == 0 && dim != 'X' || p%2 == 1 && dim != 'Y' {
+			t.Errorf("bit %v is labelled %c", p, dim)
+		}
+	}
+}
+
+func TestBitPatternLabels(t *testing.T) {
+	labels := strings.Split(New(3, 4).BitPattern(0), "\n")[0]
+	if want := strings.Repeat("_", 58) + "ZYXZYX"; labels != want {
+		t.Errorf("3D labels = %q, want %q", labels, want)
+	}
+	// Beyond 4 dimensions, labels are dimension indices
+	labels = strings.Split(New(6, 2).BitPattern(0), "\n")[0]
+	if want := strings.Repeat("_", 58) + "543210"; labels != want {
+		t.Errorf("6D labels = %q, want %q", labels, want)
+	}
+}
+
+func TestBitPatternColor(t *testing.T) {
+	m := New(2, 4, WithColor(true))
+	p := m.BitPattern(1)
+	if strings.Contains(p, "\n") {
+		t.Errorf("colored BitPattern has more than one line: %q", p)
+	}
+	if want := strings.Repeat("_", 60) + "\x1b[32m0\x1b[0m\x1b[31m0\x1b[0m\x1b[32m0\x1b[0m\x1b[31m1\x1b[0m"; p != want {
+		t.Errorf("colored BitPattern = %q, want %q", p, want)
+	}
+}
+
+func TestVisualize2D(t *testing.T) {
+	m := New(2, 16)
+	grid, err := m.Visualize2D(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Row-major reading of the 4x4 Z-curve order
+	want := []int{0, 1, 4, 5, 2, 3, 6, 7, 8, 9, 12, 13, 10, 11, 14, 15}
+	for y, row := range grid {
+		if len(row) != 4 {
+			t.Fatalf("row %v has %v cells", y, len(row))
+		}
+		for x, r := range row {
+			if w := rune(strconv.FormatInt(int64(want[y*4+x]), 36)[0]); r != w {
+				t.Errorf("cell %v, %v = %c, want %c", x, y, r, w)
+			}
+		}
+	}
+
+	s, err := m.SprintGrid(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "0 1 4 5\n2 3 6 7\n8 9 c d\na b e f\n"; s != want {
+		t.Errorf("SprintGrid(4) = %q, want %q", s, want)
+	}
+}
+
+func TestVisualize2DLarge(t *testing.T) {
+	grid, err := New(2, 16).Visualize2D(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Positions 0 to 35 are digits, and beyond are stars
+	stars := 0
+	for _, row := range grid {
+		for _, r := range row {
+			if r == '*' {
+				stars++
+			}
+		}
+	}
+	if stars != 64-36 {
+		t.Errorf("8x8 grid has %v stars, want %v", stars, 64-36)
+	}
+	if grid[7][7] != '*' || grid[0][0] != '0' || grid[0][7] != 'l' {
+		t.Errorf("8x8 corners are %c, %c, %c", grid[0][0], grid[0][7], grid[7][7])
+	}
+}
+
+func TestVisualize2DRejects(t *testing.T) {
+	m := New(2, 16)
+	for _, size := range []int{0, -1, 17} {
+		if _, err := m.Visualize2D(size); err == nil {
+			t.Errorf("Visualize2D(%v) succeeded", size)
+		}
+	}
+	if _, err := New(3, 4).SprintGrid(2); !errors.Is(err, ErrNot2D) {
+		t.Errorf("SprintGrid in 3 dimensions returned %v, want ErrNot2D", err)
+	}
+}
@@ -0,0 +1,259 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidPermutation = errors.New("Permutation must contain each dimension index exactly once")
+
+// A validated permutation, precomputed as one masked shift per lane.
+type permutation struct {
+	masks  []uint64
+	shifts []int
+}
+
+func (m *Morton) permutation(perm []uint8) (p permutation, err error) {
+	if err = m.checkLayout(); err != nil {
+		return
+	}
+	d := m.Dimensions
+	if len(perm) != int(d) {
+		err = ErrDimensionMismatch
+		return
+	}
+
+	seen := make([]bool, d)
+	for _, s := range perm {
+		if s >= d || seen[s] {
+			err = ErrInvalidPermutation
+			return
+		}
+		seen[s] = true
+	}
+
+	p.masks = make([]uint64, d)
+	p.shifts = make([]int, d)
+	for i, s := range perm {
+		p.masks[i] = MaskForDimension(d, s)
+		p.shifts[i] = i - int(s)
+	}
+	return
+}
+
+func (p permutation) apply(code uint64) (result uint64) {
+	for i, mask := range p.masks {
+		lane := code & mask
+		if s := p.shifts[i]; s >= 0 {
+			result |= lane << uint(s)
+		} else {
+			result |= lane >> uint(-s)
+		}
+	}
+	return
+}
+
+// Rearranges the interleaved lanes of code such that dimension i of the result holds dimension perm[i] of the input, without decoding.  For example, perm {1, 0} swaps x and y in 2 dimensions.
+func (m *Morton) Permute(code uint64, perm []uint8) (uint64, error) {
+	p, err := m.permutation(perm)
+	if err != nil {
+		return 0, err
+	}
+	return p.apply(code), nil
+}
+
+// Permutes every code in codes, in place.  See Permute.
+func (m *Morton) PermuteAll(codes []uint64, perm []uint8) error {
+	p, err := m.permutation(perm)
+	if err != nil {
+		return err
+	}
+	for i, c := range codes {
+		codes[i] = p.apply(c)
+	}
+	return nil
+}
+
+// Lane mask of dim restricted to the bit budget of its table, i.e., the bits needed to represent the table's last index.
+func (m *Morton) tableMask(dim uint8) uint64 {
+	b := m.Tables[dim].bits() * uint(m.Dimensions)
+	if b >= 64 {
+		return MaskForDimension(m.Dimensions, dim)
+	}
+	return MaskForDimension(m.Dimensions, dim) & (1<<b - 1)
+}
+
+// Reflects code along each of the given axes, replacing each selected component with (2^bits - 1 - value), where bits is the number of bits needed for that dimension's table.  Because the maximum is all ones, this is a single XOR with the axis mask.  The reflection is about the full bit budget, not the table length; unless the table length is a power of two, mirrored components may exceed the table.
+func (m *Morton) Mirror(code uint64, axes []uint8) (uint64, error) {
+	if err := m.checkLayout(); err != nil {
+		return 0, err
+	}
+	var mask uint64
+	for _, a := range axes {
+		if a >= m.Dimensions || int(a) >= len(m.Tables) {
+			return 0, errors.New("Mirror axis exceeds the number of dimensions")
+		}
+		mask |= m.tableMask(a)
+	}
+	return code ^ mask, nil
+}
+
+// Extracts component dim of code directly from its lane.  Other layouts decode the one component.
+func (m *Morton) Project(code uint64, dim uint8) uint32 {
+	if !m.defaultLayout() {
+		if m.scatter {
+			code = ReverseBits(code, m.codeBits())
+		}
+		return m.component(code, dim)
+	}
+	return Undilate(code>>dim, m.Dimensions)
+}
+
+// Replaces component dim of code with value, leaving the other lanes intact.  Other layouts decode the other components and interleave them again.
+func (m *Morton) Inject(code uint64, dim uint8, value uint32) uint64 {
+	if !m.defaultLayout() {
+		if m.scatter {
+			code = ReverseBits(code, m.codeBits())
+		}
+		var result uint64
+		for i := uint8(0); i < m.Dimensions; i++ {
+			v := value
+			if i != dim {
+				v = m.component(code, i)
+			}
+			result |= m.contribution(v, i)
+		}
+		if m.scatter {
+			result = ReverseBits(result, m.codeBits())
+		}
+		return result
+	}
+	return code&^MaskForDimension(m.Dimensions, dim) | Dilate(value, m.Dimensions)<<dim
+}
+
+// Swaps components dimA and dimB of code.  It is its own inverse.
+func (m *Morton) SwapDims(code uint64, dimA, dimB int) (uint64, error) {
+	d := int(m.Dimensions)
+	if dimA < 0 || dimA >= d || dimB < 0 || dimB >= d {
+		return 0, errors.New("Swapped dimensions exceed the number of dimensions")
+	}
+
+	a, b := uint8(dimA), uint8(dimB)
+	va, vb := m.Project(code, a), m.Project(code, b)
+	return m.Inject(m.Inject(code, a, vb), b, va), nil
+}
+
+// Swaps X and Y of a 2 dimension code.
+func (m *Morton) Transpose(code uint64) (uint64, error) {
+	if m.Dimensions != 2 {
+		return 0, ErrNot2D
+	}
+	return m.SwapDims(code, 0, 1)
+}
+
+// Moves each lane of a code with from dimensions into lane target[i] of a code with to dimensions, by undilating and dilating it again; lanes mapped to -1 are dropped.
+func relayout(code uint64, from, to uint8, target []int) (result uint64) {
+	for i, j := range target {
+		if j >= 0 {
+			result |= Dilate(Undilate(code>>uint(i), from), to) << uint(j)
+		}
+	}
+	return
+}
+
+// Validates a lift of m's codes by a dimension at position, returning the lane targets.
+func (m *Morton) liftTargets(position uint8) ([]int, error) {
+	d := int(m.Dimensions)
+	switch {
+	case len(m.Tables) == 0:
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	case !m.defaultLayout():
+		return nil, ErrUnsupportedLayout
+	case int(position) > d:
+		return nil, errors.New(fmt.Sprint("Position ", position, " exceeds the number of dimensions"))
+	case d == math.MaxUint8 || int(m.tableBits())*(d+1) > 64:
+		return nil, fmt.Errorf("%w.  %v dimensions of %v bits", ErrCapacityExceeded, d+1, m.tableBits())
+	}
+	target := make([]int, d)
+	for i := range target {
+		target[i] = i
+		if i >= int(position) {
+			target[i]++
+		}
+	}
+	return target, nil
+}
+
+// Inserts a new dimension at position, holding value, into code: the result is the code of the same vector, with value inserted, under a Morton of one more dimension and the same table lengths.  Unlike Permute, changing the number of dimensions changes the spacing within each lane, so every lane is undilated and dilated again, which costs about as much as decoding and encoding; like the other dilated helpers, other layouts return ErrUnsupportedLayout.
+func (m *Morton) LiftCode(code uint64, value uint32, position uint8) (uint64, error) {
+	codes, err := m.LiftCodes([]uint64{code}, []uint32{value}, position)
+	if err != nil {
+		return 0, err
+	}
+	return codes[0], nil
+}
+
+// LiftCode for each code, values[i] being inserted into codes[i].
+func (m *Morton) LiftCodes(codes []uint64, values []uint32, position uint8) ([]uint64, error) {
+	if len(values) != len(codes) {
+		return nil, errors.New(fmt.Sprint("Lifting ", len(codes), " codes requires as many values, not ", len(values)))
+	}
+	target, err := m.liftTargets(position)
+	if err != nil {
+		return nil, err
+	}
+	limit := uint32(lowMask(uint8(m.tableBits())))
+	for i, v := range values {
+		if v > limit {
+			return nil, fmt.Errorf("%w.  Value %v of code %v exceeds %v bits", ErrComponentOverflow, v, i, m.tableBits())
+		}
+	}
+
+	from, to := m.Dimensions, m.Dimensions+1
+	lifted := make([]uint64, len(codes))
+	for i, c := range codes {
+		lifted[i] = relayout(c, from, to, target) | Dilate(values[i], to)<<position
+	}
+	return lifted, nil
+}
+
+// Removes dimension dim from code: the result is the code of the vector without that component, under a Morton of one fewer dimension and the same table lengths.  The inverse of LiftCode, which it shares its layout assumption with.
+func (m *Morton) DropDim(code uint64, dim uint8) (uint64, error) {
+	codes, err := m.DropDims([]uint64{code}, dim)
+	if err != nil {
+		return 0, err
+	}
+	return codes[0], nil
+}
+
+// DropDim for each code.
+func (m *Morton) DropDims(codes []uint64, dim uint8) ([]uint64, error) {
+	d := int(m.Dimensions)
+	switch {
+	case d < 2:
+		return nil, fmt.Errorf("%w.  Dropping a dimension requires at least 2", ErrDimensionMismatch)
+	case !m.defaultLayout():
+		return nil, ErrUnsupportedLayout
+	case int(dim) >= d:
+		return nil, errors.New(fmt.Sprint("Dimension ", dim, " exceeds the number of dimensions"))
+	}
+	target := make([]int, d)
+	for i := range target {
+		switch {
+		case i < int(dim):
+			target[i] = i
+		case i == int(dim):
+			target[i] = -1
+		default:
+			target[i] = i - 1
+		}
+	}
+
+	from, to := m.Dimensions, m.Dimensions-1
+	dropped := make([]uint64, len(codes))
+	for i, c := range codes {
+		dropped[i] = relayout(c, from, to, target)
+	}
+	return dropped, nil
+}
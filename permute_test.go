@@ -0,0 +1,258 @@
+package morton
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// Every permutation of 0..n-1.
+func permutations(n int) (perms [][]uint8) {
+	var build func(prefix []uint8, used int)
+	build = func(prefix []uint8, used int) {
+		if len(prefix) == n {
+			perms = append(perms, append([]uint8(nil), prefix...))
+			return
+		}
+		for i := 0; i < n; i++ {
+			if used&(1<<i) == 0 {
+				build(append(prefix, uint8(i)), used|1<<i)
+			}
+		}
+	}
+	build(nil, 0)
+	return
+}
+
+func TestPermute(t *testing.T) {
+	m := New(3, 1<<10)
+	r := rand.New(rand.NewSource(101))
+	for _, perm := range permutations(3) {
+		inverse := make([]uint8, len(perm))
+		for i, s := range perm {
+			inverse[s] = uint8(i)
+		}
+		for n := 0; n < 200; n++ {
+			v := []uint32{uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10)), uint32(r.Intn(1 << 10))}
+			code, _ := m.Encode(v)
+			got, err := m.Permute(code, perm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Dimension i of the result holds dimension perm[i]
+			w := make([]uint32, 3)
+			for i, s := range perm {
+				w[i] = v[s]
+			}
+			if want, _ := m.Encode(w); got != want {
+				t.Fatalf("Permute(%v of %v, %v) = %v, want %v", code, v, perm, got, want)
+			}
+			if back, _ := m.Permute(got, inverse); back != code {
+				t.Fatalf("Permute by %v then %v gave %v, want %v", perm, inverse, back, code)
+			}
+		}
+	}
+}
+
+func TestPermuteAll(t *testing.T) {
+	m := New(2, 256)
+	codes := []uint64{0, 1, 2, 0x1234, 0xffff}
+	want := make([]uint64, len(codes))
+	for i, c := range codes {
+		want[i], _ = m.Transpose(c)
+	}
+	if err := m.PermuteAll(codes, []uint8{1, 0}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range codes {
+		if codes[i] != want[i] {
+			t.Errorf("PermuteAll gave %v at %v, want %v", codes[i], i, want[i])
+		}
+	}
+
+	identity := []uint64{3, 9, 0xabcd}
+	if err := m.PermuteAll(identity, []uint8{0, 1}); err != nil || identity[0] != 3 || identity[1] != 9 || identity[2] != 0xabcd {
+		t.Errorf("PermuteAll by the identity gave %v, %v", identity, err)
+	}
+}
+
+func TestPermuteRejects(t *testing.T) {
+	m := New(3, 16)
+	for _, perm := range [][]uint8{{0, 1}, {0, 1, 2, 3}} {
+		if _, err := m.Permute(5, perm); !errors.Is(err, ErrDimensionMismatch) {
+			t.Errorf("Permute by %v returned %v, want ErrDimensionMismatch", perm, err)
+		}
+	}
+	for _, perm := range [][]uint8{{0, 0, 1}, {0, 1, 3}} {
+		if _, err := m.Permute(5, perm); !errors.Is(err, ErrInvalidPermutation) {
+			t.Errorf("Permute by %v returned %v, want ErrInvalidPermutation", perm, err)
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	m := New(3, 16)
+	codes, vectors := allCodes(t, m)
+	for _, axes := range [][]uint8{nil, {0}, {2}, {0, 1}, {0, 1, 2}, {1, 1}} {
+		for n, code := range codes {
+			got, err := m.Mirror(code, axes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := append([]uint32(nil), vectors[n]...)
+			for _, a := range axes {
+				w[a] = 15 - vectors[n][a]
+			}
+			// Mirroring an axis twice in one call still mirrors it once
+			if want, _ := m.Encode(w); got != want {
+				t.Fatalf("Mirror(%v of %v, %v) = %v, want %v", code, vectors[n], axes, got, want)
+			}
+			if back, _ := m.Mirror(got, axes); back != code {
+				t.Fatalf("Mirror along %v twice gave %v, want %v", axes, back, code)
+			}
+		}
+	}
+	if _, err := m.Mirror(0, []uint8{3}); err == nil {
+		t.Error("Mirror along axis 3 succeeded")
+	}
+}
+
+func TestMirrorBitBudget(t *testing.T) {
+	// Tables of 5 reflect about 7, the full 3 bit budget
+	m := New(2, 5)
+	code, _ := m.Encode([]uint32{1, 4})
+	got, _ := m.Mirror(code, []uint8{0})
+	if v := m.Decode(got); v[0] != 6 || v[1] != 4 {
+		t.Errorf("Mirror of {1, 4} along x decodes to %v, want [6 4]", v)
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	m := New(2, 64)
+	for x := uint32(0); x < 64; x++ {
+		for y := uint32(0); y < 64; y++ {
+			code, _ := m.Encode([]uint32{x, y})
+			got, err := m.Transpose(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, _ := m.Encode([]uint32{y, x}); got != want {
+				t.Fatalf("Transpose of %v, %v = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+	if _, err := New(3, 8).Transpose(0); !errors.Is(err, ErrNot2D) {
+		t.Errorf("Transpose in 3 dimensions returned %v, want ErrNot2D", err)
+	}
+}
+
+func TestSwapDims(t *testing.T) {
+	layouts := append(otherLayouts(3, 8), struct {
+		name string
+		m    *Morton
+	}{"default", New(3, 8)})
+	for _, tc := range layouts {
+		codes, vectors := allCodes(t, tc.m)
+		for n, code := range codes {
+			for a := 0; a < 3; a++ {
+				for b := 0; b < 3; b++ {
+					got, err := tc.m.SwapDims(code, a, b)
+					if err != nil {
+						t.Fatal(err)
+					}
+					w := append([]uint32(nil), vectors[n]...)
+					w[a], w[b] = w[b], w[a]
+					if want, _ := tc.m.Encode(w); got != want {
+						t.Fatalf("%v: SwapDims(%v of %v, %v, %v) = %v, want %v", tc.name, code, vectors[n], a, b, got, want)
+					}
+					if back, _ := tc.m.SwapDims(got, a, b); back != code {
+						t.Fatalf("%v: SwapDims(%v, %v, %v) twice gave %v", tc.name, code, a, b, back)
+					}
+				}
+			}
+		}
+	}
+
+	m := New(3, 8)
+	for _, dims := range [][2]int{{-1, 0}, {0, 3}, {3, 3}} {
+		if _, err := m.SwapDims(0, dims[0], dims[1]); err == nil {
+			t.Errorf("SwapDims(%v, %v) succeeded", dims[0], dims[1])
+		}
+	}
+}
+
+func TestLiftCode(t *testing.T) {
+	m2, m3 := New(2, 32), New(3, 32)
+	codes, vectors := allCodes(t, m2)
+	r := rand.New(rand.NewSource(149))
+	for _, position := range []uint8{0, 1, 2} {
+		values := make([]uint32, len(codes))
+		for i := range values {
+			values[i] = uint32(r.Intn(32))
+		}
+		lifted, err := m2.LiftCodes(codes, values, position)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range vectors {
+			// Decode, insert the value, and encode
+			w := append(append(append([]uint32(nil), v[:position]...), values[i]), v[position:]...)
+			want := m3.MustEncode(w)
+			if lifted[i] != want {
+				t.Fatalf("LiftCodes of %v with %v at %v = %v, want %v", v, values[i], position, lifted[i], want)
+			}
+			if one, _ := m2.LiftCode(codes[i], values[i], position); one != want {
+				t.Fatalf("LiftCode of %v with %v at %v = %v, want %v", v, values[i], position, one, want)
+			}
+			if back, _ := m3.DropDim(lifted[i], position); back != codes[i] {
+				t.Fatalf("DropDim(LiftCode(%v), %v) = %v", codes[i], position, back)
+			}
+		}
+	}
+}
+
+func TestDropDim(t *testing.T) {
+	m3, m2 := New(3, 16), New(2, 16)
+	codes, vectors := allCodes(t, m3)
+	for dim := uint8(0); dim < 3; dim++ {
+		dropped, err := m3.DropDims(codes, dim)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, v := range vectors {
+			w := append(append([]uint32(nil), v[:dim]...), v[dim+1:]...)
+			if want := m2.MustEncode(w); dropped[i] != want {
+				t.Fatalf("DropDims of %v at %v = %v, want %v", v, dim, dropped[i], want)
+			}
+		}
+	}
+	// Down to one dimension, the code is the component
+	if got, _ := New(2, 16).DropDim(New(2, 16).MustEncode([]uint32{9, 13}), 0); got != 13 {
+		t.Errorf("DropDim of [9 13] at 0 = %v, want 13", got)
+	}
+}
+
+func TestLiftDropRejects(t *testing.T) {
+	m := New(2, 16)
+	if _, err := m.LiftCode(0, 16, 0); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("LiftCode of 16 into 4 bit lanes returned %v, want ErrComponentOverflow", err)
+	}
+	if _, err := m.LiftCode(0, 1, 3); err == nil {
+		t.Error("LiftCode at position 3 of 2 dimensions succeeded")
+	}
+	if _, err := m.LiftCodes([]uint64{1, 2}, []uint32{1}, 0); err == nil {
+		t.Error("LiftCodes of 2 codes with 1 value succeeded")
+	}
+	if _, err := New(3, 1<<17).LiftCode(0, 1, 0); !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("LiftCode to 4 dimensions of 17 bits returned %v, want ErrCapacityExceeded", err)
+	}
+	if _, err := New(1, 16).DropDim(5, 0); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("DropDim of a 1D code returned %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := m.DropDim(5, 2); err == nil {
+		t.Error("DropDim of dimension 2 of 2 succeeded")
+	}
+	if _, err := New(2, 16, WithGrayCode()).DropDim(5, 0); !errors.Is(err, ErrUnsupportedLayout) {
+		t.Errorf("DropDim under Gray coding returned %v, want ErrUnsupportedLayout", err)
+	}
+}
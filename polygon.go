@@ -0,0 +1,100 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Selects the cells a cover keeps.
+type CoverMode uint8
+
+const (
+	// Cells wholly within the region.
+	Interior CoverMode = iota
+	// Cells overlapping the region at all, including along its boundary.
+	Intersecting
+)
+
+// Reports whether segment ab meets the closed rectangle [x0, x1] x [y0, y1], by Liang-Barsky clipping.
+func segmentMeetsRect(a, b [2]float64, x0, y0, x1, y1 float64) bool {
+	t0, t1 := 0.0, 1.0
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	for _, e := range [4][2]float64{{-dx, a[0] - x0}, {dx, x1 - a[0]}, {-dy, a[1] - y0}, {dy, y1 - a[1]}} {
+		p, q := e[0], e[1]
+		switch {
+		case p == 0:
+			if q < 0 {
+				return false
+			}
+		case p < 0:
+			if t := q / p; t > t1 {
+				return false
+			} else if t > t0 {
+				t0 = t
+			}
+		default:
+			if t := q / p; t < t0 {
+				return false
+			} else if t < t1 {
+				t1 = t
+			}
+		}
+	}
+	return true
+}
+
+// Reports whether p lies within the rings by the even-odd rule, so that rings nested within the outer ring are holes.
+func ringsContain(rings [][][2]float64, p [2]float64) bool {
+	in := false
+	for _, ring := range rings {
+		for i, a := range ring {
+			b := ring[(i+1)%len(ring)]
+			if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < a[0]+(p[1]-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+				in = !in
+			}
+		}
+	}
+	return in
+}
+
+// Cell test for the region bounded by rings, whose cells span float coordinates through q.  A cell crossed by no edge is wholly inside or outside, as its center is.
+func polygonTest(rings [][][2]float64, q *Quantizer) CellTest {
+	return func(lo, hi []uint32) Containment {
+		x0, y0 := q.Axes[0].edge(uint64(lo[0])), q.Axes[1].edge(uint64(lo[1]))
+		x1, y1 := q.Axes[0].edge(uint64(hi[0])+1), q.Axes[1].edge(uint64(hi[1])+1)
+		for _, ring := range rings {
+			for i, a := range ring {
+				if segmentMeetsRect(a, ring[(i+1)%len(ring)], x0, y0, x1, y1) {
+					return Partial
+				}
+			}
+		}
+		if ringsContain(rings, [2]float64{(x0 + x1) / 2, (y0 + y1) / 2}) {
+			return Inside
+		}
+		return Outside
+	}
+}
+
+// Covers the polygon bounded by rings, an outer ring followed by any holes, with cells at level in ascending code order.  Coordinates are mapped onto the grid through quantizer.  In Interior mode, cells reached by the boundary are dropped, so degenerate slivers cover nothing; in Intersecting mode they are kept.
+func (m *Morton) PolygonCover(rings [][][2]float64, quantizer *Quantizer, level uint8, mode CoverMode) ([]Cell, error) {
+	if m.Dimensions != 2 {
+		return nil, ErrNot2D
+	}
+	if quantizer == nil || len(quantizer.Axes) != 2 {
+		return nil, fmt.Errorf("%w.  Polygon cover requires a 2D quantizer", ErrDimensionMismatch)
+	}
+	if len(m.Tables) < 2 {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if _, err := m.cellShift(level); err != nil {
+		return nil, err
+	}
+
+	var cells []Cell
+	m.cellsAt(level, polygonTest(rings, quantizer), mode == Intersecting, func(c Cell) bool {
+		cells = append(cells, c)
+		return true
+	})
+	return cells, nil
+}
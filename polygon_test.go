@@ -0,0 +1,118 @@
+package morton
+
+import (
+	"errors"
+	"testing"
+)
+
+// An outer ring with a triangular hole, off the integer grid so no cell corner lies on an edge.
+var testRings = [][][2]float64{
+	{{1.3, 1.2}, {14.7, 2.1}, {12.4, 13.6}, {6.1, 9.3}, {2.2, 14.5}},
+	{{5.5, 3.4}, {10.6, 3.2}, {8.7, 8.8}},
+}
+
+// Point-in-polygon checks at a 5x5 lattice of each cell, corners and center included.
+func cellSamples(rings [][][2]float64, x0, y0, x1, y1 float64) (anyIn, allIn bool) {
+	allIn = true
+	for i := 0; i <= 4; i++ {
+		for j := 0; j <= 4; j++ {
+			p := [2]float64{x0 + (x1-x0)*float64(i)/4, y0 + (y1-y0)*float64(j)/4}
+			in := ringsContain(rings, p)
+			anyIn = anyIn || in
+			allIn = allIn && in
+		}
+	}
+	return
+}
+
+func TestPolygonCover(t *testing.T) {
+	m := New(2, 16)
+	q, err := NewQuantizer(Axis{Min: 0, Max: 16, Bits: 4}, Axis{Min: 0, Max: 16, Bits: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, level := range []uint8{4, 3, 2} {
+		interior, err := m.PolygonCover(testRings, q, level, Interior)
+		if err != nil {
+			t.Fatal(err)
+		}
+		intersecting, err := m.PolygonCover(testRings, q, level, Intersecting)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := make(map[uint64]bool)
+		for _, c := range interior {
+			in[c.Code] = true
+		}
+		meets := make(map[uint64]bool)
+		for i, c := range intersecting {
+			if c.Level != level || i > 0 && intersecting[i-1].Code >= c.Code {
+				t.Fatalf("Level %v: intersecting cell %+v is out of order or at the wrong level", level, c)
+			}
+			meets[c.Code] = true
+		}
+
+		side := uint32(1) << (4 - level)
+		for y := uint32(0); y < 16; y += side {
+			for x := uint32(0); x < 16; x += side {
+				code, _ := m.AtLevel(m.MustEncode([]uint32{x, y}), level)
+				anyIn, allIn := cellSamples(testRings, float64(x), float64(y), float64(x+side), float64(y+side))
+				if in[code] && !allIn {
+					t.Errorf("Level %v: cell at %v, %v is interior, yet partly outside", level, x, y)
+				}
+				if anyIn && !meets[code] {
+					t.Errorf("Level %v: cell at %v, %v is partly inside, yet not intersecting", level, x, y)
+				}
+				if in[code] && !meets[code] {
+					t.Errorf("Level %v: cell at %v, %v is interior, yet not intersecting", level, x, y)
+				}
+			}
+		}
+		if level == 4 && len(interior) == 0 {
+			t.Error("No unit cell is interior")
+		}
+	}
+
+	// Wholly within the hole
+	unit, _ := m.PolygonCover(testRings, q, 4, Intersecting)
+	hole := m.MustEncode([]uint32{8, 4})
+	for _, c := range unit {
+		if c.Code == hole {
+			t.Error("The cell within the hole intersects the polygon")
+		}
+	}
+}
+
+func TestPolygonCoverSliver(t *testing.T) {
+	m := New(2, 16)
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 16, Bits: 4}, Axis{Min: 0, Max: 16, Bits: 4})
+	// A collinear ring has no area, but still crosses cells
+	sliver := [][][2]float64{{{1.5, 1.5}, {9.5, 5.5}, {5.5, 3.5}}}
+	if cells, _ := m.PolygonCover(sliver, q, 4, Interior); len(cells) != 0 {
+		t.Errorf("Interior cover of a sliver = %v", cells)
+	}
+	cells, _ := m.PolygonCover(sliver, q, 4, Intersecting)
+	if len(cells) == 0 {
+		t.Fatal("Intersecting cover of a sliver is empty")
+	}
+	for _, c := range cells {
+		v := m.Decode(c.Code)
+		// The segment runs from x 1.5 to 9.5 at half the slope
+		if v[0] < 1 || v[0] > 9 || absDiff(v[1], (v[0]+1)/2) > 1 {
+			t.Errorf("Sliver cover includes the cell at %v, off the segment", v)
+		}
+	}
+}
+
+func TestPolygonCoverRejects(t *testing.T) {
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 16, Bits: 4}, Axis{Min: 0, Max: 16, Bits: 4})
+	if _, err := New(3, 16).PolygonCover(testRings, q, 2, Interior); !errors.Is(err, ErrNot2D) {
+		t.Errorf("PolygonCover in 3 dimensions returned %v", err)
+	}
+	if _, err := New(2, 16).PolygonCover(testRings, nil, 2, Interior); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("PolygonCover without a quantizer returned %v", err)
+	}
+	if _, err := New(2, 16).PolygonCover(testRings, q, 5, Interior); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("PolygonCover at level 5 returned %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+	Web map tiles at zoom z divide the 2D grid into 2^z x 2^z cells, i.e., cells at level z, with dimension 0 the tile column x and dimension 1 the tile row y, growing southward.  A quadkey digit is x's bit plus twice y's, which is exactly one interleaved group of a 2D code, so a quadkey is the code's top z groups written most significant first.
+*/
+
+// Quadkey of the tile at level containing code, of level digits.
+func (m *Morton) ToQuadkey(code uint64, level uint8) (string, error) {
+	if m.Dimensions != 2 {
+		return "", ErrNot2D
+	}
+	if level > m.Bits() {
+		return "", ErrInvalidLevel
+	}
+	if err := m.checkLayout(); err != nil {
+		return "", err
+	}
+
+	var s strings.Builder
+	for i := uint8(0); i < level; i++ {
+		s.WriteByte('0' + byte(code>>(2*uint(m.Bits()-1-i))&3))
+	}
+	return s.String(), nil
+}
+
+// Code of the quadkey's tile on a grid of 2^level cells per dimension, level being the quadkey's length.
+func FromQuadkey(quadkey string) (code uint64, level uint8, err error) {
+	if len(quadkey) > 32 {
+		return 0, 0, errors.New("Quadkey must have at most 32 digits")
+	}
+	for i := 0; i < len(quadkey); i++ {
+		c := quadkey[i]
+		if c < '0' || c > '3' {
+			return 0, 0, errors.New(fmt.Sprint("Invalid quadkey digit ", string(c), " at position ", i))
+		}
+		code = code<<2 | uint64(c-'0')
+	}
+	return code, uint8(len(quadkey)), nil
+}
+
+// Tile at zoom level containing code.
+func (m *Morton) ToTileXYZ(code uint64, level uint8) (x, y uint32, z uint8, err error) {
+	if m.Dimensions != 2 {
+		return 0, 0, 0, ErrNot2D
+	}
+	if level > m.Bits() {
+		return 0, 0, 0, ErrInvalidLevel
+	}
+	shift := m.Bits() - level
+	return m.Project(code, 0) >> shift, m.Project(code, 1) >> shift, level, nil
+}
+
+// Cell code of tile (x, y) at zoom z, at level z on this Morton's grid.
+func (m *Morton) FromTileXYZ(x, y uint32, z uint8) (uint64, error) {
+	if m.Dimensions != 2 {
+		return 0, ErrNot2D
+	}
+	if z > m.Bits() {
+		return 0, ErrInvalidLevel
+	}
+	if err := m.checkLayout(); err != nil {
+		return 0, err
+	}
+	if uint64(x) >= 1<<z || uint64(y) >= 1<<z {
+		return 0, errors.New(fmt.Sprint("Tile ", x, ", ", y, " lies outside zoom level ", z))
+	}
+	shift := m.Bits() - z
+	return Dilate(x<<shift, 2) | Dilate(y<<shift, 2)<<1, nil
+}
@@ -0,0 +1,129 @@
+package morton
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Quadkey of tile x, y at zoom z, as in Bing Maps' TileXYToQuadKey.
+func referenceQuadkey(x, y uint32, z uint8) string {
+	var s strings.Builder
+	for i := z; i > 0; i-- {
+		digit := byte('0')
+		mask := uint32(1) << (i - 1)
+		if x&mask != 0 {
+			digit++
+		}
+		if y&mask != 0 {
+			digit += 2
+		}
+		s.WriteByte(digit)
+	}
+	return s.String()
+}
+
+func TestQuadkeyExamples(t *testing.T) {
+	m := New(2, 1<<16)
+	for _, tc := range []struct {
+		x, y    uint32
+		z       uint8
+		quadkey string
+	}{
+		{0, 0, 1, "0"},
+		{1, 0, 1, "1"},
+		{0, 1, 1, "2"},
+		{1, 1, 1, "3"},
+		// From the Bing Maps tile system documentation
+		{3, 5, 3, "213"},
+		// From mercantile's documentation
+		{486, 332, 10, "0313102310"},
+		{0, 0, 0, ""},
+	} {
+		if got := referenceQuadkey(tc.x, tc.y, tc.z); got != tc.quadkey {
+			t.Fatalf("reference quadkey of %v, %v, %v = %q, want %q", tc.x, tc.y, tc.z, got, tc.quadkey)
+		}
+		code, err := m.FromTileXYZ(tc.x, tc.y, tc.z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := m.ToQuadkey(code, tc.z); err != nil || got != tc.quadkey {
+			t.Errorf("ToQuadkey of tile %v, %v, %v = %q, %v; want %q", tc.x, tc.y, tc.z, got, err, tc.quadkey)
+		}
+		if x, y, z, err := m.ToTileXYZ(code, tc.z); err != nil || x != tc.x || y != tc.y || z != tc.z {
+			t.Errorf("ToTileXYZ(FromTileXYZ(%v, %v, %v)) = %v, %v, %v, %v", tc.x, tc.y, tc.z, x, y, z, err)
+		}
+
+		fc, level, err := FromQuadkey(tc.quadkey)
+		if err != nil || level != tc.z {
+			t.Fatalf("FromQuadkey(%q) = %v, %v, %v", tc.quadkey, fc, level, err)
+		}
+		if v := New(2, 1<<tc.z).Decode(fc); v[0] != tc.x || v[1] != tc.y {
+			t.Errorf("FromQuadkey(%q) decodes to %v, want %v, %v", tc.quadkey, v, tc.x, tc.y)
+		}
+	}
+}
+
+func TestQuadkeyRoundTrip(t *testing.T) {
+	m := New(2, 1<<16)
+	r := rand.New(rand.NewSource(142))
+	grids := make(map[uint8]*Morton)
+	for n := 0; n < 2000; n++ {
+		z := uint8(r.Intn(17))
+		x, y := uint32(r.Int63n(1<<z)), uint32(r.Int63n(1<<z))
+		code, err := m.FromTileXYZ(x, y, z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Any code within the tile gives the tile's quadkey
+		code |= uint64(r.Int63()) & lowMask(2*(16-z))
+		q, err := m.ToQuadkey(code, z)
+		if err != nil || q != referenceQuadkey(x, y, z) {
+			t.Fatalf("ToQuadkey of tile %v, %v, %v = %q, %v; want %q", x, y, z, q, err, referenceQuadkey(x, y, z))
+		}
+		if tx, ty, _, _ := m.ToTileXYZ(code, z); tx != x || ty != y {
+			t.Fatalf("ToTileXYZ of tile %v, %v, %v = %v, %v", x, y, z, tx, ty)
+		}
+		fc, level, err := FromQuadkey(q)
+		if err != nil || level != z {
+			t.Fatalf("FromQuadkey(%q) = %v, %v, %v", q, fc, level, err)
+		}
+		if grids[z] == nil {
+			grids[z] = New(2, 1<<z)
+		}
+		if got, _ := grids[z].ToQuadkey(fc, z); got != q {
+			t.Fatalf("ToQuadkey(FromQuadkey(%q)) = %q", q, got)
+		}
+	}
+}
+
+func TestQuadkeyRejects(t *testing.T) {
+	m := New(2, 1<<8)
+	if _, err := m.ToQuadkey(0, 9); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("ToQuadkey beyond the bit budget returned %v, want ErrInvalidLevel", err)
+	}
+	if _, _, _, err := m.ToTileXYZ(0, 9); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("ToTileXYZ beyond the bit budget returned %v, want ErrInvalidLevel", err)
+	}
+	if _, err := m.FromTileXYZ(0, 0, 9); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("FromTileXYZ beyond the bit budget returned %v, want ErrInvalidLevel", err)
+	}
+	for _, tile := range [][3]uint32{{8, 0, 3}, {0, 8, 3}, {1, 0, 0}} {
+		if _, err := m.FromTileXYZ(tile[0], tile[1], uint8(tile[2])); err == nil {
+			t.Errorf("FromTileXYZ(%v, %v, %v) succeeded", tile[0], tile[1], tile[2])
+		}
+	}
+	three := New(3, 8)
+	if _, err := three.ToQuadkey(0, 1); !errors.Is(err, ErrNot2D) {
+		t.Errorf("ToQuadkey in 3 dimensions returned %v, want ErrNot2D", err)
+	}
+	if _, err := three.FromTileXYZ(0, 0, 1); !errors.Is(err, ErrNot2D) {
+		t.Errorf("FromTileXYZ in 3 dimensions returned %v, want ErrNot2D", err)
+	}
+	for _, q := range []string{"0124", "21a", strings.Repeat("1", 33)} {
+		if _, _, err := FromQuadkey(q); err == nil {
+			t.Errorf("FromQuadkey(%q) succeeded", q)
+		}
+	}
+}
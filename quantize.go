@@ -0,0 +1,185 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Float range of one quantized dimension, divided either into 2^Bits equal cells or into cells CellSize wide, the last of which may extend beyond Max.  Exactly one of Bits and CellSize is set.  Policy applies to values outside the range.
+type Axis struct {
+	Min, Max float64
+	Bits     uint8
+	CellSize float64
+	Policy   RangePolicy
+}
+
+// Treatment of values outside an Axis's range, before quantization.  NaN is always an error.
+type RangePolicy uint8
+
+const (
+	// Out of range values, including infinities, are errors.
+	PolicyError RangePolicy = iota
+	// Values saturate to Min or Max, so into the first or last cell.
+	PolicyClamp
+	// Values are taken modulo the range, for periodic axes such as longitude, on which Max is Min again.  Infinities are errors.
+	PolicyWrap
+)
+
+// Maps float coordinates onto the integer grid, one Axis per dimension.
+type Quantizer struct {
+	Axes []Axis
+}
+
+func NewQuantizer(axes ...Axis) (*Quantizer, error) {
+	for i, a := range axes {
+		if !(a.Min < a.Max) || math.IsInf(a.Max-a.Min, 0) {
+			return nil, errors.New(fmt.Sprint("Range of axis ", i, " is empty or unbounded"))
+		}
+		switch {
+		case a.Policy > PolicyWrap:
+			return nil, errors.New(fmt.Sprint("Axis ", i, " has an unknown range policy"))
+		case a.Bits != 0 && a.CellSize != 0:
+			return nil, errors.New(fmt.Sprint("Axis ", i, " sets both Bits and CellSize"))
+		case a.Bits > 32:
+			return nil, errors.New(fmt.Sprint("Axis ", i, " must have between 1 and 32 bits"))
+		case a.Bits == 0 && !(a.CellSize > 0):
+			return nil, errors.New(fmt.Sprint("Axis ", i, " requires Bits or a positive CellSize"))
+		case a.Bits == 0 && !((a.Max-a.Min)/a.CellSize <= 1<<32):
+			return nil, errors.New(fmt.Sprint("Axis ", i, " has more than 2^32 cells"))
+		}
+	}
+	return &Quantizer{append([]Axis(nil), axes...)}, nil
+}
+
+// Number of cells.
+func (a Axis) cells() uint64 {
+	if a.Bits != 0 {
+		return 1 << a.Bits
+	}
+	n := (a.Max - a.Min) / a.CellSize
+	if r := math.Round(n); math.Abs(n-r) <= 1e-9*r {
+		// A range that is a whole number of cells, but for rounding
+		n = r
+	}
+	return uint64(math.Max(1, math.Ceil(n)))
+}
+
+// Float coordinate of the low edge of cell c, which may be one past the last cell.
+func (a Axis) edge(c uint64) float64 {
+	if a.Bits == 0 {
+		return a.Min + float64(c)*a.CellSize
+	}
+	if c == a.cells() {
+		return a.Max
+	}
+	return a.Min + (a.Max-a.Min)*(float64(c)/float64(a.cells()))
+}
+
+// Cell of v, which must lie within [Min, Max].
+func (a Axis) cell(v float64) uint32 {
+	var c float64
+	if a.Bits == 0 {
+		c = (v - a.Min) / a.CellSize
+	} else {
+		c = (v - a.Min) / (a.Max - a.Min) * float64(a.cells())
+	}
+	return uint32(math.Min(c, float64(a.cells()-1)))
+}
+
+// Applies the axis's policy to v, component i, returning a value within [Min, Max].
+func (a Axis) admit(i int, v float64) (float64, error) {
+	switch {
+	case math.IsNaN(v):
+		return 0, errors.New(fmt.Sprint("Component ", i, " is NaN"))
+	case v >= a.Min && v <= a.Max && (a.Policy != PolicyWrap || v < a.Max):
+		return v, nil
+	case a.Policy == PolicyClamp:
+		return math.Min(math.Max(v, a.Min), a.Max), nil
+	case a.Policy == PolicyWrap && !math.IsInf(v, 0):
+		span := a.Max - a.Min
+		w := math.Mod(v-a.Min, span)
+		if w < 0 {
+			w += span
+		}
+		if w >= span {
+			// Rounding brought a value just below Min up to Max
+			w = 0
+		}
+		return a.Min + w, nil
+	}
+	return 0, fmt.Errorf("%w.  Component %v, %v, is outside [%v, %v]", ErrComponentOverflow, i, v, a.Min, a.Max)
+}
+
+// Cells of each value, rounding down: a value on an edge between cells belongs to the upper one, up to floating point rounding, except that Max itself lies in the last cell.  Values outside [Min, Max] are treated according to each axis's Policy; NaN is an error.
+func (q *Quantizer) Quantize(values []float64) ([]uint32, error) {
+	if len(values) != len(q.Axes) {
+		return nil, ErrDimensionMismatch
+	}
+
+	cells := make([]uint32, len(values))
+	for i, v := range values {
+		a := q.Axes[i]
+		v, err := a.admit(i, v)
+		if err != nil {
+			return nil, err
+		}
+		cells[i] = a.cell(v)
+	}
+	return cells, nil
+}
+
+// Minimum float coordinates of each cell.
+func (q *Quantizer) Dequantize(cells []uint32) []float64 {
+	values := make([]float64, len(cells))
+	for i, c := range cells {
+		values[i] = q.Axes[i].edge(uint64(c))
+	}
+	return values
+}
+
+// Center float coordinates of each cell, within half a cell of every value quantized to it.
+func (q *Quantizer) CellCenter(cells []uint32) []float64 {
+	values := make([]float64, len(cells))
+	for i, c := range cells {
+		a := q.Axes[i]
+		values[i] = (a.edge(uint64(c)) + a.edge(uint64(c)+1)) / 2
+	}
+	return values
+}
+
+// Float bounds of each cell: values in [min, max) quantize to it, as does Max in the last cell.
+func (q *Quantizer) CellBounds(cells []uint32) (min, max []float64) {
+	min, max = make([]float64, len(cells)), make([]float64, len(cells))
+	for i, c := range cells {
+		min[i], max[i] = q.Axes[i].edge(uint64(c)), q.Axes[i].edge(uint64(c)+1)
+	}
+	return
+}
+
+// Quantizes values through q, after the configured transform if any (see WithTransform), and encodes the cells.
+func (m *Morton) EncodeFloat(values []float64, q *Quantizer) (uint64, error) {
+	if m.transform != nil {
+		if len(values) != len(m.transform.Offset) {
+			return 0, ErrDimensionMismatch
+		}
+		values = m.transform.forward(values)
+	}
+	cells, err := q.Quantize(values)
+	if err != nil {
+		return 0, err
+	}
+	return m.Encode(cells)
+}
+
+// Decodes code into the centers of its cells under q, mapped back through the configured transform if any.
+func (m *Morton) DecodeFloat(code uint64, q *Quantizer) ([]float64, error) {
+	if len(q.Axes) != int(m.Dimensions) {
+		return nil, ErrDimensionMismatch
+	}
+	center := q.CellCenter(m.Decode(code))
+	if m.transform != nil {
+		return m.transform.inverse(center), nil
+	}
+	return center, nil
+}
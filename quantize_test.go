@@ -0,0 +1,213 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestQuantizeBoundaries(t *testing.T) {
+	q, err := NewQuantizer(Axis{Min: -1, Max: 1, Bits: 3}, Axis{Min: 0, Max: 10, CellSize: 2.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		values []float64
+		want   []uint32
+	}{
+		{[]float64{-1, 0}, []uint32{0, 0}},
+		// Edges between cells belong to the upper cell
+		{[]float64{-0.75, 2.5}, []uint32{1, 1}},
+		{[]float64{math.Nextafter(-0.75, -1), math.Nextafter(2.5, 0)}, []uint32{0, 0}},
+		// Max lies in the last cell, not one past it
+		{[]float64{1, 10}, []uint32{7, 3}},
+		{[]float64{math.Nextafter(1, 0), math.Nextafter(10, 0)}, []uint32{7, 3}},
+	} {
+		got, err := q.Quantize(tc.values)
+		if err != nil || !equalUint32s(got, tc.want) {
+			t.Errorf("Quantize(%v) = %v, %v; want %v", tc.values, got, err, tc.want)
+		}
+	}
+
+	min, max := q.CellBounds([]uint32{7, 3})
+	if min[0] != 0.75 || max[0] != 1 || min[1] != 7.5 || max[1] != 10 {
+		t.Errorf("CellBounds of the last cells = %v, %v", min, max)
+	}
+	if got := q.Dequantize([]uint32{0, 2}); got[0] != -1 || got[1] != 5 {
+		t.Errorf("Dequantize([0 2]) = %v, want [-1 5]", got)
+	}
+	if got := q.CellCenter([]uint32{4, 1}); got[0] != 0.125 || got[1] != 3.75 {
+		t.Errorf("CellCenter([4 1]) = %v, want [0.125 3.75]", got)
+	}
+}
+
+func TestQuantizeRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(138))
+	for _, axes := range [][]Axis{
+		{{Min: -180, Max: 180, Bits: 16}, {Min: -90, Max: 90, Bits: 16}},
+		{{Min: 0, Max: 1, Bits: 32}, {Min: 3, Max: 7, CellSize: 0.3}},
+		// Tiny ranges, away from zero
+		{{Min: 1e6, Max: 1e6 + 1e-3, Bits: 10}, {Min: -1e-9, Max: 1e-9, CellSize: 1e-12}},
+	} {
+		q, err := NewQuantizer(axes...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := 0; n < 1000; n++ {
+			values := make([]float64, len(axes))
+			for i, a := range axes {
+				values[i] = a.Min + r.Float64()*(a.Max-a.Min)
+			}
+			if n == 0 {
+				values[0], values[1] = axes[0].Max, axes[1].Min
+			}
+			cells, err := q.Quantize(values)
+			if err != nil {
+				t.Fatalf("Quantize(%v): %v", values, err)
+			}
+			min, max := q.CellBounds(cells)
+			center := q.CellCenter(cells)
+			for i, v := range values {
+				if c := uint64(cells[i]); c >= axes[i].cells() {
+					t.Fatalf("%v quantized to cell %v of %v", v, c, axes[i].cells())
+				}
+				if v < min[i] || v > max[i] {
+					t.Fatalf("%v lies outside its cell, %v to %v", v, min[i], max[i])
+				}
+				// Half a cell, up to the rounding of the center itself
+				half := (max[i] - min[i]) / 2
+				ulp := math.Nextafter(center[i], math.Inf(1)) - center[i]
+				if diff := math.Abs(v - center[i]); diff > half+2*ulp {
+					t.Fatalf("%v is %v from its cell center %v, beyond half a cell, %v", v, diff, center[i], half)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeFloat(t *testing.T) {
+	m := New(2, 1<<8)
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 64, Bits: 8}, Axis{Min: -8, Max: 8, Bits: 8})
+	code, err := m.EncodeFloat([]float64{10.1, -0.05}, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells, _ := q.Quantize([]float64{10.1, -0.05})
+	if want, _ := m.Encode(cells); code != want {
+		t.Errorf("EncodeFloat = %v, want %v, the code of %v", code, want, cells)
+	}
+	got, err := m.DecodeFloat(code, q)
+	if err != nil || math.Abs(got[0]-10.1) > 0.125 || math.Abs(got[1]+0.05) > 1.0/32 {
+		t.Errorf("DecodeFloat(EncodeFloat([10.1 -0.05])) = %v, %v", got, err)
+	}
+
+	if _, err := m.EncodeFloat([]float64{1}, q); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("EncodeFloat of one component returned %v, want ErrDimensionMismatch", err)
+	}
+	// Cells beyond the tables still fail to encode
+	wide, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 9}, Axis{Min: 0, Max: 1, Bits: 8})
+	if _, err := m.EncodeFloat([]float64{1, 1}, wide); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("EncodeFloat beyond the tables returned %v, want ErrComponentOverflow", err)
+	}
+	if _, err := New(3, 4).DecodeFloat(0, q); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("DecodeFloat in 3 dimensions returned %v, want ErrDimensionMismatch", err)
+	}
+}
+
+func TestNewQuantizerRejects(t *testing.T) {
+	for _, a := range []Axis{
+		{Min: 1, Max: 1, Bits: 4},
+		{Min: 2, Max: 1, Bits: 4},
+		{Min: 0, Max: math.Inf(1), Bits: 4},
+		{Min: 0, Max: math.NaN(), Bits: 4},
+		{Min: 0, Max: 1},
+		{Min: 0, Max: 1, Bits: 33},
+		{Min: 0, Max: 1, Bits: 4, CellSize: 0.1},
+		{Min: 0, Max: 1, CellSize: -1},
+		{Min: 0, Max: 1, CellSize: 1e-12},
+		{Min: 0, Max: 1, Bits: 4, Policy: PolicyWrap + 1},
+	} {
+		if _, err := NewQuantizer(a); err == nil {
+			t.Errorf("NewQuantizer(%+v) succeeded", a)
+		}
+	}
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 4})
+	if _, err := q.Quantize([]float64{0.5, 0.5}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Quantize of 2 components on 1 axis returned %v, want ErrDimensionMismatch", err)
+	}
+}
+
+func TestQuantizePolicies(t *testing.T) {
+	below, above := math.Nextafter(0, -1), math.Nextafter(10, 11)
+	for _, tc := range []struct {
+		policy RangePolicy
+		values []float64
+		want   []uint32 // nil for an error
+	}{
+		{PolicyError, []float64{0, 10}, []uint32{0, 15}},
+		{PolicyError, []float64{below}, nil},
+		{PolicyError, []float64{above}, nil},
+		{PolicyError, []float64{math.Inf(1)}, nil},
+		{PolicyError, []float64{math.NaN()}, nil},
+
+		{PolicyClamp, []float64{below, above}, []uint32{0, 15}},
+		{PolicyClamp, []float64{-1e300, 1e300}, []uint32{0, 15}},
+		{PolicyClamp, []float64{math.Inf(-1), math.Inf(1)}, []uint32{0, 15}},
+		{PolicyClamp, []float64{math.NaN()}, nil},
+
+		// On a periodic axis Max is Min again
+		{PolicyWrap, []float64{10, above, -1e-3}, []uint32{0, 0, 15}},
+		// Rounding carries values just below Min to Max, so back to Min
+		{PolicyWrap, []float64{below}, []uint32{0}},
+		{PolicyWrap, []float64{-10, 20, 12.5, -2.5}, []uint32{0, 0, 4, 12}},
+		{PolicyWrap, []float64{math.Inf(1)}, nil},
+		{PolicyWrap, []float64{math.Inf(-1)}, nil},
+		{PolicyWrap, []float64{math.NaN()}, nil},
+	} {
+		axes := make([]Axis, len(tc.values))
+		for i := range axes {
+			axes[i] = Axis{Min: 0, Max: 10, Bits: 4, Policy: tc.policy}
+		}
+		q, err := NewQuantizer(axes...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := q.Quantize(tc.values)
+		switch {
+		case tc.want == nil && err == nil:
+			t.Errorf("policy %v: Quantize(%v) = %v, want an error", tc.policy, tc.values, got)
+		case tc.want != nil && (err != nil || !equalUint32s(got, tc.want)):
+			t.Errorf("policy %v: Quantize(%v) = %v, %v; want %v", tc.policy, tc.values, got, err, tc.want)
+		}
+	}
+}
+
+func TestQuantizeNaNNamesDimension(t *testing.T) {
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 4, Policy: PolicyClamp}, Axis{Min: 0, Max: 1, Bits: 4, Policy: PolicyWrap})
+	if _, err := q.Quantize([]float64{0.5, math.NaN()}); err == nil || !strings.Contains(err.Error(), "Component 1") {
+		t.Errorf("Quantize of NaN in dimension 1 returned %v", err)
+	}
+	q, _ = NewQuantizer(Axis{Min: 0, Max: 1, Bits: 4})
+	if _, err := q.Quantize([]float64{2}); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("Quantize beyond the range returned %v, want ErrComponentOverflow", err)
+	}
+}
+
+func TestQuantizeLongitudeWraps(t *testing.T) {
+	q, _ := NewQuantizer(Axis{Min: -180, Max: 180, Bits: 8, Policy: PolicyWrap})
+	east, _ := q.Quantize([]float64{179.9})
+	west, _ := q.Quantize([]float64{-179.9})
+	if east[0] != 255 || west[0] != 0 {
+		t.Errorf("179.9 and -179.9 quantize to %v and %v, want the adjacent cells 255 and 0", east[0], west[0])
+	}
+	for _, lon := range []float64{180.1, -179.9 + 360*3, -179.9 - 360} {
+		if got, _ := q.Quantize([]float64{lon}); got[0] != 0 {
+			t.Errorf("%v wraps to cell %v, want 0", lon, got[0])
+		}
+	}
+	if got, _ := q.Quantize([]float64{180}); got[0] != 0 {
+		t.Errorf("180 wraps to cell %v, want 0", got[0])
+	}
+}
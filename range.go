@@ -0,0 +1,166 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Inclusive range of codes.
+type Range struct {
+	Lo, Hi uint64
+}
+
+// Validates an inclusive query box against the configuration.
+func (m *Morton) checkBox(min, max []uint32) error {
+	d := int(m.Dimensions)
+	if len(min) != d || len(max) != d {
+		return ErrDimensionMismatch
+	}
+	if len(m.Tables) < d {
+		return errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	for i := range min {
+		if min[i] > max[i] {
+			return errors.New(fmt.Sprint("Box minimum exceeds its maximum in dimension ", i))
+		}
+	}
+	return nil
+}
+
+// Decomposes the inclusive box [min, max] into the minimal ascending list of disjoint code ranges covering exactly the codes within it.
+func (m *Morton) RangeDecompose(min, max []uint32) ([]Range, error) {
+	if err := m.checkBox(min, max); err != nil {
+		return nil, err
+	}
+	if err := m.checkLayout(); err != nil {
+		return nil, err
+	}
+
+	var ranges []Range
+	m.walk(m.Bits(), boxTest(min, max), func(code uint64, level uint8, c Containment) bool {
+		s, _ := m.cellShift(level)
+		hi := code | lowMask(s)
+		if n := len(ranges); n > 0 && ranges[n-1].Hi+1 == code {
+			ranges[n-1].Hi = hi
+		} else {
+			ranges = append(ranges, Range{code, hi})
+		}
+		return true
+	})
+	return ranges, nil
+}
+
+// Reports whether code lies within the inclusive box [min, max], without decoding each component separately.
+func (m *Morton) InBox(code uint64, min, max []uint32) bool {
+	for i := range min {
+		v := m.Project(code, uint8(i))
+		if v < min[i] || v > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Smallest code, not less than code, within the inclusive box [min, max], or false if there is none.  This is the BIGMIN computation of Tropf and Herzog, letting a scan over sorted codes skip ahead when it leaves the box.  Layouts other than the default return false.
+func (m *Morton) BigMin(code uint64, min, max []uint32) (uint64, bool) {
+	if m.checkBox(min, max) != nil || !m.defaultLayout() {
+		return 0, false
+	}
+	zmin, err := m.Encode(min)
+	if err != nil {
+		return 0, false
+	}
+	zmax, err := m.Encode(max)
+	if err != nil || code > zmax {
+		return 0, false
+	}
+	if code <= zmin {
+		return zmin, true
+	}
+
+	d := m.Dimensions
+	var bigmin uint64
+	found := false
+	for p := int(m.codeBits()) - 1; p >= 0; p-- {
+		pos := uint(p)
+		bit := uint64(1) << pos
+		// Lower bits of the same dimension
+		lower := MaskForDimension(d, uint8(pos%uint(d))) & (bit - 1)
+
+		switch code&bit != 0 {
+		case false:
+			switch {
+			case zmin&bit != 0:
+				return zmin, true
+			case zmax&bit != 0:
+				// Candidate in the upper half; continue within the lower half
+				bigmin, found = zmin&^lower|bit, true
+				zmax = zmax&^bit | lower
+			}
+		case true:
+			switch {
+			case zmax&bit == 0:
+				return bigmin, found
+			case zmin&bit == 0:
+				zmin = zmin&^lower | bit
+			}
+		}
+	}
+	// code itself is within the box
+	return code, true
+}
+
+// Calls fn for every code from lo to hi inclusive, in ascending order, until fn returns false, without holding the codes in memory.
+func (m *Morton) RangeScan(lo, hi uint64, fn func(code uint64) bool) error {
+	if lo > hi {
+		return errors.New(fmt.Sprintf("Range start 0x%x exceeds its end 0x%x", lo, hi))
+	}
+	for code := lo; fn(code) && code != hi; code++ {
+	}
+	return nil
+}
+
+// Codes, in ascending order, of the vectors whose dimensions in fixedValues equal the values given, the other dimensions spanning [0, Capacity()]: a hyperplane, or a lower dimensional slice in general, of (Capacity() + 1)^free codes.
+func (m *Morton) LayerQuery(fixedValues map[int]uint32) ([]uint64, error) {
+	d := int(m.Dimensions)
+	min, max := make([]uint32, d), make([]uint32, d)
+	for i := range max {
+		max[i] = m.Capacity()
+	}
+	for dim, v := range fixedValues {
+		if dim < 0 || dim >= d {
+			return nil, errors.New(fmt.Sprint("Fixed dimension ", dim, " exceeds the number of dimensions"))
+		}
+		if dim < len(m.Tables) && v >= m.Tables[dim].Length {
+			return nil, fmt.Errorf("%w.  Component %v", ErrComponentOverflow, dim)
+		}
+		min[dim], max[dim] = v, v
+	}
+
+	ranges, err := m.RangeDecompose(min, max)
+	if err != nil {
+		return nil, err
+	}
+	var codes []uint64
+	for _, r := range ranges {
+		m.RangeScan(r.Lo, r.Hi, func(code uint64) bool {
+			codes = append(codes, code)
+			return true
+		})
+	}
+	return codes, nil
+}
+
+// Codes within the inclusive box [min, max], in their input order, filtered with InBox rather than full decodes.
+func (m *Morton) ClipToBox(codes []uint64, min, max []uint32) ([]uint64, error) {
+	if err := m.checkBox(min, max); err != nil {
+		return nil, err
+	}
+	clipped := make([]uint64, 0, len(codes))
+	for _, c := range codes {
+		if m.InBox(c, min, max) {
+			clipped = append(clipped, c)
+		}
+	}
+	return clipped, nil
+}
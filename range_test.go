@@ -0,0 +1,208 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRangeScan(t *testing.T) {
+	m := New(3, 8)
+	for _, r := range []Range{{0, 0}, {5, 5}, {3, 40}, {0, 511}} {
+		var got []uint64
+		if err := m.RangeScan(r.Lo, r.Hi, func(code uint64) bool {
+			got = append(got, code)
+			return true
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if uint64(len(got)) != r.Hi-r.Lo+1 {
+			t.Fatalf("RangeScan(%v, %v) visited %v codes", r.Lo, r.Hi, len(got))
+		}
+		for i, code := range got {
+			if code != r.Lo+uint64(i) {
+				t.Fatalf("RangeScan(%v, %v) visited %v at %v", r.Lo, r.Hi, code, i)
+			}
+		}
+	}
+
+	// Scanning a box's decomposition visits exactly the codes in the box
+	codes, vectors := allCodes(t, m)
+	rnd := rand.New(rand.NewSource(136))
+	for n := 0; n < 50; n++ {
+		min, max := randomBox(rnd, m)
+		ranges, err := m.RangeDecompose(min, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []uint64
+		for _, r := range ranges {
+			m.RangeScan(r.Lo, r.Hi, func(code uint64) bool {
+				got = append(got, code)
+				return true
+			})
+		}
+		var want []uint64
+		for i, v := range vectors {
+			if inBox(v, min, max) {
+				want = append(want, codes[i])
+			}
+		}
+		if !equalUint64s(got, want) {
+			t.Fatalf("scanning the box %v to %v visited %v, want %v", min, max, got, want)
+		}
+	}
+}
+
+func TestRangeScanStops(t *testing.T) {
+	m := New(2, 16)
+	for _, k := range []int{1, 2, 7, 100} {
+		var got []uint64
+		m.RangeScan(10, 200, func(code uint64) bool {
+			got = append(got, code)
+			return len(got) < k
+		})
+		if len(got) != k || got[k-1] != 10+uint64(k-1) {
+			t.Errorf("RangeScan stopping after %v calls visited %v", k, got)
+		}
+	}
+
+	// The end of the code space does not wrap around
+	calls := 0
+	m.RangeScan(math.MaxUint64-2, math.MaxUint64, func(uint64) bool {
+		calls++
+		return calls < 10
+	})
+	if calls != 3 {
+		t.Errorf("RangeScan to the last code made %v calls, want 3", calls)
+	}
+
+	if err := m.RangeScan(5, 4, func(uint64) bool { t.Error("fn called on an empty range"); return true }); err == nil {
+		t.Error("RangeScan(5, 4) succeeded")
+	}
+}
+
+func TestRangeScanAllocations(t *testing.T) {
+	m := New(2, 16)
+	var sum uint64
+	fn := func(code uint64) bool {
+		sum += code
+		return true
+	}
+	if allocs := testing.AllocsPerRun(10, func() { m.RangeScan(0, 10000, fn) }); allocs != 0 {
+		t.Errorf("RangeScan allocates %v times per run", allocs)
+	}
+}
+
+func TestLayerQuery(t *testing.T) {
+	m := New(3, 6)
+	codes, vectors := allCodes(t, m)
+	for _, fixed := range []map[int]uint32{
+		{},
+		{2: 0},
+		{0: 5},
+		{1: 3, 2: 4},
+		{0: 1, 1: 2, 2: 3},
+	} {
+		got, err := m.LayerQuery(fixed)
+		if err != nil {
+			t.Fatalf("LayerQuery(%v): %v", fixed, err)
+		}
+		count := 1
+		for i := len(fixed); i < 3; i++ {
+			count *= int(m.Capacity()) + 1
+		}
+		if len(got) != count {
+			t.Errorf("LayerQuery(%v) returned %v codes, want %v", fixed, len(got), count)
+		}
+		for i, code := range got {
+			if i > 0 && got[i-1] >= code {
+				t.Fatalf("LayerQuery(%v) is out of order at %v", fixed, i)
+			}
+			v := m.Decode(code)
+			for dim, x := range fixed {
+				if v[dim] != x {
+					t.Fatalf("LayerQuery(%v) returned %v", fixed, v)
+				}
+			}
+		}
+
+		// Exactly the codes matching by brute force
+		var want []uint64
+	vectors:
+		for n, v := range vectors {
+			for dim, x := range fixed {
+				if v[dim] != x {
+					continue vectors
+				}
+			}
+			want = append(want, codes[n])
+		}
+		if !equalUint64s(got, want) {
+			t.Errorf("LayerQuery(%v) = %v, want %v", fixed, got, want)
+		}
+	}
+}
+
+func TestLayerQueryRejects(t *testing.T) {
+	m := New(2, 8)
+	for _, fixed := range []map[int]uint32{{2: 0}, {-1: 0}, {0: 8}} {
+		if _, err := m.LayerQuery(fixed); err == nil {
+			t.Errorf("LayerQuery(%v) succeeded", fixed)
+		}
+	}
+}
+
+func TestClipToBox(t *testing.T) {
+	m := New(3, 8)
+	codes, vectors := allCodes(t, m)
+	r := rand.New(rand.NewSource(142))
+	for n := 0; n < 100; n++ {
+		min, max := randomBox(r, m)
+		var want []uint64
+		for i, v := range vectors {
+			if inBox(v, min, max) {
+				want = append(want, codes[i])
+			}
+		}
+		// The whole grid clips to the box's codes, in order
+		got, err := m.ClipToBox(codes, min, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalUint64s(got, want) {
+			t.Fatalf("ClipToBox of the grid to %v, %v = %v, want %v", min, max, got, want)
+		}
+		// Codes already in the box are kept as they are
+		if again, _ := m.ClipToBox(want, min, max); !equalUint64s(again, want) {
+			t.Fatalf("ClipToBox of the box's own codes = %v, want %v", again, want)
+		}
+	}
+
+	// A prefix scan's overshoot is discarded
+	min, max := []uint32{1, 1, 1}, []uint32{2, 2, 2}
+	lo, hi := m.MustEncode(min), m.MustEncode(max)
+	var scan []uint64
+	m.RangeScan(lo, hi, func(code uint64) bool {
+		scan = append(scan, code)
+		return true
+	})
+	got, _ := m.ClipToBox(scan, min, max)
+	if len(got) != 8 || len(scan) <= 8 {
+		t.Errorf("ClipToBox of the %v codes from %v to %v kept %v, want 8", len(scan), lo, hi, len(got))
+	}
+}
+
+func TestClipToBoxEdges(t *testing.T) {
+	m := New(2, 16)
+	if got, err := m.ClipToBox(nil, []uint32{0, 0}, []uint32{3, 3}); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("ClipToBox of no codes = %v, %v; want an empty slice", got, err)
+	}
+	if _, err := m.ClipToBox([]uint64{1}, []uint32{0}, []uint32{3, 3}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("ClipToBox to a short corner returned %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := m.ClipToBox([]uint64{1}, []uint32{4, 0}, []uint32{3, 3}); err == nil {
+		t.Error("ClipToBox with min exceeding max succeeded")
+	}
+}
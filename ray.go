@@ -0,0 +1,117 @@
+package morton
+
+import (
+	"errors"
+	"math"
+)
+
+// Steps a ray through the voxels of a Morton's domain, voxel v spanning [v, v+1) in each dimension, in visitation order.
+type RayTraverser struct {
+	m         *Morton
+	origin    []float64
+	direction []float64
+	cell      []uint32
+	t, tExit  float64
+	done      bool
+}
+
+// Traverser for the ray origin + t*direction, t >= 0, which may start outside the domain.
+func NewRayTraverser(m *Morton, origin, direction []float64) (*RayTraverser, error) {
+	d := int(m.Dimensions)
+	if len(origin) != d || len(direction) != d {
+		return nil, ErrDimensionMismatch
+	}
+	if d == 0 || len(m.Tables) < d {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+
+	r := &RayTraverser{
+		m:         m,
+		origin:    append([]float64(nil), origin...),
+		direction: append([]float64(nil), direction...),
+		cell:      make([]uint32, d),
+		tExit:     math.Inf(1),
+	}
+
+	// Clip to the domain, slab by slab
+	zero := true
+	for i, o := range origin {
+		dir, size := direction[i], float64(m.Tables[i].Length)
+		if math.IsNaN(o) || math.IsNaN(dir) || math.IsInf(o, 0) || math.IsInf(dir, 0) {
+			return nil, errors.New("Ray origin and direction must be finite")
+		}
+		if dir == 0 {
+			if o < 0 || o >= size {
+				r.done = true
+			}
+			continue
+		}
+		zero = false
+		t0, t1 := (0-o)/dir, (size-o)/dir
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		r.t, r.tExit = math.Max(r.t, t0), math.Min(r.tExit, t1)
+	}
+	if zero {
+		return nil, errors.New("Ray direction must not be zero")
+	}
+	if r.t >= r.tExit {
+		r.done = true
+	}
+	if r.done {
+		return r, nil
+	}
+
+	// The voxel occupied just after entry: a descending ray on a boundary is already below it
+	for i, o := range origin {
+		size := float64(m.Tables[i].Length)
+		p := o + direction[i]*r.t
+		v := math.Floor(p)
+		if direction[i] < 0 && v == p {
+			v--
+		}
+		r.cell[i] = uint32(math.Min(math.Max(v, 0), size-1))
+	}
+	return r, nil
+}
+
+// Parameter at which the ray leaves the current voxel along dimension i.
+func (r *RayTraverser) boundary(i int) float64 {
+	switch dir := r.direction[i]; {
+	case dir > 0:
+		return (float64(r.cell[i]) + 1 - r.origin[i]) / dir
+	case dir < 0:
+		return (float64(r.cell[i]) - r.origin[i]) / dir
+	}
+	return math.Inf(1)
+}
+
+// Code of the next voxel and the parameter at which the ray enters it, or false once the ray has left the domain.  Where the ray crosses several boundaries at once, as along an exact diagonal, it steps across them together.
+func (r *RayTraverser) Next() (code uint64, tEnter float64, ok bool) {
+	if r.done {
+		return 0, 0, false
+	}
+	code, _ = r.m.Encode(r.cell)
+	tEnter = r.t
+
+	next := math.Inf(1)
+	for i := range r.cell {
+		next = math.Min(next, r.boundary(i))
+	}
+	if next >= r.tExit {
+		r.done = true
+		return code, tEnter, true
+	}
+	for i := range r.cell {
+		if r.boundary(i) == next {
+			if r.direction[i] > 0 {
+				r.cell[i]++
+			} else {
+				r.cell[i]--
+			}
+		}
+	}
+	r.t = next
+	return code, tEnter, true
+}
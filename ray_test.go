@@ -0,0 +1,132 @@
+package morton
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+type rayStep struct {
+	v      []uint32
+	tEnter float64
+}
+
+func traverse(t *testing.T, m *Morton, origin, direction []float64) []rayStep {
+	t.Helper()
+	r, err := NewRayTraverser(m, origin, direction)
+	if err != nil {
+		t.Fatalf("NewRayTraverser(%v, %v): %v", origin, direction, err)
+	}
+	var steps []rayStep
+	for {
+		code, tEnter, ok := r.Next()
+		if !ok {
+			return steps
+		}
+		steps = append(steps, rayStep{m.Decode(code), tEnter})
+		if len(steps) > 1000 {
+			t.Fatalf("Ray %v, %v does not leave the domain", origin, direction)
+		}
+	}
+}
+
+func checkSteps(t *testing.T, steps []rayStep, want [][]uint32, tEnter []float64) {
+	t.Helper()
+	if len(steps) != len(want) {
+		t.Fatalf("Ray visits %v voxels, want %v", len(steps), len(want))
+	}
+	for i, s := range steps {
+		if !equalUint32s(s.v, want[i]) || math.Abs(s.tEnter-tEnter[i]) > 1e-12 {
+			t.Errorf("Step %v is %v at %v, want %v at %v", i, s.v, s.tEnter, want[i], tEnter[i])
+		}
+	}
+}
+
+func TestRayAlongAxes(t *testing.T) {
+	m := New(3, 4)
+	steps := traverse(t, m, []float64{0.5, 1.5, 2.5}, []float64{1, 0, 0})
+	checkSteps(t, steps, [][]uint32{{0, 1, 2}, {1, 1, 2}, {2, 1, 2}, {3, 1, 2}}, []float64{0, 0.5, 1.5, 2.5})
+
+	// Descending, from a boundary: voxel 2 spans [2, 3), so the ray starts below it
+	steps = traverse(t, m, []float64{1.5, 3, 0.5}, []float64{0, -2, 0})
+	checkSteps(t, steps, [][]uint32{{1, 2, 0}, {1, 1, 0}, {1, 0, 0}}, []float64{0, 0.5, 1})
+}
+
+func TestRayDiagonal(t *testing.T) {
+	// An exact diagonal crosses every corner, stepping all dimensions together
+	m := New(2, 4)
+	steps := traverse(t, m, []float64{0, 0}, []float64{1, 1})
+	checkSteps(t, steps, [][]uint32{{0, 0}, {1, 1}, {2, 2}, {3, 3}}, []float64{0, 1, 2, 3})
+
+	steps = traverse(t, m, []float64{4, 0}, []float64{-1, 1})
+	checkSteps(t, steps, [][]uint32{{3, 0}, {2, 1}, {1, 2}, {0, 3}}, []float64{0, 1, 2, 3})
+}
+
+func TestRayOutside(t *testing.T) {
+	m := New(2, 4)
+	// Entering through the x = 0 face at t = 2
+	steps := traverse(t, m, []float64{-2, 1.5}, []float64{1, 0.25})
+	if len(steps) == 0 || steps[0].tEnter != 2 || !equalUint32s(steps[0].v, []uint32{0, 2}) {
+		t.Fatalf("Ray from outside starts with %v", steps)
+	}
+	// Missing, pointing away, and parallel outside
+	for _, ray := range [][2][]float64{
+		{{-2, 5}, {1, 0.1}},
+		{{5, 1}, {1, 0}},
+		{{1, 4}, {1, 0}},
+	} {
+		if steps := traverse(t, m, ray[0], ray[1]); len(steps) != 0 {
+			t.Errorf("Ray %v, %v visits %v", ray[0], ray[1], steps)
+		}
+	}
+}
+
+func TestRayTraversal(t *testing.T) {
+	m := New(3, 8)
+	r := rand.New(rand.NewSource(129))
+	for n := 0; n < 500; n++ {
+		origin, direction := make([]float64, 3), make([]float64, 3)
+		for i := range origin {
+			origin[i] = r.Float64()*12 - 2
+			direction[i] = r.NormFloat64()
+		}
+		steps := traverse(t, m, origin, direction)
+		for i, s := range steps {
+			// Each voxel holds the ray between its entry and the next
+			end := s.tEnter + 1e-3
+			if i+1 < len(steps) {
+				end = steps[i+1].tEnter
+				if end <= s.tEnter {
+					t.Fatalf("Ray %v, %v enters step %v at %v, not after %v", origin, direction, i+1, end, s.tEnter)
+				}
+			}
+			mid := (s.tEnter + end) / 2
+			for k := range origin {
+				if p := origin[k] + mid*direction[k]; p < float64(s.v[k]) || p >= float64(s.v[k])+1 {
+					t.Fatalf("Ray %v, %v at %v is outside step %v, %v", origin, direction, mid, i, s.v)
+				}
+			}
+			if i > 0 {
+				for k := range s.v {
+					if absDiff(s.v[k], steps[i-1].v[k]) > 1 {
+						t.Fatalf("Ray %v, %v jumps from %v to %v", origin, direction, steps[i-1].v, s.v)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRayRejects(t *testing.T) {
+	m := New(2, 4)
+	for _, ray := range [][2][]float64{
+		{{0}, {1, 0}},
+		{{0, 0}, {0, 0}},
+		{{math.NaN(), 0}, {1, 0}},
+		{{0, 0}, {math.Inf(1), 0}},
+	} {
+		if _, err := NewRayTraverser(m, ray[0], ray[1]); err == nil {
+			t.Errorf("NewRayTraverser(%v, %v) succeeded", ray[0], ray[1])
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Per-dimension translation in the dilated domain.
+type translation struct {
+	masks, steps, limits []uint64
+	negative, tooFar     []bool
+}
+
+func (m *Morton) translation(delta []int32) (*translation, error) {
+	d := int(m.Dimensions)
+	if len(delta) != d {
+		return nil, ErrDimensionMismatch
+	}
+	if len(m.Tables) < d {
+		return nil, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if err := m.checkLayout(); err != nil {
+		return nil, err
+	}
+
+	t := &translation{make([]uint64, d), make([]uint64, d), make([]uint64, d), make([]bool, d), make([]bool, d)}
+	for i, v := range delta {
+		if m.Tables[i].Length == 0 {
+			return nil, errors.New(fmt.Sprint("Table ", i, " is empty"))
+		}
+		dim := uint8(i)
+		step := uint32(v)
+		if v < 0 {
+			step = uint32(-int64(v))
+			t.negative[i] = true
+		}
+		t.tooFar[i] = step >= m.Tables[i].Length
+		t.masks[i] = MaskForDimension(m.Dimensions, dim)
+		t.steps[i] = Dilate(step, m.Dimensions) << dim
+		t.limits[i] = Dilate(m.Tables[i].Length-1, m.Dimensions) << dim
+	}
+	return t, nil
+}
+
+// Translates code lane by lane, returning the first dimension left the domain, or -1.  Dilation preserves order, so the bounds are checked on the dilated lanes.
+func (t *translation) apply(code uint64) (uint64, int) {
+	result := code
+	for i, mask := range t.masks {
+		if t.tooFar[i] {
+			return 0, i
+		}
+		lane := code & mask
+		var moved uint64
+		if t.negative[i] {
+			if moved = SubDilated(lane, t.steps[i], mask); moved > lane {
+				return 0, i
+			}
+		} else if moved = AddDilated(lane, t.steps[i], mask); moved < lane {
+			return 0, i
+		}
+		if moved > t.limits[i] {
+			return 0, i
+		}
+		result = result&^mask | moved
+	}
+	return result, -1
+}
+
+// Translates each code in place by delta, with dilated arithmetic rather than decoding and encoding each.  If any code would leave the tables, codes is left unchanged and the error names the first such code's index and dimension.  Like the other dilated helpers, this assumes the default layout.
+func (m *Morton) Rebase(codes []uint64, delta []int32) error {
+	t, err := m.translation(delta)
+	if err != nil {
+		return err
+	}
+	for i, c := range codes {
+		if _, dim := t.apply(c); dim >= 0 {
+			return fmt.Errorf("%w.  Code %v would leave the domain in dimension %v", ErrComponentOverflow, i, dim)
+		}
+	}
+	for i, c := range codes {
+		codes[i], _ = t.apply(c)
+	}
+	return nil
+}
+
+// Indices of the codes that Rebase would find leaving the tables, without changing codes.
+func (m *Morton) RebaseDryRun(codes []uint64, delta []int32) ([]int, error) {
+	t, err := m.translation(delta)
+	if err != nil {
+		return nil, err
+	}
+	var out []int
+	for i, c := range codes {
+		if _, dim := t.apply(c); dim >= 0 {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+// Encodes the position delta away from base, e.g. the next point of a trajectory stored as a difference from the last.  Unlike Rebase, it decodes and encodes, so any layout works; overflowing components are errors.
+func (m *Morton) EncodeRelative(base uint64, delta []int32) (uint64, error) {
+	if len(delta) != int(m.Dimensions) {
+		return 0, ErrDimensionMismatch
+	}
+	v := m.Decode(base)
+	for i, dv := range delta {
+		c := int64(v[i]) + int64(dv)
+		if c < 0 || c > math.MaxUint32 {
+			return 0, fmt.Errorf("%w.  Component %v of %v moved by %v", ErrComponentOverflow, i, v[i], dv)
+		}
+		v[i] = uint32(c)
+	}
+	code, err := m.Encode(v)
+	if err != nil {
+		return 0, err
+	}
+	return code, nil
+}
+
+// Component-wise difference of code from base, the inverse of EncodeRelative: EncodeRelative(base, DecodeRelative(base, code)) == code.  Differences beyond int32 are errors.
+func (m *Morton) DecodeRelative(base, code uint64) ([]int32, error) {
+	from, to := m.Decode(base), m.Decode(code)
+	delta := make([]int32, len(to))
+	for i := range delta {
+		dv := int64(to[i]) - int64(from[i])
+		if dv < math.MinInt32 || dv > math.MaxInt32 {
+			return nil, fmt.Errorf("%w.  Difference %v of component %v exceeds int32", ErrComponentOverflow, dv, i)
+		}
+		delta[i] = int32(dv)
+	}
+	return delta, nil
+}
@@ -0,0 +1,181 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Result of translating vector by delta within tables of length, by decoding; ok is false if it leaves them.
+func translated(vector []uint32, delta []int32, length uint32) (moved []uint32, ok bool) {
+	moved = make([]uint32, len(vector))
+	for i, v := range vector {
+		c := int64(v) + int64(delta[i])
+		if c < 0 || c >= int64(length) {
+			return nil, false
+		}
+		moved[i] = uint32(c)
+	}
+	return moved, true
+}
+
+func TestRebase(t *testing.T) {
+	m := New(3, 10)
+	codes, vectors := allCodes(t, m)
+	r := rand.New(rand.NewSource(137))
+	for n := 0; n < 300; n++ {
+		delta := []int32{int32(r.Intn(21) - 10), int32(r.Intn(21) - 10), int32(r.Intn(21) - 10)}
+		var want []int
+		for i, v := range vectors {
+			if _, ok := translated(v, delta, 10); !ok {
+				want = append(want, i)
+			}
+		}
+		got, err := m.RebaseDryRun(codes, delta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("RebaseDryRun by %v reported %v codes, want %v", delta, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("RebaseDryRun by %v reported %v at %v, want %v", delta, got[i], i, want[i])
+			}
+		}
+
+		// Rebase the codes staying within the tables, boundary points included
+		var kept []uint64
+		var moved [][]uint32
+		for i, v := range vectors {
+			if w, ok := translated(v, delta, 10); ok {
+				kept = append(kept, codes[i])
+				moved = append(moved, w)
+			}
+		}
+		if err := m.Rebase(kept, delta); err != nil {
+			t.Fatalf("Rebase by %v: %v", delta, err)
+		}
+		for i, c := range kept {
+			if want, _ := m.Encode(moved[i]); c != want {
+				t.Fatalf("Rebase by %v gave %v, want %v for %v", delta, c, want, moved[i])
+			}
+		}
+	}
+}
+
+func TestRebaseWithoutPartialWrites(t *testing.T) {
+	m := New(2, 16)
+	var codes []uint64
+	for _, v := range [][]uint32{{0, 0}, {3, 4}, {14, 15}, {15, 0}, {2, 2}} {
+		c, _ := m.Encode(v)
+		codes = append(codes, c)
+	}
+	before := append([]uint64(nil), codes...)
+	err := m.Rebase(codes, []int32{1, 0})
+	if !errors.Is(err, ErrComponentOverflow) {
+		t.Fatalf("Rebase of {15, 0} by {1, 0} returned %v, want ErrComponentOverflow", err)
+	}
+	if want := "Code 3 would leave the domain in dimension 0"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Rebase error %q does not name %q", err, want)
+	}
+	if !equalUint64s(codes, before) {
+		t.Errorf("failed Rebase changed the codes to %v", codes)
+	}
+
+	if bad, _ := m.RebaseDryRun(codes, []int32{-1, 1}); len(bad) != 2 || bad[0] != 0 || bad[1] != 2 {
+		t.Errorf("RebaseDryRun by {-1, 1} = %v, want [0 2]", bad)
+	}
+	if !equalUint64s(codes, before) {
+		t.Errorf("RebaseDryRun changed the codes to %v", codes)
+	}
+}
+
+func TestRebaseRejects(t *testing.T) {
+	m := New(2, 16)
+	if err := m.Rebase([]uint64{0}, []int32{1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Rebase by a 1D delta returned %v, want ErrDimensionMismatch", err)
+	}
+	if _, err := m.RebaseDryRun(nil, []int32{1, 2, 3}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("RebaseDryRun by a 3D delta returned %v, want ErrDimensionMismatch", err)
+	}
+	// Moves as long as the tables leave them from anywhere
+	if bad, _ := m.RebaseDryRun([]uint64{0, 5}, []int32{0, -16}); len(bad) != 2 {
+		t.Errorf("RebaseDryRun by -16 reported %v", bad)
+	}
+}
+
+func TestRelativeRoundTrip(t *testing.T) {
+	for _, m := range []*Morton{New(3, 1<<10), New(2, 1000, WithGrayCode())} {
+		d := int(m.Dimensions)
+		length := int(m.Tables[0].Length)
+		r := rand.New(rand.NewSource(149))
+		random := func() uint64 {
+			v := make([]uint32, d)
+			for i := range v {
+				v[i] = uint32(r.Intn(length))
+			}
+			return m.MustEncode(v)
+		}
+		for n := 0; n < 1000; n++ {
+			base, code := random(), random()
+			delta, err := m.DecodeRelative(base, code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := m.EncodeRelative(base, delta); err != nil || got != code {
+				t.Fatalf("EncodeRelative(%v, DecodeRelative(%v, %v) = %v) = %v, %v", base, base, code, delta, got, err)
+			}
+			from, to := m.Decode(base), m.Decode(code)
+			for i := range delta {
+				if int64(from[i])+int64(delta[i]) != int64(to[i]) {
+					t.Fatalf("DecodeRelative(%v, %v) = %v, not %v - %v", base, code, delta, to, from)
+				}
+			}
+		}
+	}
+}
+
+func TestRelativeTrajectory(t *testing.T) {
+	m := New(2, 1<<16)
+	r := rand.New(rand.NewSource(149))
+	// A random walk stored as its first code and the steps between
+	path := []uint64{m.MustEncode([]uint32{30000, 30000})}
+	for n := 0; n < 1000; n++ {
+		step := []int32{int32(r.Intn(21) - 10), int32(r.Intn(21) - 10)}
+		next, err := m.EncodeRelative(path[n], step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path = append(path, next)
+	}
+	deltas := make([][]int32, len(path)-1)
+	for i := range deltas {
+		deltas[i], _ = m.DecodeRelative(path[i], path[i+1])
+	}
+	code := path[0]
+	for i, delta := range deltas {
+		code, _ = m.EncodeRelative(code, delta)
+		if code != path[i+1] {
+			t.Fatalf("replaying step %v gave %v, want %v", i, code, path[i+1])
+		}
+	}
+}
+
+func TestRelativeRejects(t *testing.T) {
+	m := New(2, 16)
+	base := m.MustEncode([]uint32{2, 15})
+	for _, delta := range [][]int32{{-3, 0}, {0, 1}, {14, 0}, {math.MinInt32, 0}} {
+		if _, err := m.EncodeRelative(base, delta); !errors.Is(err, ErrComponentOverflow) {
+			t.Errorf("EncodeRelative of [2 15] by %v returned %v, want ErrComponentOverflow", delta, err)
+		}
+	}
+	if got, err := m.EncodeRelative(base, []int32{-2, -15}); err != nil || got != 0 {
+		t.Errorf("EncodeRelative of [2 15] back to the origin = %v, %v", got, err)
+	}
+	if _, err := m.EncodeRelative(base, []int32{1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("EncodeRelative by a 1D delta returned %v, want ErrDimensionMismatch", err)
+	}
+}
@@ -0,0 +1,128 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// Field indices of a struct type by dimension, from `morton:"<dimension>"` tags.
+type structPlan struct {
+	fields []int
+	err    error
+}
+
+// Plans are cached per type, so reflection over tags is paid once.
+var structPlans sync.Map
+
+func planFor(t reflect.Type) *structPlan {
+	if p, ok := structPlans.Load(t); ok {
+		return p.(*structPlan)
+	}
+
+	p := &structPlan{}
+	byDim := map[int]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("morton")
+		if !ok {
+			continue
+		}
+
+		dim, err := strconv.Atoi(tag)
+		switch {
+		case err != nil || dim < 0:
+			p.err = errors.New(fmt.Sprint("Field ", f.Name, " has an invalid morton tag, ", strconv.Quote(tag)))
+		case !f.IsExported():
+			p.err = errors.New(fmt.Sprint("Field ", f.Name, " is unexported"))
+		case f.Type.Kind() != reflect.Uint8 && f.Type.Kind() != reflect.Uint16 && f.Type.Kind() != reflect.Uint32:
+			p.err = errors.New(fmt.Sprint("Field ", f.Name, " is ", f.Type, "; only uint8, uint16 and uint32 fields are supported"))
+		default:
+			if prev, dup := byDim[dim]; dup {
+				p.err = errors.New(fmt.Sprint("Fields ", t.Field(prev).Name, " and ", f.Name, " are both tagged as dimension ", dim))
+			}
+			byDim[dim] = i
+		}
+		if p.err != nil {
+			break
+		}
+	}
+
+	if p.err == nil {
+		p.fields = make([]int, len(byDim))
+		for dim := range p.fields {
+			i, ok := byDim[dim]
+			if !ok {
+				p.err = errors.New(fmt.Sprint("No field is tagged as dimension ", dim))
+				break
+			}
+			p.fields[dim] = i
+		}
+	}
+
+	actual, _ := structPlans.LoadOrStore(t, p)
+	return actual.(*structPlan)
+}
+
+// Plan for v's struct type, checked against the number of dimensions.
+func (m *Morton) plan(v reflect.Value) (*structPlan, error) {
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprint("Expected a struct, got ", v.Type()))
+	}
+	p := planFor(v.Type())
+	if p.err != nil {
+		return nil, p.err
+	}
+	if len(p.fields) != int(m.Dimensions) {
+		return nil, fmt.Errorf("%w.  %v tags %v dimensions", ErrDimensionMismatch, v.Type(), len(p.fields))
+	}
+	return p, nil
+}
+
+// Encodes the fields of src, a struct or pointer to one, tagged `morton:"0"`, `morton:"1"` and so on, one per dimension.  Tagged fields must be exported uint8, uint16 or uint32 fields.
+func (m *Morton) EncodeStruct(src any) (uint64, error) {
+	v := reflect.ValueOf(src)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, errors.New("EncodeStruct requires a non-nil source")
+		}
+		v = v.Elem()
+	}
+	p, err := m.plan(v)
+	if err != nil {
+		return 0, err
+	}
+
+	vector := make([]uint32, len(p.fields))
+	for dim, i := range p.fields {
+		vector[dim] = uint32(v.Field(i).Uint())
+	}
+	return m.Encode(vector)
+}
+
+// Decodes code into the tagged fields of dst, which must be a non-nil pointer to a struct.  If a component does not fit its field, dst is left unchanged.  See EncodeStruct.
+func (m *Morton) DecodeStruct(code uint64, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("DecodeStruct requires a non-nil pointer destination")
+	}
+	v = v.Elem()
+	p, err := m.plan(v)
+	if err != nil {
+		return err
+	}
+
+	// Every component is checked before any field is written, so a failure leaves dst unchanged
+	vector := m.Decode(code)
+	for dim, i := range p.fields {
+		if v.Field(i).OverflowUint(uint64(vector[dim])) {
+			return fmt.Errorf("%w.  Component %v does not fit field %v", ErrComponentOverflow, dim, v.Type().Field(i).Name)
+		}
+	}
+	for dim, i := range p.fields {
+		v.Field(i).SetUint(uint64(vector[dim]))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package morton
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type voxel struct {
+	X  uint32 `morton:"0"`
+	Y  uint16 `morton:"1"`
+	Z  uint8  `morton:"2"`
+	ID string
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	m := New(3, 256)
+	src := voxel{X: 200, Y: 17, Z: 255, ID: "a"}
+	code, err := m.EncodeStruct(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := m.Encode([]uint32{200, 17, 255}); code != want {
+		t.Errorf("EncodeStruct(%+v) = %v, want %v", src, code, want)
+	}
+	if byPointer, err := m.EncodeStruct(&src); err != nil || byPointer != code {
+		t.Errorf("EncodeStruct of a pointer = %v, %v; want %v", byPointer, err, code)
+	}
+
+	dst := voxel{ID: "kept"}
+	if err := m.DecodeStruct(code, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.X != 200 || dst.Y != 17 || dst.Z != 255 || dst.ID != "kept" {
+		t.Errorf("DecodeStruct gave %+v", dst)
+	}
+}
+
+func TestStructTagOrder(t *testing.T) {
+	// Tags, not field order, pick dimensions
+	type yx struct {
+		Y uint32 `morton:"1"`
+		X uint32 `morton:"0"`
+	}
+	m := New(2, 16)
+	code, err := m.EncodeStruct(yx{Y: 3, X: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := m.Encode([]uint32{5, 3}); code != want {
+		t.Errorf("EncodeStruct = %v, want %v", code, want)
+	}
+}
+
+func TestStructRejects(t *testing.T) {
+	m := New(2, 16)
+	type missing struct {
+		X uint32 `morton:"0"`
+		Y uint32 `morton:"2"`
+	}
+	type duplicate struct {
+		X uint32 `morton:"0"`
+		Y uint32 `morton:"0"`
+	}
+	type unexported struct {
+		X uint32 `morton:"0"`
+		y uint32 `morton:"1"`
+	}
+	type signed struct {
+		X uint32 `morton:"0"`
+		Y int32  `morton:"1"`
+	}
+	type badTag struct {
+		X uint32 `morton:"0"`
+		Y uint32 `morton:"y"`
+	}
+	for _, tc := range []struct {
+		src  any
+		text string
+	}{
+		{missing{}, "dimension 1"},
+		{duplicate{}, "both tagged"},
+		{unexported{}, "unexported"},
+		{signed{}, "int32"},
+		{badTag{}, "invalid morton tag"},
+		{42, "Expected a struct"},
+	} {
+		if _, err := m.EncodeStruct(tc.src); err == nil || !strings.Contains(err.Error(), tc.text) {
+			t.Errorf("EncodeStruct(%T) returned %v, want an error mentioning %q", tc.src, err, tc.text)
+		}
+	}
+
+	if _, err := m.EncodeStruct(voxel{}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("EncodeStruct of 3 tags in 2 dimensions returned %v", err)
+	}
+	if _, err := m.EncodeStruct((*voxel)(nil)); err == nil {
+		t.Error("EncodeStruct of a nil pointer succeeded")
+	}
+}
+
+func TestDecodeStructRejects(t *testing.T) {
+	m := New(3, 1024)
+	var dst voxel
+	if err := m.DecodeStruct(0, dst); err == nil {
+		t.Error("DecodeStruct into a value succeeded")
+	}
+	if err := m.DecodeStruct(0, (*voxel)(nil)); err == nil {
+		t.Error("DecodeStruct into a nil pointer succeeded")
+	}
+	// Z is a uint8, and the fields before it are left as they were
+	dst = voxel{X: 7, Y: 8, Z: 9, ID: "kept"}
+	code, _ := m.Encode([]uint32{1, 2, 300})
+	if err := m.DecodeStruct(code, &dst); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("DecodeStruct of 300 into a uint8 returned %v", err)
+	}
+	if dst != (voxel{X: 7, Y: 8, Z: 9, ID: "kept"}) {
+		t.Errorf("failed DecodeStruct changed the destination to %+v", dst)
+	}
+}
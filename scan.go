@@ -0,0 +1,141 @@
+package morton
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// Key range for a key-value store iterator: Start is inclusive and End exclusive.  A nil End is unbounded.
+type KeyRange struct {
+	Start, End []byte
+}
+
+type scanConfig struct {
+	maxRanges int
+}
+
+// Configures PlanScan.
+type ScanOption func(*scanConfig)
+
+// Limits the plan to at most n key ranges, by merging the ranges separated by the smallest gaps.  Merged ranges over-scan, so callers must filter the keys read, e.g. with InBox.
+func WithMaxRanges(n int) ScanOption {
+	return func(c *scanConfig) {
+		c.maxRanges = n
+	}
+}
+
+// Key of code under prefix: the prefix followed by the big-endian code bytes.
+func codeKey(prefix []byte, code uint64) []byte {
+	k := make([]byte, len(prefix)+8)
+	copy(k, prefix)
+	binary.BigEndian.PutUint64(k[len(prefix):], code)
+	return k
+}
+
+// Smallest key greater than every key with the given prefix, or nil if none exists.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i]++; end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// Exclusive end key following code hi, carrying into the prefix when hi is the largest code.
+func codeKeyEnd(prefix []byte, hi uint64) []byte {
+	if hi == ^uint64(0) {
+		return prefixEnd(prefix)
+	}
+	return codeKey(prefix, hi+1)
+}
+
+// Plans the iterator ranges retrieving exactly the keys, under prefix, of codes within the inclusive box [min, max].  See RangeDecompose and WithMaxRanges.
+func (m *Morton) PlanScan(prefix []byte, min, max []uint32, opts ...ScanOption) ([]KeyRange, error) {
+	var cfg scanConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ranges, err := m.RangeDecompose(min, max)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.maxRanges > 0 {
+		ranges = mergeRanges(ranges, cfg.maxRanges)
+	}
+
+	keys := make([]KeyRange, len(ranges))
+	for i, r := range ranges {
+		keys[i] = KeyRange{codeKey(prefix, r.Lo), codeKeyEnd(prefix, r.Hi)}
+	}
+	return keys, nil
+}
+
+// Merges ascending ranges across the smallest gaps until at most n remain.
+func mergeRanges(ranges []Range, n int) []Range {
+	if len(ranges) <= n {
+		return ranges
+	}
+
+	// Indices of gaps, by size; the len-n smallest are closed
+	gaps := make([]int, len(ranges)-1)
+	for i := range gaps {
+		gaps[i] = i
+	}
+	sort.Slice(gaps, func(a, b int) bool {
+		ga := ranges[gaps[a]+1].Lo - ranges[gaps[a]].Hi
+		gb := ranges[gaps[b]+1].Lo - ranges[gaps[b]].Hi
+		return ga < gb
+	})
+	closed := make([]bool, len(gaps))
+	for _, g := range gaps[:len(ranges)-n] {
+		closed[g] = true
+	}
+
+	merged := []Range{ranges[0]}
+	for i, r := range ranges[1:] {
+		if closed[i] {
+			merged[len(merged)-1].Hi = r.Hi
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+// Next key to seek to when a scan reaches currentKey, a prefix followed by 8 code bytes: currentKey itself if its code is within the box, otherwise the key of the next code within it (see BigMin).  It returns false once no codes within the box remain, and for layouts other than the default.
+func (m *Morton) NextSeek(currentKey []byte, min, max []uint32) ([]byte, bool) {
+	if len(currentKey) < 8 {
+		return nil, false
+	}
+	prefix := currentKey[:len(currentKey)-8]
+	code := binary.BigEndian.Uint64(currentKey[len(prefix):])
+
+	next, ok := m.BigMin(code, min, max)
+	if !ok {
+		return nil, false
+	}
+	return codeKey(prefix, next), true
+}
+
+// Key prefix, of the 8 byte big-endian code keys, shared by every descendant of the cell at level containing code.  The prefix is exact when the cell's bits end on a byte boundary; otherwise it holds only the whole bytes, selecting a superset of the descendants, and CellKeyRange gives the exact key range.  An invalid level, or a layout other than the default, returns a nil inexact prefix.
+func (m *Morton) PrefixForCell(code uint64, level uint8) (prefix []byte, exact bool) {
+	s, err := m.cellShift(level)
+	if err != nil {
+		return nil, false
+	}
+	n := 64 - uint(s)
+	return codeKey(nil, code)[:n/8], n%8 == 0
+}
+
+// Key range, [start, end), of the 8 byte big-endian code keys of every descendant of the cell at level containing code.
+func (m *Morton) CellKeyRange(code uint64, level uint8) (start, end []byte, err error) {
+	s, err := m.cellShift(level)
+	if err != nil {
+		return
+	}
+	base := code &^ lowMask(s)
+	return codeKey(nil, base), codeKeyEnd(nil, base|lowMask(s)), nil
+}
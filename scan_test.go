@@ -0,0 +1,207 @@
+package morton
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Sorted in-memory key-value store keys: every point of m under prefix, and neighbouring prefixes' keys that scans must not reach.
+func testStore(t *testing.T, m *Morton, prefix []byte) [][]byte {
+	codes, _ := allCodes(t, m)
+	var keys [][]byte
+	for _, c := range codes {
+		keys = append(keys, codeKey(prefix, c))
+	}
+	for _, other := range [][]byte{[]byte("ptr/"), []byte("pts0"), []byte("pts")} {
+		keys = append(keys, codeKey(other, 0), codeKey(other, 1<<64-1))
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	return keys
+}
+
+// Index of the first key at or after seek.
+func storeSeek(keys [][]byte, seek []byte) int {
+	return sort.Search(len(keys), func(i int) bool { return bytes.Compare(keys[i], seek) >= 0 })
+}
+
+func inBoxKeys(t *testing.T, m *Morton, prefix []byte, min, max []uint32) map[string]bool {
+	want := make(map[string]bool)
+	codes, vectors := allCodes(t, m)
+	for n, v := range vectors {
+		in := true
+		for i := range v {
+			in = in && v[i] >= min[i] && v[i] <= max[i]
+		}
+		if in {
+			want[string(codeKey(prefix, codes[n]))] = true
+		}
+	}
+	return want
+}
+
+func TestPlanScan(t *testing.T) {
+	m := New(2, 32)
+	prefix := []byte("pts/")
+	keys := testStore(t, m, prefix)
+	r := rand.New(rand.NewSource(122))
+	for n := 0; n < 100; n++ {
+		min, max := make([]uint32, 2), make([]uint32, 2)
+		for i := range min {
+			a, b := uint32(r.Intn(32)), uint32(r.Intn(32))
+			if a > b {
+				a, b = b, a
+			}
+			min[i], max[i] = a, b
+		}
+		want := inBoxKeys(t, m, prefix, min, max)
+
+		for _, limit := range []int{0, 3, 1} {
+			plan, err := m.PlanScan(prefix, min, max, WithMaxRanges(limit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if limit > 0 && len(plan) > limit {
+				t.Fatalf("PlanScan(%v, %v) with at most %v ranges planned %v", min, max, limit, len(plan))
+			}
+			got := make(map[string]bool)
+			for _, kr := range plan {
+				for i := storeSeek(keys, kr.Start); i < len(keys) && (kr.End == nil || bytes.Compare(keys[i], kr.End) < 0); i++ {
+					if !bytes.HasPrefix(keys[i], prefix) {
+						t.Fatalf("PlanScan(%v, %v) reached key %q outside the prefix", min, max, keys[i])
+					}
+					code := binary.BigEndian.Uint64(keys[i][len(prefix):])
+					// Only merged ranges over-scan, and are filtered
+					switch {
+					case m.InBox(code, min, max):
+						got[string(keys[i])] = true
+					case limit == 0:
+						t.Fatalf("PlanScan(%v, %v) retrieved code %v outside the box", min, max, code)
+					}
+				}
+			}
+			if len(got) != len(want) {
+				t.Fatalf("PlanScan(%v, %v) with at most %v ranges retrieved %v in-box keys, want %v", min, max, limit, len(got), len(want))
+			}
+		}
+	}
+}
+
+func TestPlanScanLastCode(t *testing.T) {
+	// The largest code's exclusive end carries into the prefix
+	m := New(2, 1<<21)
+	plan, err := m.PlanScan([]byte{'a'}, []uint32{1<<21 - 1, 1<<21 - 1}, []uint32{1<<21 - 1, 1<<21 - 1})
+	if err != nil || len(plan) != 1 {
+		t.Fatalf("PlanScan of the last cell = %v, %v", plan, err)
+	}
+	code, _ := m.Encode([]uint32{1<<21 - 1, 1<<21 - 1})
+	if want := codeKey([]byte{'a'}, code+1); !bytes.Equal(plan[0].End, want) {
+		t.Errorf("End = % x, want % x", plan[0].End, want)
+	}
+	if got := codeKeyEnd([]byte{'a'}, 1<<64-1); !bytes.Equal(got, []byte{'b'}) {
+		t.Errorf("codeKeyEnd of the maximum code = % x, want 62", got)
+	}
+	if got := codeKeyEnd(nil, 1<<64-1); got != nil {
+		t.Errorf("codeKeyEnd of the maximum code without a prefix = % x, want unbounded", got)
+	}
+}
+
+// Skip-ahead scanning: read keys in order, seeking past runs outside the box.
+func TestNextSeek(t *testing.T) {
+	m := New(2, 32)
+	prefix := []byte("pts/")
+	keys := testStore(t, m, prefix)
+	r := rand.New(rand.NewSource(1222))
+	for n := 0; n < 100; n++ {
+		min := []uint32{uint32(r.Intn(20)), uint32(r.Intn(20))}
+		max := []uint32{min[0] + uint32(r.Intn(12)), min[1] + uint32(r.Intn(12))}
+		want := inBoxKeys(t, m, prefix, min, max)
+
+		got := make(map[string]bool)
+		seek, ok := m.NextSeek(codeKey(prefix, 0), min, max)
+		for ok {
+			i := storeSeek(keys, seek)
+			if i == len(keys) || !bytes.HasPrefix(keys[i], prefix) {
+				break
+			}
+			code := binary.BigEndian.Uint64(keys[i][len(prefix):])
+			if !m.InBox(code, min, max) {
+				t.Fatalf("NextSeek in %v, %v led to code %v outside the box", min, max, code)
+			}
+			got[string(keys[i])] = true
+			seek, ok = m.NextSeek(codeKey(prefix, code+1), min, max)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Skip-ahead scan of %v, %v read %v keys, want %v", min, max, len(got), len(want))
+		}
+	}
+
+	if _, ok := m.NextSeek([]byte{1, 2}, []uint32{0, 0}, []uint32{1, 1}); ok {
+		t.Error("NextSeek of a short key succeeded")
+	}
+	if _, ok := New(2, 32, WithGrayCode()).NextSeek(codeKey(nil, 0), []uint32{0, 0}, []uint32{1, 1}); ok {
+		t.Error("NextSeek of a Gray coded Morton succeeded")
+	}
+}
+
+func TestPrefixForCell(t *testing.T) {
+	m := New(2, 256) // Cells at level L hold 48+2L high bits, a whole number of bytes when L is 0, 4 or 8
+	r := rand.New(rand.NewSource(123))
+	for level := uint8(0); level <= m.Bits(); level++ {
+		for n := 0; n < 4; n++ {
+			cell := uint64(r.Intn(1 << 16))
+			prefix, exact := m.PrefixForCell(cell, level)
+			if want := level%4 == 0; exact != want {
+				t.Errorf("PrefixForCell at level %v is exact: %v", level, exact)
+			}
+			start, end, err := m.CellKeyRange(cell, level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			base, _ := m.AtLevel(cell, level)
+
+			for code := uint64(0); code < 1<<16; code++ {
+				key := codeKey(nil, code)
+				inCell, _ := m.AtLevel(code, level)
+				descendant := inCell == base
+				if selected := bytes.HasPrefix(key, prefix); exact && selected != descendant || descendant && !selected {
+					t.Fatalf("Level %v prefix % x selects code %v: %v, a descendant of %v: %v", level, prefix, code, selected, cell, descendant)
+				}
+				if inRange := bytes.Compare(key, start) >= 0 && bytes.Compare(key, end) < 0; inRange != descendant {
+					t.Fatalf("Level %v key range [% x, % x) selects code %v: %v, a descendant of %v: %v", level, start, end, code, inRange, cell, descendant)
+				}
+			}
+		}
+	}
+}
+
+func TestPrefixForCellWide(t *testing.T) {
+	// 3 dimensions of 20 bits leave 4 spare bits; a level 4 cell holds 16 bits, 2 whole bytes
+	m := New(3, 1<<20)
+	code, _ := m.Encode([]uint32{1<<20 - 1, 0, 1 << 19})
+	prefix, exact := m.PrefixForCell(code, 4)
+	if !exact || !bytes.Equal(prefix, codeKey(nil, code)[:2]) {
+		t.Errorf("PrefixForCell at level 4 = % x, %v", prefix, exact)
+	}
+	if prefix, exact := m.PrefixForCell(code, 0); exact || len(prefix) != 0 {
+		t.Errorf("PrefixForCell at level 0, of 4 spare bits, = % x, %v; want an empty inexact prefix", prefix, exact)
+	}
+
+	last := uint64(1<<60 - 1)
+	start, end, err := m.CellKeyRange(last, 20)
+	if err != nil || !bytes.Equal(start, codeKey(nil, last)) || !bytes.Equal(end, codeKey(nil, last+1)) {
+		t.Errorf("CellKeyRange of the last code = % x, % x, %v", start, end, err)
+	}
+
+	if prefix, exact := m.PrefixForCell(code, 21); prefix != nil || exact {
+		t.Errorf("PrefixForCell at level 21 = % x, %v", prefix, exact)
+	}
+	if _, _, err := m.CellKeyRange(code, 21); err == nil {
+		t.Error("CellKeyRange at level 21 succeeded")
+	}
+	if prefix, _ := New(3, 16, WithShardScatter()).PrefixForCell(0, 1); prefix != nil {
+		t.Errorf("PrefixForCell of a scattered Morton = % x", prefix)
+	}
+}
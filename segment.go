@@ -0,0 +1,90 @@
+package morton
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// Codes of the cells at level that the segment from a to b passes through, in order from a, by an Amanatides-Woo grid walk.  Coordinates are taken at voxel centers, and cells are half-open, so a point on a boundary belongs to the cell above it: a segment passing exactly through a corner steps diagonally, through only those cells containing the corner point.
+func (m *Morton) SegmentCells(a, b []uint32, level uint8) ([]uint64, error) {
+	d := int(m.Dimensions)
+	if len(a) != d || len(b) != d {
+		return nil, ErrDimensionMismatch
+	}
+	if _, err := m.Encode(a); err != nil {
+		return nil, err
+	}
+	if _, err := m.Encode(b); err != nil {
+		return nil, err
+	}
+	if _, err := m.cellShift(level); err != nil {
+		return nil, err
+	}
+	if d == 0 {
+		return nil, errors.New("Segment requires at least one dimension")
+	}
+
+	// Doubled coordinates keep voxel centers integral; crossing i is at t = num[i] / den[i]
+	h := m.Bits() - level
+	size := uint64(2) << h
+	cell := make([]uint32, d)
+	num, den := make([]uint64, d), make([]uint64, d)
+	steps, dir := make([]uint32, d), make([]int8, d)
+	for i := range cell {
+		cell[i] = a[i] >> h
+		p := 2*uint64(a[i]) + 1
+		switch end := b[i] >> h; {
+		case b[i] > a[i]:
+			dir[i], steps[i] = 1, end-cell[i]
+			num[i], den[i] = (uint64(cell[i])+1)*size-p, 2*uint64(b[i]-a[i])
+		case b[i] < a[i]:
+			dir[i], steps[i] = -1, cell[i]-end
+			num[i], den[i] = p-uint64(cell[i])*size, 2*uint64(a[i]-b[i])
+		}
+	}
+
+	corner := make([]uint32, d)
+	emit := func(codes []uint64) []uint64 {
+		for i, c := range cell {
+			corner[i] = c << h
+		}
+		code, _ := m.Encode(corner)
+		return append(codes, code)
+	}
+
+	codes := emit(nil)
+	for {
+		// Earliest crossing among the axes with steps left.  Tied axes step together, ascending ones first, as a boundary point belongs to the cell above it
+		next := -1
+		for i := range steps {
+			if steps[i] > 0 && (next < 0 || fracLess(num[i], den[i], num[next], den[next])) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return codes, nil
+		}
+		n, dn := num[next], den[next]
+		for _, sign := range [2]int8{1, -1} {
+			stepped := false
+			for i := range steps {
+				if dir[i] == sign && steps[i] > 0 && !fracLess(n, dn, num[i], den[i]) {
+					cell[i] = uint32(int64(cell[i]) + int64(sign))
+					num[i] += size
+					steps[i]--
+					stepped = true
+				}
+			}
+			if stepped {
+				codes = emit(codes)
+			}
+		}
+	}
+}
+
+// Reports whether a/b < c/d, exactly.
+func fracLess(a, b, c, d uint64) bool {
+	hi1, lo1 := bits.Mul64(a, d)
+	hi2, lo2 := bits.Mul64(c, b)
+	return hi1 < hi2 || hi1 == hi2 && lo1 < lo2
+}
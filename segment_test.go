@@ -0,0 +1,124 @@
+package morton
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Cells at level of the points of the segment between the voxel centers a and b, in order, by exact rational sampling at every boundary crossing and midway between them.
+func supercover(m *Morton, a, b []uint32, level uint8) []uint64 {
+	size := int64(2) << (m.Bits() - level)
+	// Doubled coordinates: p(t) = 2a+1 + 2t(b-a)
+	at := func(t *big.Rat) []uint32 {
+		cell := make([]uint32, len(a))
+		for i := range a {
+			p := new(big.Rat).Mul(t, big.NewRat(2*(int64(b[i])-int64(a[i])), 1))
+			p.Add(p, big.NewRat(2*int64(a[i])+1, 1))
+			q := new(big.Int).Quo(p.Num(), new(big.Int).Mul(p.Denom(), big.NewInt(size)))
+			cell[i] = uint32(q.Int64()) << (m.Bits() - level)
+		}
+		return cell
+	}
+
+	ts := []*big.Rat{new(big.Rat), big.NewRat(1, 1)}
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+		for j := int64(0); j*size <= 2*int64(m.Tables[i].Length); j++ {
+			t := big.NewRat(j*size-2*int64(a[i])-1, 2*(int64(b[i])-int64(a[i])))
+			if t.Sign() > 0 && t.Cmp(big.NewRat(1, 1)) < 0 {
+				ts = append(ts, t)
+			}
+		}
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Cmp(ts[j]) < 0 })
+	samples := []*big.Rat{ts[0]}
+	for i := 1; i < len(ts); i++ {
+		mid := new(big.Rat).Add(ts[i-1], ts[i])
+		samples = append(samples, mid.Quo(mid, big.NewRat(2, 1)), ts[i])
+	}
+
+	var codes []uint64
+	for _, t := range samples {
+		code := m.MustEncode(at(t))
+		if len(codes) == 0 || codes[len(codes)-1] != code {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+func TestSegmentCells(t *testing.T) {
+	r := rand.New(rand.NewSource(1282))
+	for _, m := range []*Morton{New(2, 16), New(3, 8)} {
+		d := int(m.Dimensions)
+		for n := 0; n < 300; n++ {
+			a, b := make([]uint32, d), make([]uint32, d)
+			for i := range a {
+				a[i] = uint32(r.Intn(int(m.Tables[i].Length)))
+				b[i] = uint32(r.Intn(int(m.Tables[i].Length)))
+			}
+			// Axis-aligned, diagonal and degenerate segments
+			switch n % 4 {
+			case 1:
+				copy(b[1:], a[1:])
+			case 2:
+				k := uint32(r.Intn(int(m.Tables[0].Length)))
+				for i := range a {
+					a[i], b[i] = 0, k
+				}
+			case 3:
+				copy(b, a)
+			}
+			for level := uint8(0); level <= m.Bits(); level++ {
+				got, err := m.SegmentCells(a, b, level)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := supercover(m, a, b, level)
+				if !equalUint64s(got, want) {
+					t.Fatalf("%vD: SegmentCells(%v, %v, %v) = %v, want %v", d, a, b, level, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSegmentCellsCorner(t *testing.T) {
+	// From voxel 1 to voxel 6 along the diagonal, the centers cross level 1 cell corners exactly, stepping diagonally
+	m := New(2, 8)
+	got, _ := m.SegmentCells([]uint32{1, 1}, []uint32{6, 6}, 1)
+	want := []uint64{m.MustEncode([]uint32{0, 0}), m.MustEncode([]uint32{4, 4})}
+	if !equalUint64s(got, want) {
+		t.Errorf("Diagonal SegmentCells = %v, want %v", got, want)
+	}
+}
+
+func TestSegmentCellsRejects(t *testing.T) {
+	m := New(2, 16)
+	if _, err := m.SegmentCells([]uint32{0}, []uint32{1, 1}, 4); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("SegmentCells of 1 component returned %v", err)
+	}
+	if _, err := m.SegmentCells([]uint32{0, 0}, []uint32{16, 1}, 4); err == nil {
+		t.Error("SegmentCells beyond the tables succeeded")
+	}
+	if _, err := m.SegmentCells([]uint32{0, 0}, []uint32{1, 1}, 5); !errors.Is(err, ErrInvalidLevel) {
+		t.Errorf("SegmentCells at level 5 returned %v", err)
+	}
+}
+
+func equalUint64s(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package morton
+
+import "sort"
+
+// Set of codes, e.g. of a range query's results, for repeated membership tests: building it costs O(n), and each test O(1).
+func (m *Morton) HashSet(codes []uint64) map[uint64]struct{} {
+	set := make(map[uint64]struct{}, len(codes))
+	for _, c := range codes {
+		set[c] = struct{}{}
+	}
+	return set
+}
+
+// Reports, for each candidate, whether it is in set, in O(1) per candidate.
+func (m *Morton) ContainsAll(set map[uint64]struct{}, candidates []uint64) []bool {
+	in := make([]bool, len(candidates))
+	for i, c := range candidates {
+		_, in[i] = set[c]
+	}
+	return in
+}
+
+// Sorts codes in place and removes duplicates in one linear pass, returning the leading sub-slice of codes that holds the result.
+func (m *Morton) SortAndDeduplicate(codes []uint64) []uint64 {
+	if !sort.SliceIsSorted(codes, func(i, j int) bool { return codes[i] < codes[j] }) {
+		sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	}
+	if len(codes) == 0 {
+		return codes
+	}
+
+	n := 1
+	for _, c := range codes[1:] {
+		if c != codes[n-1] {
+			codes[n] = c
+			n++
+		}
+	}
+	return codes[:n]
+}
+
+// Codes in a or b.  Both must be sorted ascending without duplicates, as from SortAndDeduplicate; the result is too.
+func (m *Morton) Union(a, b []uint64) []uint64 {
+	out := make([]uint64, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case b[j] < a[i]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return append(append(out, a[i:]...), b[j:]...)
+}
+
+// Codes in both a and b, which must be sorted as for Union.
+func (m *Morton) Intersect(a, b []uint64) []uint64 {
+	var out []uint64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case b[j] < a[i]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// Codes in a but not in b, which must be sorted as for Union.
+func (m *Morton) Subtract(a, b []uint64) []uint64 {
+	var out []uint64
+	j := 0
+	for _, c := range a {
+		for j < len(b) && b[j] < c {
+			j++
+		}
+		if j == len(b) || b[j] != c {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Half-open interval [lo, hi) of the indices of sortedCodes equal to target, found by two binary searches, like C++'s equal_range.  Duplicates arise among cell codes, e.g., after AtLevel.  If target is absent, lo == hi is where it would be inserted.
+func (m *Morton) SearchCodes(sortedCodes []uint64, target uint64) (lo, hi int) {
+	lo = sort.Search(len(sortedCodes), func(i int) bool { return sortedCodes[i] >= target })
+	hi = lo + sort.Search(len(sortedCodes)-lo, func(i int) bool { return sortedCodes[lo+i] > target })
+	return
+}
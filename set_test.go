@@ -0,0 +1,222 @@
+package morton
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestHashSet(t *testing.T) {
+	// Tables of 5 leave codes beyond the grid within the bit budget
+	m := New(2, 5)
+	var grid []uint64
+	m.ForEach(func(_ []uint32, code uint64) bool {
+		grid = append(grid, code)
+		return true
+	})
+	set := m.HashSet(grid)
+	if len(set) != 25 {
+		t.Fatalf("HashSet of the grid has %v codes, want 25", len(set))
+	}
+	for i, in := range m.ContainsAll(set, grid) {
+		if !in {
+			t.Errorf("grid code %v is absent from its set", grid[i])
+		}
+	}
+
+	outside := uint64(0x11) // x = 5
+	if v := m.Decode(outside); v[0] != 5 || v[1] != 0 {
+		t.Fatalf("Decode(0x11) = %v, want [5 0]", v)
+	}
+	in := m.ContainsAll(set, []uint64{grid[3], outside, 1 << 40, grid[0]})
+	if len(in) != 4 || !in[0] || in[1] || in[2] || !in[3] {
+		t.Errorf("ContainsAll of two grid codes and two others = %v", in)
+	}
+}
+
+func TestHashSetDuplicates(t *testing.T) {
+	m := New(2, 16)
+	set := m.HashSet([]uint64{7, 7, 3, 7})
+	if len(set) != 2 {
+		t.Errorf("HashSet of 7, 7, 3, 7 has %v codes, want 2", len(set))
+	}
+	if in := m.ContainsAll(m.HashSet(nil), []uint64{0}); len(in) != 1 || in[0] {
+		t.Errorf("ContainsAll of the empty set = %v", in)
+	}
+	if in := m.ContainsAll(set, nil); len(in) != 0 {
+		t.Errorf("ContainsAll without candidates = %v", in)
+	}
+}
+
+func TestSortAndDeduplicate(t *testing.T) {
+	m := New(2, 16)
+	r := rand.New(rand.NewSource(138))
+	for n := 0; n < 200; n++ {
+		codes := make([]uint64, r.Intn(50))
+		for i := range codes {
+			codes[i] = uint64(r.Intn(30))
+		}
+		counts := make(map[uint64]int)
+		for _, c := range codes {
+			counts[c]++
+		}
+		got := m.SortAndDeduplicate(append([]uint64(nil), codes...))
+		if len(got) != len(counts) {
+			t.Fatalf("SortAndDeduplicate(%v) = %v, want %v distinct codes", codes, got, len(counts))
+		}
+		for i, c := range got {
+			if i > 0 && got[i-1] >= c {
+				t.Fatalf("SortAndDeduplicate(%v) = %v, out of order or repeated at %v", codes, got, i)
+			}
+			if counts[c] == 0 {
+				t.Fatalf("SortAndDeduplicate(%v) = %v, with %v not in the input", codes, got, c)
+			}
+		}
+	}
+}
+
+func TestSortAndDeduplicateInPlace(t *testing.T) {
+	m := New(2, 16)
+	clean := []uint64{1, 4, 9, 16}
+	if got := m.SortAndDeduplicate(clean); len(got) != 4 || &got[0] != &clean[0] || !equalUint64s(got, []uint64{1, 4, 9, 16}) {
+		t.Errorf("SortAndDeduplicate of clean input = %v, not the input itself", got)
+	}
+	codes := []uint64{5, 2, 5, 2, 8}
+	if got := m.SortAndDeduplicate(codes); &got[0] != &codes[0] || !equalUint64s(got, []uint64{2, 5, 8}) {
+		t.Errorf("SortAndDeduplicate(5, 2, 5, 2, 8) = %v, want a sub-slice holding [2 5 8]", got)
+	}
+	if got := m.SortAndDeduplicate(nil); len(got) != 0 {
+		t.Errorf("SortAndDeduplicate(nil) = %v", got)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { m.SortAndDeduplicate(clean) }); allocs != 0 {
+		t.Errorf("SortAndDeduplicate of clean input allocates %v times", allocs)
+	}
+}
+
+// Sorted codes of a set, for comparison with the sorted set operations.
+func setCodes(set map[uint64]bool) []uint64 {
+	codes := make([]uint64, 0, len(set))
+	for c, in := range set {
+		if in {
+			codes = append(codes, c)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+func TestSetOperations(t *testing.T) {
+	m := New(2, 8)
+	r := rand.New(rand.NewSource(139))
+	for n := 0; n < 300; n++ {
+		// Small grids, so that the sets overlap
+		a := m.SortAndDeduplicate(sortedCodes(r, m, r.Intn(40)))
+		b := m.SortAndDeduplicate(sortedCodes(r, m, r.Intn(40)))
+		inA, inB := make(map[uint64]bool), make(map[uint64]bool)
+		for _, c := range a {
+			inA[c] = true
+		}
+		for _, c := range b {
+			inB[c] = true
+		}
+		union, both, only := make(map[uint64]bool), make(map[uint64]bool), make(map[uint64]bool)
+		for c := range inA {
+			union[c], both[c], only[c] = true, inB[c], !inB[c]
+		}
+		for c := range inB {
+			union[c] = true
+		}
+
+		if got, want := m.Union(a, b), setCodes(union); !equalUint64s(got, want) {
+			t.Fatalf("Union(%v, %v) = %v, want %v", a, b, got, want)
+		}
+		if got, want := m.Intersect(a, b), setCodes(both); !equalUint64s(got, want) {
+			t.Fatalf("Intersect(%v, %v) = %v, want %v", a, b, got, want)
+		}
+		if got, want := m.Subtract(a, b), setCodes(only); !equalUint64s(got, want) {
+			t.Fatalf("Subtract(%v, %v) = %v, want %v", a, b, got, want)
+		}
+	}
+}
+
+func TestSetOperationsEdges(t *testing.T) {
+	m := New(2, 16)
+	a := []uint64{1, 5, 9}
+	if got := m.Union(a, nil); !equalUint64s(got, a) {
+		t.Errorf("Union with the empty set = %v", got)
+	}
+	if got := m.Union(nil, a); !equalUint64s(got, a) {
+		t.Errorf("Union of the empty set = %v", got)
+	}
+	if got := m.Intersect(a, nil); len(got) != 0 {
+		t.Errorf("Intersect with the empty set = %v", got)
+	}
+	if got := m.Intersect(a, a); !equalUint64s(got, a) {
+		t.Errorf("Intersect with itself = %v", got)
+	}
+	if got := m.Subtract(a, a); len(got) != 0 {
+		t.Errorf("Subtract from itself = %v", got)
+	}
+	if got := m.Subtract(a, []uint64{0, 9, 10}); !equalUint64s(got, []uint64{1, 5}) {
+		t.Errorf("Subtract(%v, [0 9 10]) = %v, want [1 5]", a, got)
+	}
+}
+
+func TestSearchCodes(t *testing.T) {
+	m := New(2, 16)
+	codes := []uint64{3, 3, 3, 5, 8, 8, 13, 21, 21}
+	for _, tc := range []struct {
+		target uint64
+		lo, hi int
+	}{
+		{3, 0, 3},  // several, at the beginning
+		{5, 3, 4},  // once
+		{8, 4, 6},  // several, in the middle
+		{21, 7, 9}, // several, at the end
+		{0, 0, 0},  // absent, before every code
+		{4, 3, 3},  // absent, between codes
+		{22, 9, 9}, // absent, after every code
+	} {
+		if lo, hi := m.SearchCodes(codes, tc.target); lo != tc.lo || hi != tc.hi {
+			t.Errorf("SearchCodes(%v) = %v, %v; want %v, %v", tc.target, lo, hi, tc.lo, tc.hi)
+		}
+	}
+	if lo, hi := m.SearchCodes(nil, 5); lo != 0 || hi != 0 {
+		t.Errorf("SearchCodes in no codes = %v, %v", lo, hi)
+	}
+	if lo, hi := m.SearchCodes([]uint64{7}, 7); lo != 0 || hi != 1 {
+		t.Errorf("SearchCodes of the only code = %v, %v", lo, hi)
+	}
+}
+
+// Cell codes after AtLevel repeat; SearchCodes finds each cell's run.
+func TestSearchCodesCells(t *testing.T) {
+	m := New(2, 1<<8)
+	r := rand.New(rand.NewSource(147))
+	codes := sortedCodes(r, m, 2000)
+	for i := range codes {
+		codes[i], _ = m.AtLevel(codes[i], 3)
+	}
+	for n := 0; n < 300; n++ {
+		target := codes[r.Intn(len(codes))]
+		if n%3 == 0 {
+			target = uint64(r.Intn(1 << 16))
+		}
+		lo, hi := m.SearchCodes(codes, target)
+		want := 0
+		for i, c := range codes {
+			if c == target {
+				want++
+				if i < lo || i >= hi {
+					t.Fatalf("SearchCodes(%v) = %v, %v, excluding index %v", target, lo, hi, i)
+				}
+			}
+		}
+		if hi-lo != want {
+			t.Fatalf("SearchCodes(%v) = %v, %v; want %v matches", target, lo, hi, want)
+		}
+		if lo > 0 && codes[lo-1] >= target || lo < len(codes) && want == 0 && codes[lo] < target {
+			t.Fatalf("SearchCodes(%v) = %v, %v, not the insertion point", target, lo, hi)
+		}
+	}
+}
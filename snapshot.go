@@ -0,0 +1,46 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Deep copy of a Morton's state, for undoing experimental changes such as regenerating or permuting its tables.  See Morton.Snapshot.
+type Snapshot struct {
+	Dimensions uint8
+
+	state Morton
+}
+
+// Copy of m with its own tables, magic bits and transform.  Custom Interleavers are shared, except for the masks set by WithBitsPerDimension.
+func (m *Morton) clone() Morton {
+	c := *m
+	c.Tables = make([]Table, len(m.Tables))
+	for i, t := range m.Tables {
+		t.Encode = append([]Bit(nil), t.Encode...)
+		c.Tables[i] = t
+	}
+	c.Magic = append([]uint64(nil), m.Magic...)
+	c.transform = m.Transform()
+	if mi, ok := m.interleaver.(maskInterleaver); ok {
+		c.interleaver = append(maskInterleaver(nil), mi...)
+	}
+	return c
+}
+
+// Captures the current state; later changes to m do not affect the snapshot, nor changes to the snapshot m.
+func (m *Morton) Snapshot() Snapshot {
+	return Snapshot{m.Dimensions, m.clone()}
+}
+
+// Rolls m back to the state captured in s, which must have the same number of dimensions.  The snapshot can be restored again.
+func (m *Morton) Restore(s Snapshot) error {
+	if s.state.Dimensions != s.Dimensions || s.state.Tables == nil && s.Dimensions != 0 {
+		return errors.New("Snapshot is empty or altered.  Please capture one via Snapshot().")
+	}
+	if s.Dimensions != m.Dimensions {
+		return fmt.Errorf("%w.  Snapshot has %v dimensions, not %v", ErrDimensionMismatch, s.Dimensions, m.Dimensions)
+	}
+	*m = s.state.clone()
+	return nil
+}
@@ -0,0 +1,111 @@
+package morton
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	m := New(2, 16, WithGrayCode())
+	vector := []uint32{5, 11}
+	want, err := m.Encode(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Snapshot()
+	if err := m.Create(2, 4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Encode(vector); err == nil {
+		t.Fatal("Encode succeeded beyond the resized tables")
+	}
+
+	if err := m.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Encode(vector)
+	if err != nil || got != want {
+		t.Fatalf("Encode after Restore = %v, %v; want %v", got, err, want)
+	}
+	if d := m.Decode(got); !equalUint32s(d, vector) {
+		t.Errorf("Decode after Restore = %v, want %v", d, vector)
+	}
+
+	// Restoring twice works, and the restored tables are not the snapshot's
+	m.Tables[0].Encode[1] = m.Tables[0].Encode[0]
+	if err := m.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.Encode(vector); got != want {
+		t.Errorf("Encode after the second Restore = %v, want %v", got, want)
+	}
+}
+
+func TestSnapshotIndependent(t *testing.T) {
+	m := New(2, 16)
+	s := m.Snapshot()
+	s.state.Tables[0].Encode[1] = s.state.Tables[0].Encode[0]
+	s.state.Magic[0] = 0
+	if got, _ := m.Encode([]uint32{1, 0}); got != 1 {
+		t.Errorf("Encode after altering the snapshot = %v, want 1", got)
+	}
+	if m.Magic[0] == 0 {
+		t.Error("Altering the snapshot's magic bits altered m's")
+	}
+}
+
+func TestSnapshotTransform(t *testing.T) {
+	m := new(Morton)
+	if err := m.Create(2, 256, WithTransform([]float64{1, 2}, []float64{4, 8})); err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewQuantizer(Axis{Min: 0, Max: 64, Bits: 8}, Axis{Min: 0, Max: 64, Bits: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := m.EncodeFloat([]float64{3, 5}, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Snapshot()
+	WithTransform([]float64{0, 0}, []float64{1, 1})(m)
+	WithRotation(1)(m)
+	m.transform.Offset[0] = 100
+	if err := m.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.EncodeFloat([]float64{3, 5}, q); err != nil || got != want {
+		t.Errorf("EncodeFloat after Restore = %v, %v; want %v", got, err, want)
+	}
+	if tr := m.Transform(); tr.Offset[0] != 1 || tr.Scale[1] != 8 || tr.Rotation != 0 {
+		t.Errorf("Transform after Restore = %+v", tr)
+	}
+}
+
+func TestSnapshotBitsPerDimension(t *testing.T) {
+	m, err := New(2, 4).WithBitsPerDimension([]uint8{3, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := m.Encode([]uint32{6, 30})
+	s := m.Snapshot()
+	m.interleaver.(maskInterleaver)[0] = 0
+	if err := m.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Encode([]uint32{6, 30}); err != nil || got != want {
+		t.Errorf("Encode after Restore = %v, %v; want %v", got, err, want)
+	}
+}
+
+func TestRestoreRejects(t *testing.T) {
+	m := New(2, 16)
+	if err := m.Restore(New(3, 4).Snapshot()); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Restore of a 3D snapshot returned %v, want ErrDimensionMismatch", err)
+	}
+	if err := m.Restore(Snapshot{Dimensions: 2}); err == nil {
+		t.Error("Restore of an empty snapshot succeeded")
+	}
+}
@@ -0,0 +1,127 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"math/rand"
+	"sort"
+)
+
+// Start of the i-th of n equal intervals over the code space [0, max], for i <= n, computed without overflow.  The end of the last interval, max + 1, wraps to 0 when the space spans all 64 bits, so that end - start is still each interval's width modulo 2^64.
+func intervalStart(i, n, max uint64) uint64 {
+	if i >= n {
+		return max + 1
+	}
+	// i * (max + 1) / n, with a 128 bit product
+	hi, lo := bits.Mul64(i, max)
+	lo, carry := bits.Add64(lo, i, 0)
+	q, _ := bits.Div64(hi+carry, lo, n)
+	return q
+}
+
+// Stratified random sample of the code space [0, MaxCode()]: the space is divided into n equal intervals and one code is drawn from each, yielding n distinct, ascending codes.  The same seed always produces the same sample.
+func (m *Morton) UniformSample(n int, seed int64) ([]uint64, error) {
+	if n < 0 {
+		return nil, errors.New("Sample size must not be negative")
+	}
+
+	max := m.MaxCode()
+	if len(m.Tables) == 0 || n > 0 && uint64(n)-1 > max {
+		return nil, errors.New("Sample size exceeds the number of codes")
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	codes := make([]uint64, n)
+	for i := range codes {
+		start := intervalStart(uint64(i), uint64(n), max)
+		width := intervalStart(uint64(i+1), uint64(n), max) - start
+		offset := r.Uint64()
+		// A width of 0 is the whole 64 bit space
+		if width != 0 {
+			offset, _ = bits.Mul64(offset, width)
+		}
+		codes[i] = start + offset
+	}
+
+	return codes, nil
+}
+
+// The code at fraction q of the code space [0, MaxCode()], with q clamped to [0, 1].
+func (m *Morton) QuantileCode(q float64) (uint64, error) {
+	if len(m.Tables) == 0 {
+		return 0, errors.New("No lookup tables.  Please generate them via CreateTables().")
+	}
+	if math.IsNaN(q) {
+		return 0, errors.New("Quantile must be a number")
+	}
+
+	max := m.MaxCode()
+	switch {
+	case q <= 0:
+		return 0, nil
+	case q >= 1:
+		return max, nil
+	}
+
+	// Float rounding may land just past max for q close to 1.
+	f := float64(max) * q
+	if f >= float64(max) {
+		return max, nil
+	}
+	return uint64(f), nil
+}
+
+// Decoded coordinates of QuantileCode(q), e.g. for median splits of the space.
+func (m *Morton) QuantileCoords(q float64) ([]uint32, error) {
+	code, err := m.QuantileCode(q)
+	if err != nil {
+		return nil, err
+	}
+	return m.Decode(code), nil
+}
+
+// Counts codes into bins equal-width intervals over the code space [0, MaxCode()].  Codes beyond MaxCode() are counted in the last bin.  The input is copied and sorted once, and bins are counted by binary search.
+func (m *Morton) Histogram(codes []uint64, bins int) ([]int, error) {
+	if bins <= 0 {
+		return nil, errors.New("Histogram requires at least one bin")
+	}
+
+	sorted := make([]uint64, len(codes))
+	copy(sorted, codes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	max := m.MaxCode()
+	counts := make([]int, bins)
+	lo := 0
+	for i := range counts {
+		if i == bins-1 {
+			counts[i] = len(sorted) - lo
+			break
+		}
+		end := intervalStart(uint64(i+1), uint64(bins), max)
+		hi := lo + sort.Search(len(sorted)-lo, func(j int) bool { return sorted[lo+j] >= end })
+		counts[i] = hi - lo
+		lo = hi
+	}
+
+	return counts, nil
+}
+
+// Fraction of consecutive pairs in the sorted codes whose decoded coordinates differ by at most 1 in every dimension: 1.0 is perfectly local, 0.0 has no spatial locality.
+func (m *Morton) LocalityScore(codes []uint64) (float64, error) {
+	if len(codes) < 2 {
+		return 0, errors.New("LocalityScore requires at least two codes")
+	}
+	if !sort.SliceIsSorted(codes, func(i, j int) bool { return codes[i] < codes[j] }) {
+		return 0, errors.New("LocalityScore requires codes sorted in ascending order")
+	}
+
+	local := 0
+	for i := 1; i < len(codes); i++ {
+		if m.DistanceChebyshev(codes[i-1], codes[i]) <= 1 {
+			local++
+		}
+	}
+	return float64(local) / float64(len(codes)-1), nil
+}
@@ -0,0 +1,254 @@
+package morton
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	m := New(2, 256)
+	r := rand.New(rand.NewSource(1))
+	codes := make([]uint64, 40000)
+	for i := range codes {
+		codes[i] = uint64(r.Int63n(int64(m.MaxCode()) + 1))
+	}
+
+	counts, err := m.Histogram(codes, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := 0
+	for i, c := range counts {
+		sum += c
+		if math.Abs(float64(c)-5000) > 500 {
+			t.Errorf("bin %v holds %v codes, want about 5000", i, c)
+		}
+	}
+	if sum != len(codes) {
+		t.Errorf("counts sum to %v, want %v", sum, len(codes))
+	}
+
+	for _, bins := range []int{0, -1} {
+		if _, err := m.Histogram(codes, bins); err == nil {
+			t.Errorf("Histogram with %v bins succeeded, want an error", bins)
+		}
+	}
+}
+
+func TestHistogramEdges(t *testing.T) {
+	m := New(2, 4)
+	// 16 codes, 4 bins of 4
+	counts, err := m.Histogram([]uint64{0, 3, 4, 7, 8, 15, 15, 100}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 2, 1, 3}; !equalInts(counts, want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+}
+
+func TestHistogramFullWidth(t *testing.T) {
+	for _, m := range []*Morton{New(8, 256), New(4, 65536)} {
+		if m.MaxCode() != math.MaxUint64 {
+			t.Fatalf("MaxCode() = %#x, want all 64 bits", m.MaxCode())
+		}
+		codes := []uint64{0, 1 << 62, 1<<63 - 1, 1 << 63, 3 << 62, math.MaxUint64}
+		counts, err := m.Histogram(codes, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int{1, 2, 1, 2}; !equalInts(counts, want) {
+			t.Errorf("%v dimensions: got %v, want %v", m.Dimensions, counts, want)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestUniformSample(t *testing.T) {
+	m := New(2, 16)
+	total := m.MaxCode() + 1
+	const n = 37
+	codes, err := m.UniformSample(n, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != n {
+		t.Fatalf("got %v codes, want %v", len(codes), n)
+	}
+	for i, c := range codes {
+		lo, hi := uint64(i)*total/n, uint64(i+1)*total/n
+		if c < lo || c >= hi {
+			t.Errorf("code %v, %v, is outside its interval [%v, %v)", i, c, lo, hi)
+		}
+		if i > 0 && c <= codes[i-1] {
+			t.Errorf("code %v, %v, does not exceed its predecessor %v", i, c, codes[i-1])
+		}
+	}
+
+	again, _ := m.UniformSample(n, 7)
+	other, _ := m.UniformSample(n, 8)
+	same, differ := true, false
+	for i := range codes {
+		same = same && again[i] == codes[i]
+		differ = differ || other[i] != codes[i]
+	}
+	if !same {
+		t.Error("the same seed produced different samples")
+	}
+	if !differ {
+		t.Error("different seeds produced the same sample")
+	}
+
+	all, err := m.UniformSample(int(total), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range all {
+		if c != uint64(i) {
+			t.Fatalf("sampling every code: code %v is %v", i, c)
+		}
+	}
+	if _, err := m.UniformSample(int(total)+1, 1); err == nil {
+		t.Error("sampling more codes than exist succeeded, want an error")
+	}
+	if _, err := m.UniformSample(-1, 1); err == nil {
+		t.Error("negative sample size succeeded, want an error")
+	}
+}
+
+func TestUniformSampleFullWidth(t *testing.T) {
+	m := New(4, 65536)
+	for _, n := range []int{1, 4, 1000} {
+		codes, err := m.UniformSample(n, 3)
+		if err != nil {
+			t.Fatalf("n = %v: %v", n, err)
+		}
+		for i, c := range codes {
+			// Interval i is [i * 2^64 / n, (i + 1) * 2^64 / n)
+			if lo := intervalStart(uint64(i), uint64(n), math.MaxUint64); c < lo {
+				t.Errorf("n = %v: code %v, %#x, precedes its interval at %#x", n, i, c, lo)
+			}
+			if i+1 < n && c >= intervalStart(uint64(i+1), uint64(n), math.MaxUint64) {
+				t.Errorf("n = %v: code %v, %#x, is beyond its interval", n, i, c)
+			}
+			if i > 0 && c <= codes[i-1] {
+				t.Errorf("n = %v: codes are not ascending at %v", n, i)
+			}
+		}
+	}
+	if lo := intervalStart(1, 4, math.MaxUint64); lo != 1<<62 {
+		t.Errorf("second quarter starts at %#x, want %#x", lo, uint64(1<<62))
+	}
+}
+
+func TestQuantileCode(t *testing.T) {
+	for _, m := range []*Morton{New(2, 16), New(3, 10), New(4, 65536)} {
+		max := m.MaxCode()
+		for _, tc := range []struct {
+			q    float64
+			want uint64
+		}{{0, 0}, {-1, 0}, {1, max}, {2, max}, {math.Inf(1), max}, {math.Inf(-1), 0}} {
+			if got, err := m.QuantileCode(tc.q); err != nil || got != tc.want {
+				t.Errorf("QuantileCode(%v) of max %v = %v, %v; want %v", tc.q, max, got, err, tc.want)
+			}
+		}
+		half, _ := m.QuantileCode(0.5)
+		if half+1 < max/2 || half > max/2+1 {
+			t.Errorf("QuantileCode(0.5) of max %v = %v, want about %v", max, half, max/2)
+		}
+		prev := uint64(0)
+		for q := 0.0; q <= 1; q += 1.0 / 64 {
+			c, _ := m.QuantileCode(q)
+			if c < prev || c > max {
+				t.Errorf("QuantileCode(%v) = %v, after %v, with max %v", q, c, prev, max)
+			}
+			prev = c
+		}
+	}
+
+	m := New(2, 16)
+	if _, err := m.QuantileCode(math.NaN()); err == nil {
+		t.Error("QuantileCode(NaN) succeeded")
+	}
+	if _, err := new(Morton).QuantileCode(0.5); err == nil {
+		t.Error("QuantileCode without tables succeeded")
+	}
+}
+
+func TestQuantileCoords(t *testing.T) {
+	m := New(2, 16)
+	v, err := m.QuantileCoords(1)
+	if err != nil || !equalUint32s(v, []uint32{15, 15}) {
+		t.Errorf("QuantileCoords(1) = %v, %v; want [15 15]", v, err)
+	}
+	code, _ := m.QuantileCode(0.3)
+	if v, _ := m.QuantileCoords(0.3); !equalUint32s(v, m.Decode(code)) {
+		t.Errorf("QuantileCoords(0.3) = %v, but QuantileCode decodes to %v", v, m.Decode(code))
+	}
+}
+
+func TestLocalityScore(t *testing.T) {
+	m := New(2, 64)
+	grid, _ := allCodes(t, m)
+	local, err := m.LocalityScore(grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Brute force over the pairs
+	near := 0
+	for i := 1; i < len(grid); i++ {
+		a, b := m.Decode(grid[i-1]), m.Decode(grid[i])
+		if absDiff(a[0], b[0]) <= 1 && absDiff(a[1], b[1]) <= 1 {
+			near++
+		}
+	}
+	if want := float64(near) / float64(len(grid)-1); local != want {
+		t.Errorf("LocalityScore of the grid = %v, want %v", local, want)
+	}
+	// Z-order jumps between quadrants, an eighth of its steps on a whole grid
+	if local < 0.85 {
+		t.Errorf("LocalityScore of the whole grid = %v, want near 1", local)
+	}
+
+	// The same number of codes, scattered at random, has little locality
+	r := rand.New(rand.NewSource(125))
+	wide := New(2, 1<<16)
+	scattered := make([]uint64, len(grid))
+	for i := range scattered {
+		scattered[i] = uint64(r.Int63n(1 << 32))
+	}
+	sort.Slice(scattered, func(i, j int) bool { return scattered[i] < scattered[j] })
+	score, err := wide.LocalityScore(scattered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if score > local/4 {
+		t.Errorf("LocalityScore of scattered codes = %v, want much less than the grid's %v", score, local)
+	}
+
+	if s, _ := m.LocalityScore([]uint64{5, 5}); s != 1 {
+		t.Errorf("LocalityScore of a repeated code = %v, want 1", s)
+	}
+}
+
+func TestLocalityScoreRejects(t *testing.T) {
+	m := New(2, 64)
+	for _, codes := range [][]uint64{nil, {3}, {4, 3, 5}} {
+		if _, err := m.LocalityScore(codes); err == nil {
+			t.Errorf("LocalityScore(%v) succeeded", codes)
+		}
+	}
+}
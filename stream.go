@@ -0,0 +1,158 @@
+package morton
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+Code streams are fixed-width records of 8 bytes, each a big-endian code, so that byte order matches code order.  A stream may begin with
+an 8 byte header, "MRTN", a version byte, the number of dimensions and two reserved zero bytes, which keeps records aligned.
+*/
+
+var ErrTruncatedRecord = errors.New("Code stream ends with a truncated record")
+
+const (
+	codeRecordSize    = 8
+	codeStreamVersion = 1
+	// Records buffered per write
+	codeChunk = 512
+)
+
+var codeStreamMagic = [4]byte{'M', 'R', 'T', 'N'}
+
+// Writes the optional stream header, for sanity checking dimensions on read.
+func WriteCodesHeader(w io.Writer, dimensions uint8) error {
+	h := [codeRecordSize]byte{codeStreamMagic[0], codeStreamMagic[1], codeStreamMagic[2], codeStreamMagic[3], codeStreamVersion, dimensions}
+	_, err := w.Write(h[:])
+	return err
+}
+
+// Writes codes as big-endian 8 byte records, in chunks.
+func WriteCodes(w io.Writer, codes []uint64) error {
+	_, err := Codes(codes).WriteTo(w)
+	return err
+}
+
+// Code slice with bulk stream reading and writing.
+type Codes []uint64
+
+var (
+	_ io.WriterTo   = Codes(nil)
+	_ io.ReaderFrom = (*Codes)(nil)
+)
+
+func (c Codes) WriteTo(w io.Writer) (n int64, err error) {
+	buf := make([]byte, codeChunk*codeRecordSize)
+	for len(c) > 0 {
+		k := len(c)
+		if k > codeChunk {
+			k = codeChunk
+		}
+		for i, code := range c[:k] {
+			binary.BigEndian.PutUint64(buf[i*codeRecordSize:], code)
+		}
+
+		var written int
+		written, err = w.Write(buf[:k*codeRecordSize])
+		n += int64(written)
+		if err != nil {
+			return
+		}
+		c = c[k:]
+	}
+	return
+}
+
+// Appends every record until EOF.  A trailing partial record yields ErrTruncatedRecord, with the complete codes still appended.
+func (c *Codes) ReadFrom(r io.Reader) (n int64, err error) {
+	buf := make([]byte, codeChunk*codeRecordSize)
+	pending := 0
+	for {
+		var read int
+		read, err = r.Read(buf[pending:])
+		n += int64(read)
+		pending += read
+
+		whole := pending / codeRecordSize * codeRecordSize
+		for i := 0; i < whole; i += codeRecordSize {
+			*c = append(*c, binary.BigEndian.Uint64(buf[i:]))
+		}
+		pending = copy(buf, buf[whole:pending])
+
+		if err == io.EOF {
+			err = nil
+			if pending != 0 {
+				err = fmt.Errorf("%w after %v codes", ErrTruncatedRecord, len(*c))
+			}
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Reads codes one at a time, or in bulk, from a stream of records.
+type CodesReader struct {
+	r *bufio.Reader
+	// Number of complete codes read
+	count int
+}
+
+func NewCodesReader(r io.Reader) *CodesReader {
+	return &CodesReader{r: bufio.NewReader(r)}
+}
+
+// Number of complete codes read so far.
+func (c *CodesReader) Count() int {
+	return c.count
+}
+
+// Reads and validates the optional stream header, returning its dimensions.
+func (c *CodesReader) ReadHeader() (dimensions uint8, err error) {
+	var h [codeRecordSize]byte
+	if _, err = io.ReadFull(c.r, h[:]); err != nil {
+		return
+	}
+	if [4]byte(h[:4]) != codeStreamMagic {
+		err = errors.New("Code stream header is missing")
+		return
+	}
+	if h[4] != codeStreamVersion {
+		err = errors.New(fmt.Sprint("Unsupported code stream version, ", h[4]))
+		return
+	}
+	return h[5], nil
+}
+
+// Reads the next code.  It returns io.EOF at the end of the stream, or ErrTruncatedRecord if the stream ends mid-record.
+func (c *CodesReader) Next() (uint64, error) {
+	var rec [codeRecordSize]byte
+	switch _, err := io.ReadFull(c.r, rec[:]); err {
+	case nil:
+	case io.ErrUnexpectedEOF:
+		return 0, fmt.Errorf("%w after %v codes", ErrTruncatedRecord, c.count)
+	default:
+		return 0, err
+	}
+	c.count++
+	return binary.BigEndian.Uint64(rec[:]), nil
+}
+
+// Reads up to len(dst) codes, returning how many were read.  At the end of the stream, it returns io.EOF once no codes remain.
+func (c *CodesReader) Read(dst []uint64) (n int, err error) {
+	for n < len(dst) {
+		if dst[n], err = c.Next(); err != nil {
+			if err == io.EOF && n > 0 {
+				err = nil
+			}
+			return
+		}
+		n++
+	}
+	return
+}
@@ -0,0 +1,122 @@
+package morton
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// Header for 3 dimensions, then the codes 0, 0x35, 0x0123456789abcdef and the maximum.
+var goldenCodes = []uint64{0, 0x35, 0x0123456789abcdef, 1<<64 - 1}
+
+func TestCodesGolden(t *testing.T) {
+	golden, err := os.ReadFile("testdata/codes.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteCodesHeader(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteCodes(&buf, goldenCodes); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Fatalf("WriteCodes wrote % x, want % x", buf.Bytes(), golden)
+	}
+
+	r := NewCodesReader(bytes.NewReader(golden))
+	if dims, err := r.ReadHeader(); err != nil || dims != 3 {
+		t.Fatalf("ReadHeader() = %v, %v", dims, err)
+	}
+	for i, want := range goldenCodes {
+		if got, err := r.Next(); err != nil || got != want {
+			t.Fatalf("Next() at %v = %x, %v; want %x", i, got, err, want)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() at the end returned %v, want io.EOF", err)
+	}
+	if r.Count() != len(goldenCodes) {
+		t.Errorf("Count() = %v, want %v", r.Count(), len(goldenCodes))
+	}
+}
+
+func TestCodesPipe(t *testing.T) {
+	// More than one chunk, so writes and reads span several calls
+	rnd := rand.New(rand.NewSource(120))
+	codes := make([]uint64, 3*codeChunk+7)
+	for i := range codes {
+		codes[i] = rnd.Uint64()
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(WriteCodes(pw, codes))
+	}()
+	var got Codes
+	if _, err := got.ReadFrom(pr); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(codes) {
+		t.Fatalf("ReadFrom read %v codes, want %v", len(got), len(codes))
+	}
+	for i := range codes {
+		if got[i] != codes[i] {
+			t.Fatalf("ReadFrom read %x at %v, want %x", got[i], i, codes[i])
+		}
+	}
+
+	// And in bulk through a CodesReader
+	pr, pw = io.Pipe()
+	go func() {
+		_, err := Codes(codes).WriteTo(pw)
+		pw.CloseWithError(err)
+	}()
+	r := NewCodesReader(pr)
+	dst := make([]uint64, 100)
+	var read []uint64
+	for {
+		n, err := r.Read(dst)
+		read = append(read, dst[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(read) != len(codes) || read[len(read)-1] != codes[len(codes)-1] || r.Count() != len(codes) {
+		t.Errorf("CodesReader read %v codes, counted %v, want %v", len(read), r.Count(), len(codes))
+	}
+}
+
+func TestCodesTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	WriteCodes(&buf, []uint64{1, 2, 3})
+	stream := buf.Bytes()[:2*codeRecordSize+5]
+
+	var got Codes
+	if _, err := got.ReadFrom(bytes.NewReader(stream)); !errors.Is(err, ErrTruncatedRecord) || len(got) != 2 {
+		t.Errorf("ReadFrom of a truncated stream read %v, %v; want 2 codes and ErrTruncatedRecord", got, err)
+	}
+
+	r := NewCodesReader(bytes.NewReader(stream))
+	r.Next()
+	r.Next()
+	if _, err := r.Next(); !errors.Is(err, ErrTruncatedRecord) || r.Count() != 2 {
+		t.Errorf("Next() of a truncated record returned %v after %v codes", err, r.Count())
+	}
+}
+
+func TestCodesHeaderRejects(t *testing.T) {
+	if _, err := NewCodesReader(bytes.NewReader(make([]byte, 8))).ReadHeader(); err == nil {
+		t.Error("ReadHeader without a header succeeded")
+	}
+	if _, err := NewCodesReader(bytes.NewReader([]byte("MRTN\x02\x03\x00\x00"))).ReadHeader(); err == nil {
+		t.Error("ReadHeader of version 2 succeeded")
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Generates libmorton_vectors.txt, the golden vectors of TestLibmortonVectors.
+
+The encoders below are libmorton's 64 bit "magicbits" encoders, morton2D_64_encode and
+morton3D_64_encode (morton2D.h and morton3D.h of https://github.com/Forceflow/libmorton),
+transcribed with their masks and shifts unchanged, so that the vectors do not depend on
+this package.  To regenerate, from the repository root:
+
+	cc -O2 -o /tmp/gen testdata/libmorton/gen.c && /tmp/gen > testdata/libmorton_vectors.txt
+
+Each line is the number of dimensions, the coordinates and the code, in decimal.
+*/
+#include <stdint.h>
+#include <stdio.h>
+
+static const uint64_t magicbit2D_masks64[6] = {
+	0x00000000FFFFFFFF, 0x0000FFFF0000FFFF, 0x00FF00FF00FF00FF,
+	0x0F0F0F0F0F0F0F0F, 0x3333333333333333, 0x5555555555555555};
+
+static const uint64_t magicbit3D_masks64[6] = {
+	0x1fffff, 0x1f00000000ffff, 0x1f0000ff0000ff,
+	0x100f00f00f00f00f, 0x10c30c30c30c30c3, 0x1249249249249249};
+
+static uint64_t morton2D_SplitBy2Bits(uint64_t a) {
+	const uint64_t *masks = magicbit2D_masks64;
+	uint64_t x = a & masks[0];
+	x = (x | x << 16) & masks[1];
+	x = (x | x << 8) & masks[2];
+	x = (x | x << 4) & masks[3];
+	x = (x | x << 2) & masks[4];
+	x = (x | x << 1) & masks[5];
+	return x;
+}
+
+static uint64_t morton2D_64_encode(uint32_t x, uint32_t y) {
+	return morton2D_SplitBy2Bits(x) | (morton2D_SplitBy2Bits(y) << 1);
+}
+
+static uint64_t morton3D_SplitBy3bits(uint32_t a) {
+	const uint64_t *masks = magicbit3D_masks64;
+	uint64_t x = ((uint64_t)a) & masks[0];
+	x = (x | x << 32) & masks[1];
+	x = (x | x << 16) & masks[2];
+	x = (x | x << 8) & masks[3];
+	x = (x | x << 4) & masks[4];
+	x = (x | x << 2) & masks[5];
+	return x;
+}
+
+static uint64_t morton3D_64_encode(uint32_t x, uint32_t y, uint32_t z) {
+	return morton3D_SplitBy3bits(x) | (morton3D_SplitBy3bits(y) << 1) | (morton3D_SplitBy3bits(z) << 2);
+}
+
+static uint64_t state = 0x9E3779B97F4A7C15;
+
+static uint64_t next(void) {
+	state ^= state << 13;
+	state ^= state >> 7;
+	state ^= state << 17;
+	return state;
+}
+
+/* Coordinate of a random width up to bits, so that small and large values both occur. */
+static uint32_t coord(int bits) {
+	int width = 1 + next() % bits;
+	return (uint32_t)(next() & ((UINT64_C(1) << width) - 1));
+}
+
+int main(void) {
+	static const uint32_t edges2[][2] = {{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0xFFFFFFFF, 0}, {0, 0xFFFFFFFF}, {0xFFFFFFFF, 0xFFFFFFFF}, {0xFFFF, 0xFFFF}};
+	static const uint32_t edges3[][3] = {{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, 2, 4}, {0x1FFFFF, 0, 0}, {0, 0, 0x1FFFFF}, {0x1FFFFF, 0x1FFFFF, 0x1FFFFF}, {0xFFFF, 0xFFFF, 0xFFFF}};
+	int i;
+
+	for (i = 0; i < (int)(sizeof edges2 / sizeof edges2[0]); i++)
+		printf("2 %u %u %llu\n", edges2[i][0], edges2[i][1], (unsigned long long)morton2D_64_encode(edges2[i][0], edges2[i][1]));
+	for (i = 0; i < 192; i++) {
+		uint32_t x = coord(32), y = coord(32);
+		printf("2 %u %u %llu\n", x, y, (unsigned long long)morton2D_64_encode(x, y));
+	}
+	for (i = 0; i < (int)(sizeof edges3 / sizeof edges3[0]); i++)
+		printf("3 %u %u %u %llu\n", edges3[i][0], edges3[i][1], edges3[i][2], (unsigned long long)morton3D_64_encode(edges3[i][0], edges3[i][1], edges3[i][2]));
+	for (i = 0; i < 191; i++) {
+		uint32_t x = coord(21), y = coord(21), z = coord(21);
+		printf("3 %u %u %u %llu\n", x, y, z, (unsigned long long)morton3D_64_encode(x, y, z));
+	}
+	return 0;
+}
@@ -0,0 +1,76 @@
+package morton
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// Block-linear texture layout: Morton order within fixed size tiles, row-major across tiles.  Images whose width is not a multiple of the tile width are padded with partial tiles, so each row of tiles spans ceil(imageWidth / tile width) whole tiles, and offsets within the padding are simply never produced.
+type TileSwizzler struct {
+	TileWidth, TileHeight uint32
+
+	// Bits of tile width and height
+	bw, bh uint
+}
+
+// Tile dimensions must be non-zero powers of two.
+func NewTileSwizzler(tileWidth, tileHeight uint32) (*TileSwizzler, error) {
+	if bits.OnesCount32(tileWidth) != 1 || bits.OnesCount32(tileHeight) != 1 {
+		return nil, errors.New("Tile dimensions must be powers of two")
+	}
+	return &TileSwizzler{
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+		bw:         uint(bits.TrailingZeros32(tileWidth)),
+		bh:         uint(bits.TrailingZeros32(tileHeight)),
+	}, nil
+}
+
+// Tiles per row of an image of the given width.
+func (t *TileSwizzler) tilesPerRow(imageWidth uint32) uint64 {
+	return (uint64(imageWidth) + uint64(t.TileWidth) - 1) >> t.bw
+}
+
+// Offset of pixel (x, y) in an image of the given width, x < imageWidth.
+func (t *TileSwizzler) Offset(x, y, imageWidth uint32) uint64 {
+	tile := uint64(y>>t.bh)*t.tilesPerRow(imageWidth) + uint64(x>>t.bw)
+	lx, ly := x&(t.TileWidth-1), y&(t.TileHeight-1)
+
+	// Interleave the bits both axes share, then stack the longer axis' extra bits on top
+	k := t.bw
+	if t.bh < k {
+		k = t.bh
+	}
+	low := uint32(1)<<k - 1
+	intra := Dilate(lx&low, 2) | Dilate(ly&low, 2)<<1
+	intra |= uint64(lx>>k|ly>>k) << (2 * k)
+
+	return tile<<(t.bw+t.bh) | intra
+}
+
+// Inverse of Offset.
+func (t *TileSwizzler) Coord(offset uint64, imageWidth uint32) (x, y uint32) {
+	perRow := t.tilesPerRow(imageWidth)
+	if perRow == 0 {
+		return
+	}
+	tile := offset >> (t.bw + t.bh)
+	intra := offset & (1<<(t.bw+t.bh) - 1)
+
+	k := t.bw
+	if t.bh < k {
+		k = t.bh
+	}
+	lx, ly := Undilate(intra, 2), Undilate(intra>>1, 2)
+	lx &= 1<<k - 1
+	ly &= 1<<k - 1
+	if extra := uint32(intra >> (2 * k)); t.bw > t.bh {
+		lx |= extra << k
+	} else {
+		ly |= extra << k
+	}
+
+	x = uint32(tile%perRow)<<t.bw | lx
+	y = uint32(tile/perRow)<<t.bh | ly
+	return
+}
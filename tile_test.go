@@ -0,0 +1,60 @@
+package morton
+
+import "testing"
+
+func TestTileSwizzlerBijection(t *testing.T) {
+	const width, height = 67, 35
+	for _, tile := range [][2]uint32{{8, 8}, {32, 32}, {16, 4}, {2, 8}, {1, 1}} {
+		s, err := NewTileSwizzler(tile[0], tile[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Padded row of tiles, and padded rows
+		perRow := (uint64(width) + uint64(tile[0]) - 1) / uint64(tile[0])
+		rows := (uint64(height) + uint64(tile[1]) - 1) / uint64(tile[1])
+		limit := perRow * rows * uint64(tile[0]) * uint64(tile[1])
+
+		seen := make(map[uint64]bool)
+		for y := uint32(0); y < height; y++ {
+			for x := uint32(0); x < width; x++ {
+				off := s.Offset(x, y, width)
+				if off >= limit {
+					t.Fatalf("%vx%v tiles: Offset(%v, %v) = %v, beyond the padded image of %v", tile[0], tile[1], x, y, off, limit)
+				}
+				if seen[off] {
+					t.Fatalf("%vx%v tiles: offset %v is repeated at (%v, %v)", tile[0], tile[1], off, x, y)
+				}
+				seen[off] = true
+				if gx, gy := s.Coord(off, width); gx != x || gy != y {
+					t.Fatalf("%vx%v tiles: Coord(Offset(%v, %v)) = %v, %v", tile[0], tile[1], x, y, gx, gy)
+				}
+			}
+		}
+	}
+}
+
+func TestTileSwizzlerLayout(t *testing.T) {
+	s, _ := NewTileSwizzler(8, 8)
+	// Morton order within the first tile
+	for y := uint32(0); y < 8; y++ {
+		for x := uint32(0); x < 8; x++ {
+			if got, want := s.Offset(x, y, 20), Dilate(x, 2)|Dilate(y, 2)<<1; got != want {
+				t.Fatalf("Offset(%v, %v) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+	// Row-major across the 3 tiles per row of a width of 20
+	for _, tc := range []struct{ x, y, tile uint32 }{{8, 0, 1}, {16, 0, 2}, {0, 8, 3}, {19, 9, 5}} {
+		if got := s.Offset(tc.x, tc.y, 20) / 64; got != uint64(tc.tile) {
+			t.Errorf("Offset(%v, %v) is in tile %v, want %v", tc.x, tc.y, got, tc.tile)
+		}
+	}
+}
+
+func TestNewTileSwizzlerRejects(t *testing.T) {
+	for _, tile := range [][2]uint32{{0, 8}, {8, 0}, {6, 8}, {8, 12}} {
+		if _, err := NewTileSwizzler(tile[0], tile[1]); err == nil {
+			t.Errorf("NewTileSwizzler(%v, %v) succeeded", tile[0], tile[1])
+		}
+	}
+}
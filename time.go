@@ -0,0 +1,168 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+/*
+Time-space codes use dimension 0 for time, counted in units (seconds by default, see WithTimeUnit) since the Unix epoch, and the remaining
+dimensions for space.  Since time is a table dimension like any other, the table length bounds the time span as well as the spatial
+extent: for example, a 4 dimension Morton of size 2^16 covers 2^16 seconds (about 18 hours) at second precision, or 2^16 days (about 179
+years) at day precision, alongside 2^16 cells per spatial axis.  Coarser time units buy span without costing spatial precision.
+*/
+
+// Sets the unit in which EncodeTime counts time since the Unix epoch, e.g. 24 * time.Hour for days.
+func WithTimeUnit(unit time.Duration) Option {
+	return func(m *Morton) {
+		m.timeUnit = unit
+	}
+}
+
+func (m *Morton) unit() time.Duration {
+	if m.timeUnit <= 0 {
+		return time.Second
+	}
+	return m.timeUnit
+}
+
+// Encodes t, truncated to the time unit, as dimension 0 alongside spatialCoords, for a Morton of spatialDims + 1 dimensions.  Codes at the same location one time unit apart therefore differ by exactly the dilated step of dimension 0.
+func (m *Morton) EncodeTime(t time.Time, spatialDims uint8, spatialCoords []uint32) (uint64, error) {
+	if int(spatialDims)+1 != int(m.Dimensions) || len(spatialCoords) != int(spatialDims) {
+		return 0, ErrDimensionMismatch
+	}
+
+	if t.Before(time.Unix(0, 0)) {
+		return 0, errors.New("Times before the Unix epoch cannot be encoded")
+	}
+
+	// Whole second units count from Unix seconds, avoiding Duration overflow beyond 292 years
+	unit := m.unit()
+	var ticks int64
+	if unit%time.Second == 0 {
+		ticks = t.Unix() / int64(unit/time.Second)
+	} else {
+		ticks = t.UnixNano() / int64(unit)
+	}
+	if len(m.Tables) == 0 || uint64(ticks) >= uint64(m.Tables[0].Length) {
+		return 0, fmt.Errorf("%w.  Time %v exceeds %v units of %v", ErrComponentOverflow, t, m.tableLength(0), unit)
+	}
+
+	vector := make([]uint32, 0, m.Dimensions)
+	vector = append(vector, uint32(ticks))
+	return m.Encode(append(vector, spatialCoords...))
+}
+
+// Inverse of EncodeTime; t is the start of its time unit, in UTC.
+func (m *Morton) DecodeTime(code uint64) (t time.Time, spatialCoords []uint32) {
+	v := m.Decode(code)
+	if len(v) == 0 {
+		return
+	}
+	unit := m.unit()
+	if unit%time.Second == 0 {
+		return time.Unix(int64(v[0])*int64(unit/time.Second), 0).UTC(), v[1:]
+	}
+	return time.Unix(0, int64(v[0])*int64(unit)).UTC(), v[1:]
+}
+
+func (m *Morton) tableLength(dim int) uint32 {
+	if dim >= len(m.Tables) {
+		return 0
+	}
+	return m.Tables[dim].Length
+}
+
+// Keys events by time and place: dimension 0 counts Granularity buckets since Epoch, in TimeBits bits, and the remaining dimensions are the cells of the Quantizer's axes.  Buckets are fixed durations, so daylight saving transitions neither shift nor resize them; for local calendar days, start Epoch at a local midnight and expect the hour of a transition to drift.
+type SpatioTemporalKeyer struct {
+	Epoch       time.Time
+	Granularity time.Duration
+	TimeBits    uint8
+	Quantizer   *Quantizer
+	Morton      *Morton
+}
+
+// Keyer over 2^timeBits buckets of granularity from epoch.  Every dimension gets as many bits as the widest, whether time or an axis, at most 21 as for other tables, and all of them must fit in 64.  Axes must have Bits set, rather than CellSize.
+func NewSpatioTemporalKeyer(epoch time.Time, granularity time.Duration, timeBits uint8, q *Quantizer) (*SpatioTemporalKeyer, error) {
+	if granularity <= 0 {
+		return nil, errors.New("Time granularity must be positive")
+	}
+	if q == nil || len(q.Axes) == 0 {
+		return nil, errors.New("Spatio-temporal keys require a quantizer with at least one axis")
+	}
+	bits := timeBits
+	for i, a := range q.Axes {
+		if a.Bits == 0 {
+			return nil, errors.New(fmt.Sprint("Axis ", i, " must set Bits"))
+		}
+		if a.Bits > bits {
+			bits = a.Bits
+		}
+	}
+	d := len(q.Axes) + 1
+	if timeBits == 0 || bits > maxTableBits {
+		return nil, errors.New(fmt.Sprint("Spatio-temporal keys require between 1 and ", maxTableBits, " bits per dimension, not ", bits))
+	}
+	if d*int(bits) > 64 {
+		return nil, fmt.Errorf("%w.  Spatio-temporal keys of %v dimensions of %v bits exceed 64 bits", ErrCapacityExceeded, d, bits)
+	}
+	m := new(Morton)
+	if err := m.Create(uint8(d), 1<<bits); err != nil {
+		return nil, err
+	}
+	return &SpatioTemporalKeyer{epoch, granularity, timeBits, q, m}, nil
+}
+
+// Bucket of t.
+func (k *SpatioTemporalKeyer) bucket(t time.Time) (uint32, error) {
+	since := t.Sub(k.Epoch)
+	if since < 0 {
+		return 0, errors.New(fmt.Sprint("Time ", t, " precedes the epoch ", k.Epoch))
+	}
+	b := since / k.Granularity
+	if since == math.MaxInt64 || b >= 1<<k.TimeBits {
+		return 0, fmt.Errorf("%w.  Time %v exceeds %v buckets of %v", ErrComponentOverflow, t, uint64(1)<<k.TimeBits, k.Granularity)
+	}
+	return uint32(b), nil
+}
+
+func (k *SpatioTemporalKeyer) Encode(t time.Time, coords []float64) (uint64, error) {
+	b, err := k.bucket(t)
+	if err != nil {
+		return 0, err
+	}
+	cells, err := k.Quantizer.Quantize(coords)
+	if err != nil {
+		return 0, err
+	}
+	return k.Morton.Encode(append([]uint32{b}, cells...))
+}
+
+// Inverse of Encode: the start of the time bucket and the centers of the cells.
+func (k *SpatioTemporalKeyer) Decode(code uint64) (bucket time.Time, coords []float64) {
+	v := k.Morton.Decode(code)
+	return k.Epoch.Add(time.Duration(v[0]) * k.Granularity), k.Quantizer.CellCenter(v[1:])
+}
+
+// Code ranges of the keys from the bucket of from to that of to, inclusive, within the box [min, max]; see RangeDecompose.
+func (k *SpatioTemporalKeyer) Query(from, to time.Time, min, max []float64) ([]Range, error) {
+	lo, err := k.bucket(from)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := k.bucket(to)
+	if err != nil {
+		return nil, err
+	}
+	cmin, err := k.Quantizer.Quantize(min)
+	if err != nil {
+		return nil, err
+	}
+	cmax, err := k.Quantizer.Quantize(max)
+	if err != nil {
+		return nil, err
+	}
+	return k.Morton.RangeDecompose(append([]uint32{lo}, cmin...), append([]uint32{hi}, cmax...))
+}
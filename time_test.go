@@ -0,0 +1,257 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestTimeRoundTrip(t *testing.T) {
+	m := New(3, 1<<16)
+	for _, when := range []time.Time{time.Unix(0, 0), time.Unix(12345, 0), time.Unix(1<<16-1, 999999999)} {
+		code, err := m.EncodeTime(when, 2, []uint32{7, 40000})
+		if err != nil {
+			t.Fatalf("EncodeTime(%v): %v", when, err)
+		}
+		got, coords := m.DecodeTime(code)
+		if want := when.Truncate(time.Second).UTC(); !got.Equal(want) || !equalUint32s(coords, []uint32{7, 40000}) {
+			t.Errorf("DecodeTime(EncodeTime(%v)) = %v, %v; want %v", when, got, coords, want)
+		}
+	}
+
+	days := New(2, 1<<16, WithTimeUnit(24*time.Hour))
+	when := time.Date(2024, 2, 29, 13, 45, 0, 0, time.UTC)
+	code, err := days.EncodeTime(when, 1, []uint32{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := days.DecodeTime(code); !got.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("DecodeTime at day precision = %v, want the start of the day", got)
+	}
+
+	millis := New(2, 1<<20, WithTimeUnit(time.Millisecond))
+	code, _ = millis.EncodeTime(time.Unix(3, 5e6+7), 1, []uint32{1})
+	if got, _ := millis.DecodeTime(code); !got.Equal(time.Unix(3, 5e6)) {
+		t.Errorf("DecodeTime at millisecond precision = %v", got)
+	}
+}
+
+// Codes at one location one time unit apart differ by one step of dimension 0's lane, leaving the spatial lanes alone.
+func TestTimeStep(t *testing.T) {
+	m := New(3, 1<<12, WithTimeUnit(time.Minute))
+	mask := MaskForDimension(3, 0)
+	start := time.Unix(0, 0).Add(17 * time.Minute)
+	prev, _ := m.EncodeTime(start, 2, []uint32{100, 200})
+	for i := 1; i < 1000; i++ {
+		code, err := m.EncodeTime(start.Add(time.Duration(i)*time.Minute), 2, []uint32{100, 200})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code&^mask != prev&^mask {
+			t.Fatalf("Spatial lanes changed between minutes %v and %v", i-1, i)
+		}
+		if step := SubDilated(code, prev, mask); step != 1 {
+			t.Fatalf("Minutes %v and %v differ by %v in dimension 0, want 1", i-1, i, step)
+		}
+		prev = code
+	}
+}
+
+func TestTimeRejects(t *testing.T) {
+	m := New(3, 1<<8)
+	if _, err := m.EncodeTime(time.Unix(1, 0), 3, []uint32{1, 2, 3}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("EncodeTime of 3 spatial dimensions returned %v", err)
+	}
+	if _, err := m.EncodeTime(time.Unix(1, 0), 2, []uint32{1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("EncodeTime of 1 spatial coordinate returned %v", err)
+	}
+	if _, err := m.EncodeTime(time.Unix(-1, 0), 2, []uint32{1, 2}); err == nil {
+		t.Error("EncodeTime before the epoch succeeded")
+	}
+	if _, err := m.EncodeTime(time.Unix(256, 0), 2, []uint32{1, 2}); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("EncodeTime beyond the table returned %v", err)
+	}
+}
+
+// Keyer of longitude and latitude, with bits for time and for each axis.
+func newTestKeyer(t *testing.T, epoch time.Time, granularity time.Duration, bits uint8) *SpatioTemporalKeyer {
+	t.Helper()
+	q, err := NewQuantizer(Axis{Min: -180, Max: 180, Bits: bits, Policy: PolicyWrap}, Axis{Min: -90, Max: 90, Bits: bits, Policy: PolicyClamp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewSpatioTemporalKeyer(epoch, granularity, bits, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSpatioTemporalRoundTrip(t *testing.T) {
+	epoch := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	k := newTestKeyer(t, epoch, time.Hour, 16)
+	r := rand.New(rand.NewSource(144))
+	for n := 0; n < 1000; n++ {
+		at := epoch.Add(time.Duration(r.Int63n(int64(time.Hour) << 16)))
+		coords := []float64{r.Float64()*360 - 180, r.Float64()*180 - 90}
+		code, err := k.Encode(at, coords)
+		if err != nil {
+			t.Fatalf("Encode(%v, %v): %v", at, coords, err)
+		}
+		bucket, got := k.Decode(code)
+		if want := epoch.Add(at.Sub(epoch).Truncate(time.Hour)); !bucket.Equal(want) {
+			t.Fatalf("Decode(Encode(%v)) has bucket %v, want %v", at, bucket, want)
+		}
+		if math.Abs(got[0]-coords[0]) > 180.0/65536 || math.Abs(got[1]-coords[1]) > 90.0/65536 {
+			t.Fatalf("Decode(Encode(%v)) = %v, beyond half a cell", coords, got)
+		}
+	}
+}
+
+func TestSpatioTemporalEpochEdges(t *testing.T) {
+	epoch := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	k := newTestKeyer(t, epoch, time.Hour, 16)
+	here := []float64{0, 0}
+	last := epoch.Add(time.Hour<<16 - 1)
+	for _, at := range []time.Time{epoch, epoch.Add(time.Hour - 1), last} {
+		if _, err := k.Encode(at, here); err != nil {
+			t.Errorf("Encode at %v: %v", at, err)
+		}
+	}
+	if code, _ := k.Encode(last, here); k.Morton.Decode(code)[0] != 1<<16-1 {
+		t.Errorf("Encode at the last moment is in bucket %v", k.Morton.Decode(code)[0])
+	}
+	if _, err := k.Encode(epoch.Add(-1), here); err == nil {
+		t.Error("Encode before the epoch succeeded")
+	}
+	if _, err := k.Encode(last.Add(1), here); !errors.Is(err, ErrComponentOverflow) {
+		t.Errorf("Encode beyond the last bucket returned %v, want ErrComponentOverflow", err)
+	}
+	// An epoch in another zone is the same instant
+	if code, _ := k.Encode(epoch.In(time.FixedZone("UTC+5", 5*3600)), here); k.Morton.Decode(code)[0] != 0 {
+		t.Errorf("the epoch in UTC+5 is in bucket %v", k.Morton.Decode(code)[0])
+	}
+}
+
+func TestSpatioTemporalDaylightSaving(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	k := newTestKeyer(t, time.Date(2026, 3, 1, 0, 0, 0, 0, ny), time.Hour, 16)
+	here := []float64{-74, 40.7}
+	bucket := func(at time.Time) uint32 {
+		code, err := k.Encode(at, here)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k.Morton.Decode(code)[0]
+	}
+	// Clocks spring forward at 2:00 on March 8, so 1:30 and 3:30 are an hour apart
+	before, after := bucket(time.Date(2026, 3, 8, 1, 30, 0, 0, ny)), bucket(time.Date(2026, 3, 8, 3, 30, 0, 0, ny))
+	if after != before+1 {
+		t.Errorf("1:30 and 3:30 across the spring transition are in buckets %v and %v", before, after)
+	}
+	// and fall back at 2:00 on November 1, repeating 1:00 to 2:00
+	first := time.Date(2026, 11, 1, 1, 30, 0, 0, ny)
+	if second := first.Add(time.Hour); bucket(second) != bucket(first)+1 || second.Hour() != 1 {
+		t.Errorf("the repeated 1:30 is in bucket %v after %v", bucket(second), bucket(first))
+	}
+
+	// Daily buckets from a local midnight drift by the transition's hour
+	daily := newTestKeyer(t, time.Date(2026, 3, 1, 0, 0, 0, 0, ny), 24*time.Hour, 16)
+	code, _ := daily.Encode(time.Date(2026, 3, 9, 0, 30, 0, 0, ny), here)
+	if b := daily.Morton.Decode(code)[0]; b != 7 {
+		t.Errorf("0:30 on March 9 is in daily bucket %v, want 7 after the lost hour", b)
+	}
+}
+
+func TestSpatioTemporalQuery(t *testing.T) {
+	epoch := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Hours over 42 days, and cells of a third of a degree
+	k := newTestKeyer(t, epoch, time.Hour, 10)
+	r := rand.New(rand.NewSource(144))
+	type event struct {
+		at     time.Time
+		coords []float64
+		code   uint64
+	}
+	events := make([]event, 3000)
+	for i := range events {
+		e := event{epoch.Add(time.Duration(r.Int63n(int64(40 * 24 * time.Hour)))), []float64{r.Float64()*40 - 20, r.Float64()*40 - 20}, 0}
+		e.code, _ = k.Encode(e.at, e.coords)
+		events[i] = e
+	}
+
+	// This week, within the box
+	from, to := epoch.Add(14*24*time.Hour), epoch.Add(21*24*time.Hour-1)
+	min, max := []float64{-5, -10}, []float64{8, 3}
+	ranges, err := k.Query(from, to, min, max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := 0
+	for _, e := range events {
+		var in bool
+		for _, rg := range ranges {
+			in = in || e.code >= rg.Lo && e.code <= rg.Hi
+		}
+		// Box edges are cell edges, so compare cells rather than coordinates
+		cells, _ := k.Quantizer.Quantize(e.coords)
+		lo, _ := k.Quantizer.Quantize(min)
+		hi, _ := k.Quantizer.Quantize(max)
+		want := !e.at.Before(from) && !e.at.After(to) && inBox(cells, lo, hi)
+		if in != want {
+			t.Fatalf("event at %v, %v is in the query ranges %v, want %v", e.at, e.coords, in, want)
+		}
+		if want {
+			found++
+		}
+	}
+	if found == 0 {
+		t.Error("no events fall in the query")
+	}
+}
+
+func TestSpatioTemporalRejects(t *testing.T) {
+	epoch := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	q16, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 16}, Axis{Min: 0, Max: 1, Bits: 16})
+	q22, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 22}, Axis{Min: 0, Max: 1, Bits: 22})
+	sized, _ := NewQuantizer(Axis{Min: 0, Max: 1, CellSize: 0.25})
+	// 2 dimensions of 22 bits fit in 64, but not the tables' 21 bit limit
+	q1, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 8})
+	for _, tc := range []struct {
+		name        string
+		granularity time.Duration
+		timeBits    uint8
+		q           *Quantizer
+	}{
+		{"zero granularity", 0, 16, q16},
+		{"no time bits", time.Hour, 0, q16},
+		{"no quantizer", time.Hour, 16, nil},
+		{"no axes", time.Hour, 16, &Quantizer{}},
+		{"cell size axes", time.Hour, 16, sized},
+		{"3 dimensions of 22 bits", time.Hour, 16, q22},
+		{"32 time bits", time.Hour, 32, q16},
+		{"22 bits per dimension", time.Hour, 22, q1},
+	} {
+		if _, err := NewSpatioTemporalKeyer(epoch, tc.granularity, tc.timeBits, tc.q); err == nil {
+			t.Errorf("NewSpatioTemporalKeyer with %v succeeded", tc.name)
+		}
+	}
+	// 3 dimensions of 21 bits fit
+	k, err := NewSpatioTemporalKeyer(epoch, time.Hour, 21, q16)
+	if err != nil {
+		t.Fatalf("NewSpatioTemporalKeyer of 3 dimensions of 21 bits: %v", err)
+	}
+	if size := k.Morton.CurrentTableSize(); size != 1<<21 {
+		t.Errorf("keyer tables hold %v entries, want %v", size, 1<<21)
+	}
+	// 4 dimensions of 17 bits are within the tables' limit, but not 64 bits
+	q17, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 17}, Axis{Min: 0, Max: 1, Bits: 17}, Axis{Min: 0, Max: 1, Bits: 17})
+	if _, err := NewSpatioTemporalKeyer(epoch, time.Hour, 8, q17); !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("NewSpatioTemporalKeyer of 4 dimensions of 17 bits returned %v, want ErrCapacityExceeded", err)
+	}
+}
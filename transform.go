@@ -0,0 +1,106 @@
+package morton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Affine frame applied by EncodeFloat before quantizing, and reversed by DecodeFloat: values are offset, rotated counterclockwise by Rotation (2 dimensions only), then scaled.
+type Transform struct {
+	Offset   []float64
+	Scale    []float64
+	Rotation float64
+}
+
+// Sets the transform from world coordinates into the frame quantized by EncodeFloat: v' = (R(rotation) (v - offset)) * scale, per component.  The transform is part of the configuration, so every caller sharing a Morton, or loading a saved one (see MarshalBinary), agrees on the frame.
+func WithTransform(offset, scale []float64) Option {
+	return func(m *Morton) {
+		t := m.transformOrNew()
+		t.Offset = append([]float64(nil), offset...)
+		t.Scale = append([]float64(nil), scale...)
+	}
+}
+
+// Rotates 2 dimensional values counterclockwise by angle radians, after the offset of WithTransform and before its scale.
+func WithRotation(angle float64) Option {
+	return func(m *Morton) {
+		m.transformOrNew().Rotation = angle
+	}
+}
+
+func (m *Morton) transformOrNew() *Transform {
+	if m.transform == nil {
+		m.transform = new(Transform)
+	}
+	return m.transform
+}
+
+// Copy of the configured transform, or nil if there is none.
+func (m *Morton) Transform() *Transform {
+	if m.transform == nil {
+		return nil
+	}
+	return &Transform{append([]float64(nil), m.transform.Offset...), append([]float64(nil), m.transform.Scale...), m.transform.Rotation}
+}
+
+// Validates t against the number of dimensions.  Missing offsets default to 0 and missing scales to 1, once the lengths check out.
+func (t *Transform) check(dimensions uint8) error {
+	d := int(dimensions)
+	if t.Offset == nil {
+		t.Offset = make([]float64, d)
+	}
+	if t.Scale == nil {
+		t.Scale = make([]float64, d)
+		for i := range t.Scale {
+			t.Scale[i] = 1
+		}
+	}
+	if len(t.Offset) != d || len(t.Scale) != d {
+		return fmt.Errorf("%w.  Transform offset and scale need %v components", ErrDimensionMismatch, d)
+	}
+	for i := range t.Offset {
+		if math.IsNaN(t.Offset[i]) || math.IsInf(t.Offset[i], 0) || t.Scale[i] == 0 || math.IsNaN(t.Scale[i]) || math.IsInf(t.Scale[i], 0) {
+			return errors.New(fmt.Sprint("Transform of component ", i, " must have a finite offset and a finite, non-zero scale"))
+		}
+	}
+	if math.IsNaN(t.Rotation) || math.IsInf(t.Rotation, 0) {
+		return errors.New("Transform rotation must be finite")
+	}
+	if t.Rotation != 0 && d != 2 {
+		return fmt.Errorf("%w.  Transform rotation requires 2 dimensions", ErrDimensionMismatch)
+	}
+	return nil
+}
+
+// Maps world values into the frame.
+func (t *Transform) forward(values []float64) []float64 {
+	v := make([]float64, len(values))
+	for i := range v {
+		v[i] = values[i] - t.Offset[i]
+	}
+	if t.Rotation != 0 {
+		s, c := math.Sincos(t.Rotation)
+		v[0], v[1] = c*v[0]-s*v[1], s*v[0]+c*v[1]
+	}
+	for i := range v {
+		v[i] *= t.Scale[i]
+	}
+	return v
+}
+
+// Maps frame values back into the world.
+func (t *Transform) inverse(values []float64) []float64 {
+	v := make([]float64, len(values))
+	for i := range v {
+		v[i] = values[i] / t.Scale[i]
+	}
+	if t.Rotation != 0 {
+		s, c := math.Sincos(t.Rotation)
+		v[0], v[1] = c*v[0]+s*v[1], -s*v[0]+c*v[1]
+	}
+	for i := range v {
+		v[i] += t.Offset[i]
+	}
+	return v
+}
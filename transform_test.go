@@ -0,0 +1,89 @@
+package morton
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTransformRoundTrip(t *testing.T) {
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 16}, Axis{Min: 0, Max: 1, Bits: 16})
+	// Projected coordinates with a false easting of 500 km, over a 100 by 50 km frame
+	offset, scale := []float64{500000, 4000000}, []float64{1e-5, 2e-5}
+	half := []float64{0.5 / 65536 / scale[0], 0.5 / 65536 / scale[1]}
+	r := rand.New(rand.NewSource(146))
+	for _, rotation := range []float64{0, math.Pi / 6} {
+		m := New(2, 1<<16, WithTransform(offset, scale), WithRotation(rotation))
+		for n := 0; n < 1000; n++ {
+			// Points whose rotated frame coordinates lie within [0, 1)
+			u := []float64{r.Float64(), r.Float64()}
+			s, c := math.Sincos(rotation)
+			x, y := u[0]/scale[0], u[1]/scale[1]
+			world := []float64{c*x + s*y + offset[0], -s*x + c*y + offset[1]}
+
+			code, err := m.EncodeFloat(world, q)
+			if err != nil {
+				t.Fatalf("rotation %v: EncodeFloat(%v): %v", rotation, world, err)
+			}
+			if want, _ := m.Encode([]uint32{uint32(u[0] * 65536), uint32(u[1] * 65536)}); rotation == 0 && code != want {
+				t.Fatalf("EncodeFloat(%v) = %v, want %v", world, code, want)
+			}
+			got, err := m.DecodeFloat(code, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Half a cell in the frame, rotated back, is at most its diagonal in each component
+			limit := math.Hypot(half[0], half[1])
+			if rotation == 0 {
+				limit = math.Max(half[0], half[1])
+			}
+			for i := range world {
+				if diff := math.Abs(got[i] - world[i]); diff > limit*(1+1e-9) {
+					t.Fatalf("rotation %v: %v decodes to %v, %v from it, beyond %v", rotation, world, got, diff, limit)
+				}
+			}
+		}
+	}
+}
+
+func TestTransformDefaults(t *testing.T) {
+	m := New(3, 8, WithTransform(nil, []float64{2, 2, 2}))
+	tr := m.Transform()
+	if tr == nil || len(tr.Offset) != 3 || tr.Offset[0] != 0 || tr.Scale[2] != 2 || tr.Rotation != 0 {
+		t.Fatalf("Transform() = %+v", tr)
+	}
+	// The copy is the caller's own
+	tr.Scale[0] = 5
+	if m.Transform().Scale[0] != 2 {
+		t.Error("changing Transform's result changed the configuration")
+	}
+	if New(2, 8).Transform() != nil {
+		t.Error("Transform() without one is not nil")
+	}
+	if m := New(2, 8, WithRotation(1)); m.Transform().Scale[1] != 1 {
+		t.Errorf("WithRotation alone has scale %v, want 1", m.Transform().Scale)
+	}
+}
+
+func TestTransformRejects(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"short offset":      WithTransform([]float64{1}, nil),
+		"long scale":        WithTransform(nil, []float64{1, 1, 1}),
+		"zero scale":        WithTransform(nil, []float64{1, 0}),
+		"infinite scale":    WithTransform(nil, []float64{math.Inf(1), 1}),
+		"NaN offset":        WithTransform([]float64{math.NaN(), 0}, nil),
+		"infinite rotation": WithRotation(math.Inf(-1)),
+	} {
+		if err := new(Morton).Create(2, 16, opt); err == nil {
+			t.Errorf("Create with a %v succeeded", name)
+		}
+	}
+	if err := new(Morton).Create(3, 16, WithRotation(0.5)); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Create with a 3D rotation returned %v, want ErrDimensionMismatch", err)
+	}
+	q, _ := NewQuantizer(Axis{Min: 0, Max: 1, Bits: 4}, Axis{Min: 0, Max: 1, Bits: 4})
+	if _, err := New(2, 16, WithTransform(nil, nil)).EncodeFloat([]float64{0.5}, q); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("EncodeFloat of one component through a transform returned %v, want ErrDimensionMismatch", err)
+	}
+}
@@ -0,0 +1,84 @@
+package morton
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrVarintTruncated = errors.New("Uvarint code is truncated")
+	ErrVarintOverflow  = errors.New("Uvarint code overflows 64 bits")
+	ErrVarintOverlong  = errors.New("Uvarint code is not minimally encoded")
+)
+
+// Appends code as a uvarint, at most binary.MaxVarintLen64 (10) bytes.
+func AppendUvarintCode(dst []byte, code uint64) []byte {
+	return binary.AppendUvarint(dst, code)
+}
+
+// Parses one uvarint code from the front of b, returning it and the number of bytes consumed.  Unlike binary.Uvarint, encodings with superfluous trailing zero groups are rejected, so every code has exactly one valid encoding.
+func ConsumeUvarintCode(b []byte) (code uint64, n int, err error) {
+	code, n = binary.Uvarint(b)
+	switch {
+	case n == 0:
+		return 0, 0, ErrVarintTruncated
+	case n < 0:
+		return 0, 0, ErrVarintOverflow
+	case n > 1 && b[n-1] == 0:
+		return 0, 0, ErrVarintOverlong
+	}
+	return
+}
+
+// Appends each code as a uvarint.
+func AppendUvarintCodes(dst []byte, codes []uint64) []byte {
+	for _, c := range codes {
+		dst = binary.AppendUvarint(dst, c)
+	}
+	return dst
+}
+
+// Parses uvarint codes until b is exhausted, appending them to dst.
+func ConsumeUvarintCodes(b []byte, dst []uint64) ([]uint64, error) {
+	for offset := 0; offset < len(b); {
+		c, n, err := ConsumeUvarintCode(b[offset:])
+		if err != nil {
+			return dst, fmt.Errorf("%w at byte %v", err, offset)
+		}
+		dst = append(dst, c)
+		offset += n
+	}
+	return dst, nil
+}
+
+// Appends ascending codes as uvarint deltas from their predecessor, the first from 0, which is compact for clustered codes.
+func AppendDeltaCodes(dst []byte, codes []uint64) ([]byte, error) {
+	var prev uint64
+	for i, c := range codes {
+		if c < prev {
+			return dst, errors.New(fmt.Sprint("Delta coded codes must be ascending, code ", i, " is not"))
+		}
+		dst = binary.AppendUvarint(dst, c-prev)
+		prev = c
+	}
+	return dst, nil
+}
+
+// Parses uvarint deltas written by AppendDeltaCodes until b is exhausted, appending the codes to dst.
+func ConsumeDeltaCodes(b []byte, dst []uint64) ([]uint64, error) {
+	var prev uint64
+	for offset := 0; offset < len(b); {
+		delta, n, err := ConsumeUvarintCode(b[offset:])
+		if err != nil {
+			return dst, fmt.Errorf("%w at byte %v", err, offset)
+		}
+		if prev+delta < prev {
+			return dst, fmt.Errorf("%w at byte %v", ErrVarintOverflow, offset)
+		}
+		prev += delta
+		dst = append(dst, prev)
+		offset += n
+	}
+	return dst, nil
+}
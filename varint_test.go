@@ -0,0 +1,112 @@
+package morton
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestUvarintCodeRoundTrip(t *testing.T) {
+	for _, code := range []uint64{0, 1, 127, 128, 300, 1<<63 - 1, 1 << 63, math.MaxUint64} {
+		b := AppendUvarintCode([]byte{0xaa}, code)[1:]
+		got, n, err := ConsumeUvarintCode(append(b, 0x01))
+		if err != nil || got != code || n != len(b) {
+			t.Errorf("ConsumeUvarintCode(AppendUvarintCode(%v)) = %v, %v, %v; want %v bytes", code, got, n, err, len(b))
+		}
+	}
+	if n := len(AppendUvarintCode(nil, math.MaxUint64)); n != binary.MaxVarintLen64 {
+		t.Errorf("The maximum code takes %v bytes, want %v", n, binary.MaxVarintLen64)
+	}
+}
+
+func TestUvarintCodeRejects(t *testing.T) {
+	for _, tc := range []struct {
+		b   []byte
+		err error
+	}{
+		{nil, ErrVarintTruncated},
+		{[]byte{0x80}, ErrVarintTruncated},
+		{[]byte{0xff, 0xff, 0xff}, ErrVarintTruncated},
+		{[]byte{0x80, 0x00}, ErrVarintOverlong},
+		{[]byte{0x81, 0x80, 0x00}, ErrVarintOverlong},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02}, ErrVarintOverflow},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}, ErrVarintOverflow},
+	} {
+		if _, _, err := ConsumeUvarintCode(tc.b); !errors.Is(err, tc.err) {
+			t.Errorf("ConsumeUvarintCode(% x) returned %v, want %v", tc.b, err, tc.err)
+		}
+	}
+}
+
+func TestUvarintCodes(t *testing.T) {
+	r := rand.New(rand.NewSource(121))
+	codes := make([]uint64, 200)
+	for i := range codes {
+		codes[i] = r.Uint64() >> uint(r.Intn(64))
+	}
+	got, err := ConsumeUvarintCodes(AppendUvarintCodes(nil, codes), nil)
+	if err != nil || len(got) != len(codes) {
+		t.Fatalf("ConsumeUvarintCodes read %v codes, %v", len(got), err)
+	}
+	for i := range codes {
+		if got[i] != codes[i] {
+			t.Fatalf("ConsumeUvarintCodes read %v at %v, want %v", got[i], i, codes[i])
+		}
+	}
+
+	b := AppendUvarintCodes(nil, []uint64{5, 1000})
+	if got, err := ConsumeUvarintCodes(b[:len(b)-1], nil); !errors.Is(err, ErrVarintTruncated) || len(got) != 1 {
+		t.Errorf("ConsumeUvarintCodes of a truncated batch = %v, %v", got, err)
+	}
+}
+
+func TestDeltaCodes(t *testing.T) {
+	codes := []uint64{0, 0, 3, 100, 101, 1 << 40, math.MaxUint64}
+	b, err := AppendDeltaCodes(nil, codes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ConsumeDeltaCodes(b, nil)
+	if err != nil || len(got) != len(codes) {
+		t.Fatalf("ConsumeDeltaCodes read %v, %v", got, err)
+	}
+	for i := range codes {
+		if got[i] != codes[i] {
+			t.Fatalf("ConsumeDeltaCodes read %v at %v, want %v", got[i], i, codes[i])
+		}
+	}
+
+	if _, err := AppendDeltaCodes(nil, []uint64{5, 4}); err == nil {
+		t.Error("AppendDeltaCodes of descending codes succeeded")
+	}
+	// Deltas summing past 64 bits
+	overflow := AppendUvarintCodes(nil, []uint64{math.MaxUint64, 1})
+	if _, err := ConsumeDeltaCodes(overflow, nil); !errors.Is(err, ErrVarintOverflow) {
+		t.Errorf("ConsumeDeltaCodes of overflowing deltas returned %v", err)
+	}
+}
+
+// Whatever the input, a parsed code re-encodes to exactly the bytes consumed.
+func FuzzConsumeUvarintCode(f *testing.F) {
+	for _, seed := range [][]byte{{}, {0}, {0x80, 0x00}, {0xff, 0x01}, AppendUvarintCode(nil, math.MaxUint64), bytes.Repeat([]byte{0xff}, 11)} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		code, n, err := ConsumeUvarintCode(b)
+		if err != nil {
+			if n != 0 || code != 0 {
+				t.Fatalf("ConsumeUvarintCode(% x) failed with %v, yet returned %v, %v", b, err, code, n)
+			}
+			return
+		}
+		if n < 1 || n > binary.MaxVarintLen64 || n > len(b) {
+			t.Fatalf("ConsumeUvarintCode(% x) consumed %v bytes", b, n)
+		}
+		if enc := AppendUvarintCode(nil, code); !bytes.Equal(enc, b[:n]) {
+			t.Fatalf("ConsumeUvarintCode(% x) = %v, which encodes as % x", b, code, enc)
+		}
+	})
+}